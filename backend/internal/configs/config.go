@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +15,22 @@ type Config struct {
 	Version     string
 	JWTSecret   string
 	JWTIssuer   string
+
+	// Payments; PaymentMode is "live" for the real gateways or "fake" for local development,
+	// where every payment succeeds
+	PaymentMode          string
+	PublicURL            string
+	FrontendURL          string
+	TelebirrBaseURL      string
+	TelebirrAppID        string
+	TelebirrAppKey       string
+	TelebirrMerchantCode string
+	CBEBirrBaseURL       string
+	CBEBirrMerchantID    string
+	CBEBirrAPIKey        string
+
+	// ReviewBlocklist holds terms that send a review to the moderation queue instead of publishing it
+	ReviewBlocklist []string
 }
 
 func LoadConfig(envFile string) (*Config, error) {
@@ -55,6 +72,49 @@ func LoadConfig(envFile string) (*Config, error) {
 		cfg.JWTIssuer = os.Getenv("JWT_ISSUER")
 	}
 
+	// No default: the fake gateways accept any payment, so they must be asked for explicitly
+	if os.Getenv("PAYMENT_MODE") == "" {
+		return nil, fmt.Errorf("PAYMENT_MODE is not set")
+	} else {
+		cfg.PaymentMode = os.Getenv("PAYMENT_MODE")
+	}
+	if cfg.PaymentMode != "fake" && cfg.PaymentMode != "live" {
+		return nil, fmt.Errorf("PAYMENT_MODE must be fake or live, got %q", cfg.PaymentMode)
+	}
+
+	if os.Getenv("API_PUBLIC_URL") == "" {
+		cfg.PublicURL = fmt.Sprintf("http://localhost:%d", cfg.Port)
+	} else {
+		cfg.PublicURL = os.Getenv("API_PUBLIC_URL")
+	}
+	if os.Getenv("FRONTEND_URL") == "" {
+		cfg.FrontendURL = "http://localhost:3000"
+	} else {
+		cfg.FrontendURL = os.Getenv("FRONTEND_URL")
+	}
+
+	cfg.TelebirrBaseURL = os.Getenv("TELEBIRR_BASE_URL")
+	cfg.TelebirrAppID = os.Getenv("TELEBIRR_APP_ID")
+	cfg.TelebirrAppKey = os.Getenv("TELEBIRR_APP_KEY")
+	cfg.TelebirrMerchantCode = os.Getenv("TELEBIRR_MERCHANT_CODE")
+	cfg.CBEBirrBaseURL = os.Getenv("CBE_BIRR_BASE_URL")
+	cfg.CBEBirrMerchantID = os.Getenv("CBE_BIRR_MERCHANT_ID")
+	cfg.CBEBirrAPIKey = os.Getenv("CBE_BIRR_API_KEY")
+	if cfg.PaymentMode == "live" {
+		if cfg.TelebirrBaseURL == "" || cfg.TelebirrAppID == "" || cfg.TelebirrAppKey == "" || cfg.TelebirrMerchantCode == "" {
+			return nil, fmt.Errorf("TELEBIRR_BASE_URL, TELEBIRR_APP_ID, TELEBIRR_APP_KEY and TELEBIRR_MERCHANT_CODE must be set in live payment mode")
+		}
+		if cfg.CBEBirrBaseURL == "" || cfg.CBEBirrMerchantID == "" || cfg.CBEBirrAPIKey == "" {
+			return nil, fmt.Errorf("CBE_BIRR_BASE_URL, CBE_BIRR_MERCHANT_ID and CBE_BIRR_API_KEY must be set in live payment mode")
+		}
+	}
+
+	for _, term := range strings.Split(os.Getenv("REVIEW_BLOCKLIST"), ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			cfg.ReviewBlocklist = append(cfg.ReviewBlocklist, term)
+		}
+	}
+
 	return cfg, nil
 
 }
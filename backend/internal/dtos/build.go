@@ -10,11 +10,29 @@ type CreateBuildRequestDTO struct {
 	Items []BuildItemDTO `json:"items" binding:"required,min=1"`
 }
 
+type ValidateBuildRequestDTO struct {
+	Items []BuildItemDTO `json:"items" binding:"required,min=1,max=20,dive"`
+}
+
 type UpdateBuildRequestDTO struct {
 	Name  string         `json:"name" binding:"omitempty"`
 	Items []BuildItemDTO `json:"items" binding:"omitempty,min=1"`
 }
 
+type SetBuildVisibilityDTO struct {
+	Visibility string `json:"visibility" binding:"required,oneof=private unlisted public"`
+}
+
+type CloneBuildDTO struct {
+	Name string `json:"name" binding:"omitempty,max=100"`
+}
+
+type BuildGalleryDTO struct {
+	Sort   string `form:"sort" binding:"omitempty,oneof=recent popular price_asc price_desc"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
 type BuildItemResponseDTO struct {
 	ProductID    int     `json:"product_id"`
 	Quantity     int     `json:"quantity"`
@@ -31,6 +49,8 @@ type BuildResponseDTO struct {
 	Name       string                 `json:"name"`
 	CreatedAt  string                 `json:"created_at"`
 	TotalPrice float64                `json:"total_price"`
+	Visibility string                 `json:"visibility"`
+	Slug       *string                `json:"slug"`
 	Items      []BuildItemResponseDTO `json:"items"`
 }
 
@@ -40,11 +60,75 @@ type CompatibleProductsRequestDTO struct {
 }
 
 type CompatibleProductDTO struct {
-	ProductID    int               `json:"product_id"`
-	ProductName  string            `json:"product_name"`
-	Price        float64           `json:"price"`
-	Description  string            `json:"description"`
-	BrandName    string            `json:"brand_name"`
-	CategoryName string            `json:"category_name"`
-	Specs        map[string]string `json:"specs"`
+	ProductID     int               `json:"product_id"`
+	ProductName   string            `json:"product_name"`
+	Price         float64           `json:"price"`
+	Description   string            `json:"description"`
+	BrandName     string            `json:"brand_name"`
+	CategoryName  string            `json:"category_name"`
+	StockQuantity int               `json:"stock_quantity"`
+	Specs         map[string]string `json:"specs"`
+}
+
+type CompleteBuildRequestDTO struct {
+	Items       []BuildItemDTO `json:"items" binding:"omitempty,max=20,dive"`
+	CategoryIDs []int          `json:"category_ids" binding:"required,min=1,max=8,dive,min=1"`
+	Budget      float64        `json:"budget" binding:"required,gt=0"`
+	Preference  string         `json:"preference" binding:"omitempty,oneof=cheapest balanced performance"`
+	Limit       int            `json:"limit" binding:"omitempty,min=1,max=10"`
+}
+
+type AddBuildItemDTO struct {
+	ProductID int `json:"product_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"omitempty,min=1"`
+}
+
+type SetBuildItemQuantityDTO struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+type BuildPurchaseOptionsDTO struct {
+	Substitutes bool `form:"substitutes"`
+}
+
+type BuildSubstituteDTO struct {
+	ProductID     int     `json:"product_id"`
+	ProductName   string  `json:"product_name"`
+	Price         float64 `json:"price"`
+	StockQuantity int     `json:"stock_quantity"`
+}
+
+// BuildPurchaseItemDTO compares a build item with what it costs and how much of it is in
+// stock now; Substitutes are only listed when asked for and the item is short on stock
+type BuildPurchaseItemDTO struct {
+	ProductID     int                  `json:"product_id"`
+	ProductName   string               `json:"product_name"`
+	Quantity      int                  `json:"quantity"`
+	SavedPrice    float64              `json:"saved_price"`
+	CurrentPrice  float64              `json:"current_price"`
+	PriceChange   float64              `json:"price_change"`
+	StockQuantity int                  `json:"stock_quantity"`
+	InStock       bool                 `json:"in_stock"`
+	StockWarning  string               `json:"stock_warning,omitempty"`
+	Substitutes   []BuildSubstituteDTO `json:"substitutes,omitempty"`
+}
+
+type BuildPurchaseCheckDTO struct {
+	BuildID        string                 `json:"build_id"`
+	Items          []BuildPurchaseItemDTO `json:"items"`
+	SavedTotal     float64                `json:"saved_total"`
+	CurrentTotal   float64                `json:"current_total"`
+	Repriced       bool                   `json:"repriced"`
+	HasStockIssues bool                   `json:"has_stock_issues"`
+}
+
+// BuildToCartResponseDTO is the cart after adding a build; items short on stock are left
+// out and reported in the check
+type BuildToCartResponseDTO struct {
+	Check *BuildPurchaseCheckDTO `json:"check"`
+	Cart  *CartResponseDTO       `json:"cart"`
+}
+
+type SetBuildPriceAlertDTO struct {
+	Threshold float64 `json:"threshold" binding:"required,gt=0"`
 }
@@ -0,0 +1,45 @@
+package dtos
+
+type CartItemDTO struct {
+	ProductID int `json:"product_id" binding:"required"`
+	Quantity  int `json:"quantity" binding:"required,min=1"`
+}
+
+type UpdateCartItemDTO struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+type MergeCartDTO struct {
+	Items []CartItemDTO `json:"items" binding:"required,dive"`
+}
+
+type CartItemResponseDTO struct {
+	ProductID          int     `json:"product_id"`
+	ProductName        string  `json:"product_name"`
+	OriginalPrice      float64 `json:"original_price"`
+	DiscountPercentage float64 `json:"discount_percentage"`
+	Price              float64 `json:"price"`
+	Quantity           int     `json:"quantity"`
+	LineTotal          float64 `json:"line_total"`
+	StockQuantity      int     `json:"stock_quantity"`
+	InStock            bool    `json:"in_stock"`
+	StockWarning       string  `json:"stock_warning,omitempty"`
+}
+
+type CartResponseDTO struct {
+	Items            []CartItemResponseDTO `json:"items"`
+	ItemCount        int                   `json:"item_count"`
+	OriginalSubtotal float64               `json:"original_subtotal"`
+	DiscountTotal    float64               `json:"discount_total"`
+	Subtotal         float64               `json:"subtotal"`
+	Coupon           *CartCouponDTO        `json:"coupon,omitempty"`
+	CouponError      string                `json:"coupon_error,omitempty"`
+	Total            float64               `json:"total"`
+	HasStockIssues   bool                  `json:"has_stock_issues"`
+}
+
+type CartCouponDTO struct {
+	Code             string  `json:"code"`
+	EligibleSubtotal float64 `json:"eligible_subtotal"`
+	DiscountAmount   float64 `json:"discount_amount"`
+}
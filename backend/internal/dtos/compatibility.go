@@ -0,0 +1,36 @@
+package dtos
+
+// CreateCompatibilityRuleDTO needs a subject category or product and compares the target
+// spec against exactly one of a constant value or a spec of the subject. Aggregates and
+// margins only work with the gte and lte operators; a count needs no target spec.
+type CreateCompatibilityRuleDTO struct {
+	Name               string  `json:"name" binding:"required,max=150"`
+	Description        string  `json:"description" binding:"omitempty,max=1000"`
+	Severity           string  `json:"severity" binding:"omitempty,oneof=error warning"`
+	SubjectCategoryID  *int    `json:"subject_category_id" binding:"omitempty,min=1"`
+	SubjectProductID   *int    `json:"subject_product_id" binding:"omitempty,min=1"`
+	SubjectFilterSpec  string  `json:"subject_filter_spec" binding:"omitempty,max=100"`
+	SubjectFilterValue string  `json:"subject_filter_value" binding:"omitempty,max=255"`
+	TargetCategoryID   *int    `json:"target_category_id" binding:"omitempty,min=1"`
+	TargetFilterSpec   string  `json:"target_filter_spec" binding:"omitempty,max=100"`
+	TargetFilterValue  string  `json:"target_filter_value" binding:"omitempty,max=255"`
+	TargetSpec         string  `json:"target_spec" binding:"omitempty,max=100"`
+	TargetAggregate    string  `json:"target_aggregate" binding:"omitempty,oneof=sum count"`
+	Operator           string  `json:"operator" binding:"omitempty,oneof=equals in_set gte lte"`
+	Quantifier         string  `json:"quantifier" binding:"omitempty,oneof=all any"`
+	Margin             float64 `json:"margin"`
+	SubjectSpec        string  `json:"subject_spec" binding:"omitempty,max=100"`
+	Value              string  `json:"value" binding:"omitempty,max=255"`
+}
+
+// CompatibilityRuleFilterDTO lists the rules whose subject or target is the category, or
+// whose subject is the product
+type CompatibilityRuleFilterDTO struct {
+	CategoryID *int `form:"category_id" binding:"omitempty,min=1"`
+	ProductID  *int `form:"product_id" binding:"omitempty,min=1"`
+}
+
+// TestCompatibilityRuleDTO is the set of products a rule is tried against, as in a build
+type TestCompatibilityRuleDTO struct {
+	ProductIDs []int `json:"product_ids" binding:"required,min=1,dive,min=1"`
+}
@@ -0,0 +1,39 @@
+package dtos
+
+import "time"
+
+type CreateCouponDTO struct {
+	Code              string     `json:"code" binding:"required,min=3,max=50"`
+	Description       *string    `json:"description" binding:"omitempty,max=500"`
+	CouponType        string     `json:"coupon_type" binding:"required,oneof=percentage fixed"`
+	Value             float64    `json:"value" binding:"required,gt=0"`
+	MinOrderAmount    float64    `json:"min_order_amount" binding:"omitempty,gte=0"`
+	MaxDiscountAmount *float64   `json:"max_discount_amount" binding:"omitempty,gt=0"`
+	UsageLimit        *int       `json:"usage_limit" binding:"omitempty,min=1"`
+	PerUserLimit      *int       `json:"per_user_limit" binding:"omitempty,min=1"`
+	StartsAt          *time.Time `json:"starts_at" binding:"omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at" binding:"omitempty"`
+	CategoryIDs       []int      `json:"category_ids" binding:"omitempty,dive,min=1"`
+	BrandIDs          []int      `json:"brand_ids" binding:"omitempty,dive,min=1"`
+}
+
+// UpdateCouponDTO changes the given fields only. category_ids and brand_ids replace the
+// existing restrictions when present; an empty list removes them.
+type UpdateCouponDTO struct {
+	Description       *string    `json:"description" binding:"omitempty,max=500"`
+	Value             *float64   `json:"value" binding:"omitempty,gt=0"`
+	MinOrderAmount    *float64   `json:"min_order_amount" binding:"omitempty,gte=0"`
+	MaxDiscountAmount *float64   `json:"max_discount_amount" binding:"omitempty,gt=0"`
+	UsageLimit        *int       `json:"usage_limit" binding:"omitempty,min=1"`
+	PerUserLimit      *int       `json:"per_user_limit" binding:"omitempty,min=1"`
+	StartsAt          *time.Time `json:"starts_at" binding:"omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at" binding:"omitempty"`
+	IsActive          *bool      `json:"is_active" binding:"omitempty"`
+	CategoryIDs       *[]int     `json:"category_ids" binding:"omitempty,dive,min=1"`
+	BrandIDs          *[]int     `json:"brand_ids" binding:"omitempty,dive,min=1"`
+}
+
+type CouponFilterDTO struct {
+	Active *bool  `form:"active" binding:"omitempty"`
+	Code   string `form:"code" binding:"omitempty,max=50"`
+}
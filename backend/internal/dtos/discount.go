@@ -0,0 +1,27 @@
+package dtos
+
+import "time"
+
+// CreateDiscountDTO targets exactly one of a product or a category. A category discount
+// also applies to every product in its subcategories.
+type CreateDiscountDTO struct {
+	ProductID          *int      `json:"product_id" binding:"omitempty,min=1"`
+	CategoryID         *int      `json:"category_id" binding:"omitempty,min=1"`
+	DiscountPercentage float64   `json:"discount_percentage" binding:"required,gt=0,lte=100"`
+	StartDate          time.Time `json:"start_date" binding:"required"`
+	EndDate            time.Time `json:"end_date" binding:"required"`
+}
+
+type UpdateDiscountDTO struct {
+	ProductID          *int       `json:"product_id" binding:"omitempty,min=1"`
+	CategoryID         *int       `json:"category_id" binding:"omitempty,min=1"`
+	DiscountPercentage *float64   `json:"discount_percentage" binding:"omitempty,gt=0,lte=100"`
+	StartDate          *time.Time `json:"start_date" binding:"omitempty"`
+	EndDate            *time.Time `json:"end_date" binding:"omitempty"`
+}
+
+type DiscountFilterDTO struct {
+	ProductID  *int  `form:"product_id" binding:"omitempty,min=1"`
+	CategoryID *int  `form:"category_id" binding:"omitempty,min=1"`
+	Active     *bool `form:"active" binding:"omitempty"`
+}
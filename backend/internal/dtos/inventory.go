@@ -0,0 +1,40 @@
+package dtos
+
+type CreateSupplierDTO struct {
+	Name         string  `json:"name" binding:"required,max=100"`
+	ContactEmail *string `json:"contact_email" binding:"omitempty,email,max=255"`
+	Phone        *string `json:"phone" binding:"omitempty,max=20"`
+}
+
+type UpdateSupplierDTO struct {
+	Name         *string `json:"name" binding:"omitempty,min=1,max=100"`
+	ContactEmail *string `json:"contact_email" binding:"omitempty,email,max=255"`
+	Phone        *string `json:"phone" binding:"omitempty,max=20"`
+}
+
+type ReceiveStockDTO struct {
+	SupplierID int    `json:"supplier_id" binding:"required"`
+	Quantity   int    `json:"quantity" binding:"required,min=1"`
+	Note       string `json:"note" binding:"max=500"`
+}
+
+// AdjustStockDTO corrects stock outside of receipts and orders. Negative changes take stock
+// out of the oldest lots first, optionally restricted to one supplier's lots.
+type AdjustStockDTO struct {
+	QuantityChange int    `json:"quantity_change" binding:"required,ne=0"`
+	Reason         string `json:"reason" binding:"required,oneof=damaged lost found correction customer_return supplier_return"`
+	SupplierID     *int   `json:"supplier_id" binding:"omitempty"`
+	Note           string `json:"note" binding:"max=500"`
+}
+
+type StockLedgerFilterDTO struct {
+	Limit  int `form:"limit" binding:"omitempty,min=1,max=200"`
+	Offset int `form:"offset" binding:"omitempty,min=0"`
+}
+
+type LowStockFilterDTO struct {
+	Threshold  *int `form:"threshold" binding:"omitempty,min=0"`
+	CategoryID *int `form:"category_id" binding:"omitempty"`
+	Limit      int  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset     int  `form:"offset" binding:"omitempty,min=0"`
+}
@@ -0,0 +1,7 @@
+package dtos
+
+type NotificationFilterDTO struct {
+	Unread bool `form:"unread"`
+	Limit  int  `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int  `form:"offset" binding:"omitempty,min=0"`
+}
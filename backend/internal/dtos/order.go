@@ -4,12 +4,35 @@ type CreateOrderDTO struct {
 	UserID        string  `json:"user_id" binding:"required,uuid"`
 	AddressID     int     `json:"address_id" binding:"required"`
 	TotalAmount   float64 `json:"total_amount" binding:"required,gte=0"`
-	PaymentMethod string  `json:"payment_method" binding:"required,oneof=cash credit_card debit_card"`
+	PaymentMethod string  `json:"payment_method" binding:"required,oneof=telebirr cbe_banking"`
 }
 
 type UpdateOrderDTO struct {
-	Status        *string  `json:"status" binding:"omitempty,oneof=pending shipped delivered cancelled"`
 	TotalAmount   *float64 `json:"total_amount" binding:"omitempty,gte=0"`
-	PaymentMethod *string  `json:"payment_method" binding:"omitempty,oneof=cash credit_card debit_card"`
+	PaymentMethod *string  `json:"payment_method" binding:"omitempty,oneof=telebirr cbe_banking"`
 	PaymentDate   *string  `json:"payment_date" binding:"omitempty,datetime=2006-01-02T15:04:05Z07:00"`
 }
+
+type CheckoutRequestDTO struct {
+	AddressID     int    `json:"address_id" binding:"required"`
+	PaymentMethod string `json:"payment_method" binding:"required,oneof=telebirr cbe_banking"`
+	// BuildID checks out a saved custom build instead of the cart when set
+	BuildID    string `json:"build_id" binding:"omitempty,uuid"`
+	CouponCode string `json:"coupon_code" binding:"omitempty,max=50"`
+	// AcceptPriceChanges lets a build be ordered although prices changed since it was saved
+	AcceptPriceChanges bool `json:"accept_price_changes"`
+}
+
+type OrderFilterDTO struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending paid shipped delivered cancelled"`
+	UserID string `form:"user_id" binding:"omitempty,uuid"`
+	From   string `form:"from" binding:"omitempty,datetime=2006-01-02"`
+	To     string `form:"to" binding:"omitempty,datetime=2006-01-02"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+type UpdateOrderStatusDTO struct {
+	Status string `json:"status" binding:"required,oneof=pending paid shipped delivered cancelled"`
+	Reason string `json:"reason" binding:"omitempty,max=500"`
+}
@@ -1,21 +1,19 @@
 package dtos
 
 var ModelToDatabaseMap = map[string]string{
-	"CategoryID":    "categroy_id",
-	"BrandID":       "brand_id",
-	"Name":          "name",
-	"Description":   "description",
-	"Price":         "price",
-	"StockQuantity": "stock_quantity",
+	"CategoryID":  "categroy_id",
+	"BrandID":     "brand_id",
+	"Name":        "name",
+	"Description": "description",
+	"Price":       "price",
 }
 
 type ProductUpdateDTO struct {
-	CategoryID    *int     `json:"categroy_id" binding:"omitempty"`
-	BrandID       *int     `json:"brand_id" binding:"omitempty"`
-	Name          *string  `json:"name" binding:"omitempty"`
-	Description   *string  `json:"description" binding:"omitempty"`
-	Price         *float64 `json:"price" binding:"omitempty"`
-	StockQuantity *int     `json:"stock_quantity" binding:"omitempty"`
+	CategoryID  *int     `json:"categroy_id" binding:"omitempty"`
+	BrandID     *int     `json:"brand_id" binding:"omitempty"`
+	Name        *string  `json:"name" binding:"omitempty"`
+	Description *string  `json:"description" binding:"omitempty"`
+	Price       *float64 `json:"price" binding:"omitempty"`
 }
 
 type CreateProductDTO struct {
@@ -24,11 +22,10 @@ type CreateProductDTO struct {
 	Name          string  `json:"name" binding:"required"`
 	Description   string  `json:"description" binding:"required"`
 	Price         float64 `json:"price" binding:"required"`
-	StockQuantity int     `json:"stock_quantity" binding:"required"`
+	StockQuantity int     `json:"stock_quantity" binding:"omitempty,min=0"` // opening stock, booked as an inventory lot
 }
 
 type CreateReviewDTO struct {
-	UserID    string `json:"user_id" binding:"required,uuid"`
 	ProductID int    `json:"product_id" binding:"required"`
 	Rating    int    `json:"rating" binding:"required,min=1,max=5"`
 	Comment   string `json:"comment" binding:"required"`
@@ -37,3 +34,62 @@ type UpdateReviewDTO struct {
 	Rating  *int    `json:"rating" binding:"omitempty,min=1,max=5"`
 	Comment *string `json:"comment" binding:"omitempty"`
 }
+
+type ReviewFilterDTO struct {
+	Sort         string `form:"sort" binding:"omitempty,oneof=newest oldest highest lowest helpful"`
+	VerifiedOnly bool   `form:"verified_only" binding:"omitempty"`
+	Limit        int    `form:"limit" binding:"omitempty,min=1,max=50"`
+	Offset       int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+type ReportReviewDTO struct {
+	Reason string `json:"reason" binding:"required,oneof=spam offensive off_topic fake other"`
+	Note   string `json:"note" binding:"omitempty,max=500"`
+}
+
+type ModerateReviewDTO struct {
+	Action string `json:"action" binding:"required,oneof=approve hide delete"`
+	Reason string `json:"reason" binding:"omitempty,max=500"`
+}
+
+// ModerationQueueFilterDTO selects the queue; without a status it holds every held review
+// and every published review with unresolved reports
+type ModerationQueueFilterDTO struct {
+	Status string `form:"status" binding:"omitempty,oneof=held hidden reported"`
+	Limit  int    `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset int    `form:"offset" binding:"omitempty,min=0"`
+}
+
+// ProductSearchDTO holds the query of GET /products/search. Specs are given as repeated
+// name:value pairs; values of the same name are alternatives, different names must all match.
+// Numeric specs can also be limited to a range with name:min..max, where either end may be
+// left out (e.g. tdp_watts:..125).
+type ProductSearchDTO struct {
+	Query      string   `form:"q" binding:"omitempty,max=200"`
+	CategoryID int      `form:"category_id" binding:"omitempty,min=1"`
+	BrandIDs   []int    `form:"brand_id" binding:"omitempty,dive,min=1"`
+	MinPrice   *float64 `form:"min_price" binding:"omitempty,gte=0"`
+	MaxPrice   *float64 `form:"max_price" binding:"omitempty,gte=0"`
+	InStock    bool     `form:"in_stock" binding:"omitempty"`
+	Specs      []string `form:"spec" binding:"omitempty,dive,contains=:"`
+	SpecRanges []string `form:"spec_range" binding:"omitempty,dive,contains=:"`
+	Sort       string   `form:"sort" binding:"omitempty,oneof=relevance newest price_asc price_desc name rating"`
+	Limit      int      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Offset     int      `form:"offset" binding:"omitempty,min=0"`
+
+	// SpecFilters is Specs parsed by the service: lower-cased spec name to accepted values
+	SpecFilters map[string][]string `form:"-"`
+	// SpecRangeFilters is SpecRanges parsed by the service, keyed by lower-cased spec name
+	SpecRangeFilters map[string]SpecRange `form:"-"`
+}
+
+// SpecRange bounds a numeric specification; a nil end is open
+type SpecRange struct {
+	Min *float64
+	Max *float64
+}
+
+type SearchSuggestDTO struct {
+	Query string `form:"q" binding:"required,max=100"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=20"`
+}
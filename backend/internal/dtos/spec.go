@@ -0,0 +1,29 @@
+package dtos
+
+// ReplaceProductSpecsDTO sets the complete specification list of a product
+type ReplaceProductSpecsDTO struct {
+	Specs map[string]string `json:"specs" binding:"required"`
+}
+
+// UpdateProductSpecsDTO adds or changes the given specifications; a null value removes one
+type UpdateProductSpecsDTO struct {
+	Specs map[string]*string `json:"specs" binding:"required,min=1"`
+}
+
+// SpecDefinitionItemDTO declares one specification of a category. Enums list their
+// allowed values; integers and decimals may name the unit their values are given in.
+type SpecDefinitionItemDTO struct {
+	SpecName      string   `json:"spec_name" binding:"required,max=100"`
+	DataType      string   `json:"data_type" binding:"omitempty,oneof=text enum integer decimal boolean"`
+	Unit          string   `json:"unit" binding:"omitempty,max=20"`
+	AllowedValues []string `json:"allowed_values" binding:"omitempty,dive,required,max=255"`
+	Required      bool     `json:"required"`
+	Filterable    bool     `json:"filterable"`
+	Description   string   `json:"description" binding:"omitempty,max=500"`
+}
+
+// SetCategorySpecDefinitionsDTO replaces the definitions a category declares itself;
+// inherited definitions are untouched. An empty list removes them.
+type SetCategorySpecDefinitionsDTO struct {
+	Definitions []SpecDefinitionItemDTO `json:"definitions" binding:"omitempty,dive"`
+}
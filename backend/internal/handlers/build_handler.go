@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/amha-mersha/sanqa-suq/internal/auth"
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
@@ -60,6 +61,14 @@ func (h *BuildHandler) GetUserBuilds(c *gin.Context) {
 	c.JSON(http.StatusOK, builds)
 }
 
+// viewerID is the ID of the signed-in user, or empty for an anonymous request
+func viewerID(c *gin.Context) string {
+	if claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims); ok {
+		return claims.UserID
+	}
+	return ""
+}
+
 func (h *BuildHandler) GetBuildByID(c *gin.Context) {
 	buildID := c.Param("id")
 	if buildID == "" {
@@ -67,7 +76,7 @@ func (h *BuildHandler) GetBuildByID(c *gin.Context) {
 		return
 	}
 
-	build, err := h.buildService.GetBuildByID(c.Request.Context(), buildID)
+	build, err := h.buildService.GetBuildByID(c.Request.Context(), buildID, viewerID(c))
 	if err != nil {
 		c.Error(err)
 		return
@@ -76,6 +85,279 @@ func (h *BuildHandler) GetBuildByID(c *gin.Context) {
 	c.JSON(http.StatusOK, build)
 }
 
+func (h *BuildHandler) GetSharedBuild(c *gin.Context) {
+	build, err := h.buildService.GetSharedBuild(c.Request.Context(), c.Param("slug"), viewerID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, build)
+}
+
+func (h *BuildHandler) GetPublicBuilds(c *gin.Context) {
+	var filter dtos.BuildGalleryDTO
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	gallery, err := h.buildService.GetPublicBuilds(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gallery)
+}
+
+func (h *BuildHandler) GetBuildAnalysis(c *gin.Context) {
+	buildID := c.Param("id")
+	if buildID == "" {
+		c.Error(errs.BadRequest("MISSING_BUILD_ID", nil))
+		return
+	}
+
+	analysis, err := h.buildService.AnalyzeBuild(c.Request.Context(), buildID, viewerID(c))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+func (h *BuildHandler) DeleteBuild(c *gin.Context) {
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	if err := h.buildService.DeleteBuild(c.Request.Context(), c.Param("id"), claims.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *BuildHandler) AddBuildItem(c *gin.Context) {
+	var req dtos.AddBuildItemDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	response, err := h.buildService.AddBuildItem(c.Request.Context(), c.Param("id"), claims.UserID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *BuildHandler) SetBuildItemQuantity(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	var req dtos.SetBuildItemQuantityDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	response, err := h.buildService.SetBuildItemQuantity(c.Request.Context(), c.Param("id"), claims.UserID, productID, req.Quantity)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *BuildHandler) RemoveBuildItem(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	response, err := h.buildService.RemoveBuildItem(c.Request.Context(), c.Param("id"), claims.UserID, productID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *BuildHandler) GetBuildRevisions(c *gin.Context) {
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	revisions, err := h.buildService.GetBuildRevisions(c.Request.Context(), c.Param("id"), claims.UserID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *BuildHandler) GetBuildRevision(c *gin.Context) {
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_REVISION", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	result, err := h.buildService.GetBuildRevision(c.Request.Context(), c.Param("id"), claims.UserID, revision)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *BuildHandler) RestoreBuildRevision(c *gin.Context) {
+	revision, err := strconv.Atoi(c.Param("revision"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_REVISION", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	response, err := h.buildService.RestoreBuildRevision(c.Request.Context(), c.Param("id"), claims.UserID, revision)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *BuildHandler) AddBuildToCart(c *gin.Context) {
+	var options dtos.BuildPurchaseOptionsDTO
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	response, err := h.buildService.AddBuildToCart(c.Request.Context(), c.Param("id"), claims.UserID, options.Substitutes)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *BuildHandler) GetBuildPurchaseCheck(c *gin.Context) {
+	var options dtos.BuildPurchaseOptionsDTO
+	if err := c.ShouldBindQuery(&options); err != nil {
+		c.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	check, err := h.buildService.CheckBuildPurchase(c.Request.Context(), c.Param("id"), viewerID(c), options.Substitutes)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, check)
+}
+
+func (h *BuildHandler) SetBuildVisibility(c *gin.Context) {
+	var req dtos.SetBuildVisibilityDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	response, err := h.buildService.SetBuildVisibility(c.Request.Context(), c.Param("id"), claims.UserID, req.Visibility)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *BuildHandler) CloneBuild(c *gin.Context) {
+	var req dtos.CloneBuildDTO
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+			return
+		}
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	response, err := h.buildService.CloneBuild(c.Request.Context(), c.Param("id"), claims.UserID, req.Name)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
 func (h *BuildHandler) UpdateBuild(c *gin.Context) {
 	buildID := c.Param("id")
 	if buildID == "" {
@@ -105,6 +387,38 @@ func (h *BuildHandler) UpdateBuild(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *BuildHandler) ValidateBuild(c *gin.Context) {
+	var req dtos.ValidateBuildRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	report, err := h.buildService.ValidateBuild(c.Request.Context(), req.Items)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func (h *BuildHandler) CompleteBuild(c *gin.Context) {
+	var req dtos.CompleteBuildRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	completions, err := h.buildService.CompleteBuild(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, completions)
+}
+
 func (h *BuildHandler) GetCompatibleProducts(c *gin.Context) {
 	var req dtos.CompatibleProductsRequestDTO
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -120,3 +434,66 @@ func (h *BuildHandler) GetCompatibleProducts(c *gin.Context) {
 
 	c.JSON(http.StatusOK, products)
 }
+
+func (h *BuildHandler) GetBuildPriceHistory(c *gin.Context) {
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	history, err := h.buildService.GetBuildPriceHistory(c.Request.Context(), c.Param("id"), claims.UserID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+func (h *BuildHandler) SetBuildPriceAlert(c *gin.Context) {
+	var req dtos.SetBuildPriceAlertDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	alert, err := h.buildService.SetBuildPriceAlert(c.Request.Context(), c.Param("id"), claims.UserID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, alert)
+}
+
+func (h *BuildHandler) DeleteBuildPriceAlert(c *gin.Context) {
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	if err := h.buildService.DeleteBuildPriceAlert(c.Request.Context(), c.Param("id"), claims.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *BuildHandler) RefreshBuildTotals(c *gin.Context) {
+	refreshed, err := h.buildService.RefreshBuildTotals(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refreshed": refreshed})
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/amha-mersha/sanqa-suq/internal/auth"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/amha-mersha/sanqa-suq/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CartHandler struct {
+	cartService *services.CartService
+}
+
+func NewCartHandler(cartService *services.CartService) *CartHandler {
+	return &CartHandler{
+		cartService: cartService,
+	}
+}
+
+func (h *CartHandler) GetCart(c *gin.Context) {
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	var (
+		cart *dtos.CartResponseDTO
+		err  error
+	)
+	if couponCode := c.Query("coupon_code"); couponCode != "" {
+		cart, err = h.cartService.GetCartWithCoupon(c.Request.Context(), claims.UserID, couponCode)
+	} else {
+		cart, err = h.cartService.GetCart(c.Request.Context(), claims.UserID)
+	}
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+func (h *CartHandler) AddItem(c *gin.Context) {
+	var req dtos.CartItemDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	cart, err := h.cartService.AddItem(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+func (h *CartHandler) UpdateItemQuantity(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	var req dtos.UpdateCartItemDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	cart, err := h.cartService.UpdateItemQuantity(c.Request.Context(), claims.UserID, productID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+func (h *CartHandler) RemoveItem(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("product_id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	cart, err := h.cartService.RemoveItem(c.Request.Context(), claims.UserID, productID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
+
+func (h *CartHandler) ClearCart(c *gin.Context) {
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	if err := h.cartService.ClearCart(c.Request.Context(), claims.UserID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CartHandler) MergeCart(c *gin.Context) {
+	var req dtos.MergeCartDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	cart, err := h.cartService.MergeCart(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, cart)
+}
@@ -0,0 +1,51 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amha-mersha/sanqa-suq/internal/auth"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CheckoutHandler struct {
+	checkoutService *services.CheckoutService
+}
+
+func NewCheckoutHandler(checkoutService *services.CheckoutService) *CheckoutHandler {
+	return &CheckoutHandler{
+		checkoutService: checkoutService,
+	}
+}
+
+// Checkout handles POST /checkout
+func (h *CheckoutHandler) Checkout(c *gin.Context) {
+	var req dtos.CheckoutRequestDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	order, err := h.checkoutService.Checkout(c.Request.Context(), claims.UserID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "ORDER_PLACED_SUCCESSFULLY",
+		"data": struct {
+			Order models.OrderWithItems `json:"order"`
+		}{Order: *order},
+	})
+}
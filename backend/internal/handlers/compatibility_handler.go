@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CompatibilityHandler struct {
+	compatibilityService *services.CompatibilityService
+}
+
+func NewCompatibilityHandler(compatibilityService *services.CompatibilityService) *CompatibilityHandler {
+	return &CompatibilityHandler{
+		compatibilityService: compatibilityService,
+	}
+}
+
+func (h *CompatibilityHandler) GetRules(c *gin.Context) {
+	var filter dtos.CompatibilityRuleFilterDTO
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	rules, err := h.compatibilityService.GetRules(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+func (h *CompatibilityHandler) GetRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_RULE_ID", err))
+		return
+	}
+
+	rule, err := h.compatibilityService.GetRule(c.Request.Context(), ruleID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *CompatibilityHandler) CreateRule(c *gin.Context) {
+	var req dtos.CreateCompatibilityRuleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	rule, err := h.compatibilityService.CreateRule(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *CompatibilityHandler) DeleteRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_RULE_ID", err))
+		return
+	}
+
+	if err := h.compatibilityService.DeleteRule(c.Request.Context(), ruleID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CompatibilityHandler) TestRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_RULE_ID", err))
+		return
+	}
+
+	var req dtos.TestCompatibilityRuleDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	result, err := h.compatibilityService.TestRule(c.Request.Context(), ruleID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type CouponHandler struct {
+	couponService *services.CouponService
+}
+
+func NewCouponHandler(couponService *services.CouponService) *CouponHandler {
+	return &CouponHandler{
+		couponService: couponService,
+	}
+}
+
+func (h *CouponHandler) GetCoupons(c *gin.Context) {
+	var filter dtos.CouponFilterDTO
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	coupons, err := h.couponService.GetCoupons(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coupons)
+}
+
+func (h *CouponHandler) GetCoupon(c *gin.Context) {
+	couponID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_COUPON_ID", err))
+		return
+	}
+
+	coupon, err := h.couponService.GetCoupon(c.Request.Context(), couponID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+func (h *CouponHandler) CreateCoupon(c *gin.Context) {
+	var req dtos.CreateCouponDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	coupon, err := h.couponService.CreateCoupon(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, coupon)
+}
+
+func (h *CouponHandler) UpdateCoupon(c *gin.Context) {
+	couponID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_COUPON_ID", err))
+		return
+	}
+
+	var req dtos.UpdateCouponDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	coupon, err := h.couponService.UpdateCoupon(c.Request.Context(), couponID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, coupon)
+}
+
+func (h *CouponHandler) DeleteCoupon(c *gin.Context) {
+	couponID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_COUPON_ID", err))
+		return
+	}
+
+	if err := h.couponService.DeleteCoupon(c.Request.Context(), couponID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *CouponHandler) GetRedemptions(c *gin.Context) {
+	couponID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_COUPON_ID", err))
+		return
+	}
+
+	redemptions, err := h.couponService.GetRedemptions(c.Request.Context(), couponID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, redemptions)
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type DiscountHandler struct {
+	discountService *services.DiscountService
+}
+
+func NewDiscountHandler(discountService *services.DiscountService) *DiscountHandler {
+	return &DiscountHandler{
+		discountService: discountService,
+	}
+}
+
+func (h *DiscountHandler) GetDiscounts(c *gin.Context) {
+	var filter dtos.DiscountFilterDTO
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	discounts, err := h.discountService.GetDiscounts(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, discounts)
+}
+
+func (h *DiscountHandler) GetDiscount(c *gin.Context) {
+	discountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_DISCOUNT_ID", err))
+		return
+	}
+
+	discount, err := h.discountService.GetDiscount(c.Request.Context(), discountID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, discount)
+}
+
+func (h *DiscountHandler) CreateDiscount(c *gin.Context) {
+	var req dtos.CreateDiscountDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	discount, err := h.discountService.CreateDiscount(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, discount)
+}
+
+func (h *DiscountHandler) UpdateDiscount(c *gin.Context) {
+	discountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_DISCOUNT_ID", err))
+		return
+	}
+
+	var req dtos.UpdateDiscountDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	discount, err := h.discountService.UpdateDiscount(c.Request.Context(), discountID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, discount)
+}
+
+func (h *DiscountHandler) DeleteDiscount(c *gin.Context) {
+	discountID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_DISCOUNT_ID", err))
+		return
+	}
+
+	if err := h.discountService.DeleteDiscount(c.Request.Context(), discountID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
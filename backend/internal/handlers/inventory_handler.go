@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/amha-mersha/sanqa-suq/internal/auth"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/amha-mersha/sanqa-suq/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryHandler struct {
+	inventoryService *services.InventoryService
+}
+
+func NewInventoryHandler(inventoryService *services.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+// ReceiveStock handles POST /inventory/product/:id/receive
+func (h *InventoryHandler) ReceiveStock(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	var req dtos.ReceiveStockDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	ledger, err := h.inventoryService.ReceiveStock(c.Request.Context(), productID, claims.UserID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, ledger)
+}
+
+// AdjustStock handles POST /inventory/product/:id/adjust
+func (h *InventoryHandler) AdjustStock(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	var req dtos.AdjustStockDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	ledger, err := h.inventoryService.AdjustStock(c.Request.Context(), productID, claims.UserID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// GetStockLedger handles GET /inventory/product/:id/ledger
+func (h *InventoryHandler) GetStockLedger(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	var filter dtos.StockLedgerFilterDTO
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	ledger, err := h.inventoryService.GetStockLedger(c.Request.Context(), productID, &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, ledger)
+}
+
+// GetLowStockProducts handles GET /inventory/low-stock
+func (h *InventoryHandler) GetLowStockProducts(c *gin.Context) {
+	var filter dtos.LowStockFilterDTO
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		c.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	products, err := h.inventoryService.GetLowStockProducts(c.Request.Context(), &filter)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, products)
+}
@@ -3,8 +3,10 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/amha-mersha/sanqa-suq/internal/auth"
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
 	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
 	"github.com/amha-mersha/sanqa-suq/internal/models"
 	"github.com/amha-mersha/sanqa-suq/internal/services"
 	"github.com/gin-gonic/gin"
@@ -45,7 +47,12 @@ func (handler *OrderHandler) GetOrder(ctx *gin.Context) {
 		ctx.Error(errs.BadRequest("INVALID_ORDER_ID", nil))
 		return
 	}
-	order, err := handler.service.GetOrder(ctx, orderID)
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+	order, err := handler.service.GetOrder(ctx, orderID, claims.UserID, claims.Role)
 	if err != nil {
 		ctx.Error(err)
 		return
@@ -53,14 +60,19 @@ func (handler *OrderHandler) GetOrder(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": "ORDER_FETCHED_SUCCESSFULLY",
 		"data": struct {
-			Order models.Order `json:"order"`
+			Order models.OrderWithItems `json:"order"`
 		}{Order: *order},
 	})
 }
 
 // GetAllOrders handles GET /orders
 func (handler *OrderHandler) GetAllOrders(ctx *gin.Context) {
-	orders, err := handler.service.GetAllOrders(ctx)
+	var filter dtos.OrderFilterDTO
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+	orders, err := handler.service.GetAllOrders(ctx, &filter)
 	if err != nil {
 		ctx.Error(err)
 		return
@@ -73,6 +85,26 @@ func (handler *OrderHandler) GetAllOrders(ctx *gin.Context) {
 	})
 }
 
+// GetMyOrders handles GET /order
+func (handler *OrderHandler) GetMyOrders(ctx *gin.Context) {
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+	orders, err := handler.service.GetOrdersByUser(ctx, claims.UserID, claims.UserID, claims.Role)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "ORDERS_FETCHED_BY_USER_SUCCESSFULLY",
+		"data": struct {
+			Orders []*models.Order `json:"orders"`
+		}{Orders: orders},
+	})
+}
+
 // GetOrdersByUser handles GET /order/user/:user_id
 func (handler *OrderHandler) GetOrdersByUser(ctx *gin.Context) {
 	userID := ctx.Param("user_id")
@@ -80,7 +112,12 @@ func (handler *OrderHandler) GetOrdersByUser(ctx *gin.Context) {
 		ctx.Error(errs.BadRequest("INVALID_USER_ID", nil))
 		return
 	}
-	orders, err := handler.service.GetOrdersByUser(ctx, userID)
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+	orders, err := handler.service.GetOrdersByUser(ctx, userID, claims.UserID, claims.Role)
 	if err != nil {
 		ctx.Error(err)
 		return
@@ -132,3 +169,58 @@ func (handler *OrderHandler) RemoveOrder(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, gin.H{"message": "ORDER_REMOVED_SUCCESSFULLY"})
 }
+
+// UpdateOrderStatus handles PATCH /order/:id/status
+func (handler *OrderHandler) UpdateOrderStatus(ctx *gin.Context) {
+	var dto dtos.UpdateOrderStatusDTO
+	if err := ctx.ShouldBindBodyWithJSON(&dto); err != nil {
+		ctx.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+	orderID := ctx.Param("id")
+	if orderID == "" {
+		ctx.Error(errs.BadRequest("INVALID_ORDER_ID", nil))
+		return
+	}
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+	order, err := handler.service.UpdateOrderStatus(ctx, orderID, claims.UserID, claims.Role, &dto)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "ORDER_STATUS_UPDATED_SUCCESSFULLY",
+		"data": struct {
+			Order models.OrderWithItems `json:"order"`
+		}{Order: *order},
+	})
+}
+
+// GetOrderStatusHistory handles GET /order/:id/history
+func (handler *OrderHandler) GetOrderStatusHistory(ctx *gin.Context) {
+	orderID := ctx.Param("id")
+	if orderID == "" {
+		ctx.Error(errs.BadRequest("INVALID_ORDER_ID", nil))
+		return
+	}
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+	history, err := handler.service.GetOrderStatusHistory(ctx, orderID, claims.UserID, claims.Role)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "ORDER_STATUS_HISTORY_FETCHED_SUCCESSFULLY",
+		"data": struct {
+			History []*models.OrderStatusHistory `json:"history"`
+		}{History: history},
+	})
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/amha-mersha/sanqa-suq/internal/auth"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/amha-mersha/sanqa-suq/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PaymentHandler struct {
+	paymentService *services.PaymentService
+}
+
+func NewPaymentHandler(paymentService *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+	}
+}
+
+// InitiatePayment handles POST /payment/order/:id/initiate
+func (h *PaymentHandler) InitiatePayment(c *gin.Context) {
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	intent, err := h.paymentService.InitiatePayment(c.Request.Context(), c.Param("id"), claims.UserID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, intent)
+}
+
+// GetPayment handles GET /payment/order/:id
+func (h *PaymentHandler) GetPayment(c *gin.Context) {
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	intent, err := h.paymentService.GetPayment(c.Request.Context(), c.Param("id"), claims.UserID, claims.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, intent)
+}
+
+// VerifyPayment handles POST /payment/order/:id/verify
+func (h *PaymentHandler) VerifyPayment(c *gin.Context) {
+	claims, ok := c.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		c.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	intent, err := h.paymentService.VerifyPayment(c.Request.Context(), c.Param("id"), claims.UserID, claims.Role)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, intent)
+}
+
+// RefundPayment handles POST /payment/order/:id/refund
+func (h *PaymentHandler) RefundPayment(c *gin.Context) {
+	intent, err := h.paymentService.RefundPayment(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, intent)
+}
+
+// HandleCallback handles POST /payment/callback/:provider. The raw body is passed on
+// untouched because providers sign the exact bytes they send.
+func (h *PaymentHandler) HandleCallback(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	intent, err := h.paymentService.HandleCallback(c.Request.Context(), c.Param("provider"), body, c.Request.Header)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": intent.Status})
+}
@@ -31,8 +31,8 @@ func (handler *ProductHandler) AddNewProduct(ctx *gin.Context) {
 		return
 	}
 	ctx.JSON(http.StatusCreated, gin.H{"error": "PRODUCT_ADDED_SUCCESSFULLY", "data": struct {
-		newProduct any `json:"new_product"`
-	}{newProduct: newProduct}})
+		NewProduct any `json:"new_product"`
+	}{NewProduct: newProduct}})
 }
 
 func (handler *ProductHandler) RemoveProduct(ctx *gin.Context) {
@@ -134,3 +134,18 @@ func (handler *ProductHandler) GetProductsByCategoryID(ctx *gin.Context) {
 	}{Products: products},
 	})
 }
+
+// SearchProducts handles GET /products/search
+func (handler *ProductHandler) SearchProducts(ctx *gin.Context) {
+	var filter dtos.ProductSearchDTO
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+	result, err := handler.service.SearchProducts(ctx, &filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{"message": "PRODUCTS_FETCHED_SUCCESSFULLY", "data": result})
+}
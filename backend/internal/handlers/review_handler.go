@@ -2,9 +2,12 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
+	"github.com/amha-mersha/sanqa-suq/internal/auth"
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
 	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
 	"github.com/amha-mersha/sanqa-suq/internal/models"
 	"github.com/amha-mersha/sanqa-suq/internal/services"
 	"github.com/gin-gonic/gin"
@@ -17,22 +20,28 @@ type ReviewHandler struct {
 func NewReviewHandler(service *services.ReviewService) *ReviewHandler {
 	return &ReviewHandler{service: service}
 }
+
 func (handler *ReviewHandler) AddNewReview(ctx *gin.Context) {
-	var reviewDTO dtos.CreateReviewDTO
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
 
+	var reviewDTO dtos.CreateReviewDTO
 	if err := ctx.ShouldBindBodyWithJSON(&reviewDTO); err != nil {
 		ctx.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
 		return
 	}
 
-	newReview, err := handler.service.AddNewReview(ctx, &reviewDTO)
+	newReview, err := handler.service.AddNewReview(ctx, claims.UserID, &reviewDTO)
 	if err != nil {
 		ctx.Error(err)
 		return
 	}
 
 	ctx.JSON(http.StatusCreated, gin.H{
-		"error": "REVIEW_ADDED_SUCCESSFULLY",
+		"message": "REVIEW_ADDED_SUCCESSFULLY",
 		"data": struct {
 			NewReview any `json:"new_review"`
 		}{
@@ -42,20 +51,25 @@ func (handler *ReviewHandler) AddNewReview(ctx *gin.Context) {
 }
 
 func (handler *ReviewHandler) UpdateReview(ctx *gin.Context) {
-	var reviewUpdateDTO dtos.UpdateReviewDTO
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
 
+	var reviewUpdateDTO dtos.UpdateReviewDTO
 	if err := ctx.ShouldBindBodyWithJSON(&reviewUpdateDTO); err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REQUEST_BODY"})
+		ctx.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
 		return
 	}
 
 	reviewID := ctx.Param("id")
 	if reviewID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REVIEW_ID"})
+		ctx.Error(errs.BadRequest("INVALID_REVIEW_ID", nil))
 		return
 	}
 
-	updatedReview, err := handler.service.UpdateReview(ctx, reviewID, &reviewUpdateDTO)
+	updatedReview, err := handler.service.UpdateReview(ctx, reviewID, claims.UserID, &reviewUpdateDTO)
 	if err != nil {
 		ctx.Error(err)
 		return
@@ -68,13 +82,19 @@ func (handler *ReviewHandler) UpdateReview(ctx *gin.Context) {
 }
 
 func (handler *ReviewHandler) RemoveReview(ctx *gin.Context) {
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
 	reviewID := ctx.Param("id")
 	if reviewID == "" {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "INVALID_REVIEW_ID"})
+		ctx.Error(errs.BadRequest("INVALID_REVIEW_ID", nil))
 		return
 	}
 
-	err := handler.service.RemoveReview(ctx, reviewID)
+	err := handler.service.RemoveReview(ctx, reviewID, claims.UserID, claims.Role)
 	if err != nil {
 		ctx.Error(err)
 		return
@@ -105,3 +125,147 @@ func (handler *ReviewHandler) GetReviewByID(ctx *gin.Context) {
 		},
 	})
 }
+
+// GetProductReviews handles GET /product/:id/reviews
+func (handler *ReviewHandler) GetProductReviews(ctx *gin.Context) {
+	productID, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	var filter dtos.ReviewFilterDTO
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	reviews, err := handler.service.GetProductReviews(ctx, productID, &filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "PRODUCT_REVIEWS_FETCHED_SUCCESSFULLY",
+		"data":    reviews,
+	})
+}
+
+// ReportReview handles POST /review/:id/report
+func (handler *ReviewHandler) ReportReview(ctx *gin.Context) {
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	var reportDTO dtos.ReportReviewDTO
+	if err := ctx.ShouldBindBodyWithJSON(&reportDTO); err != nil {
+		ctx.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	report, err := handler.service.ReportReview(ctx, ctx.Param("id"), claims.UserID, &reportDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message": "REVIEW_REPORTED_SUCCESSFULLY",
+		"data":    report,
+	})
+}
+
+// VoteHelpful handles POST /review/:id/helpful
+func (handler *ReviewHandler) VoteHelpful(ctx *gin.Context) {
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	review, err := handler.service.VoteHelpful(ctx, ctx.Param("id"), claims.UserID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "REVIEW_VOTED_HELPFUL",
+		"data":    review,
+	})
+}
+
+// RemoveHelpfulVote handles DELETE /review/:id/helpful
+func (handler *ReviewHandler) RemoveHelpfulVote(ctx *gin.Context) {
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	review, err := handler.service.RemoveHelpfulVote(ctx, ctx.Param("id"), claims.UserID)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "REVIEW_VOTE_REMOVED",
+		"data":    review,
+	})
+}
+
+// GetModerationQueue handles GET /review/moderation
+func (handler *ReviewHandler) GetModerationQueue(ctx *gin.Context) {
+	var filter dtos.ModerationQueueFilterDTO
+	if err := ctx.ShouldBindQuery(&filter); err != nil {
+		ctx.Error(errs.BadRequest("INVALID_QUERY_PARAMETERS", err))
+		return
+	}
+
+	queue, err := handler.service.GetModerationQueue(ctx, &filter)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "MODERATION_QUEUE_FETCHED_SUCCESSFULLY",
+		"data": struct {
+			Reviews []*models.ModerationQueueItem `json:"reviews"`
+		}{Reviews: queue},
+	})
+}
+
+// ModerateReview handles POST /review/:id/moderate
+func (handler *ReviewHandler) ModerateReview(ctx *gin.Context) {
+	claims, ok := ctx.Request.Context().Value(middlewares.UserClaimsKey).(*auth.CustomClaims)
+	if !ok {
+		ctx.Error(errs.Unauthorized("MISSING_CLAIMS", nil))
+		return
+	}
+
+	var moderateDTO dtos.ModerateReviewDTO
+	if err := ctx.ShouldBindBodyWithJSON(&moderateDTO); err != nil {
+		ctx.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	review, err := handler.service.ModerateReview(ctx, ctx.Param("id"), claims.UserID, &moderateDTO)
+	if err != nil {
+		ctx.Error(err)
+		return
+	}
+
+	if review == nil {
+		ctx.JSON(http.StatusOK, gin.H{"message": "REVIEW_REMOVED_SUCCESSFULLY"})
+		return
+	}
+	ctx.JSON(http.StatusOK, gin.H{
+		"message": "REVIEW_MODERATED_SUCCESSFULLY",
+		"data":    review,
+	})
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SpecHandler struct {
+	specService *services.SpecService
+}
+
+func NewSpecHandler(specService *services.SpecService) *SpecHandler {
+	return &SpecHandler{
+		specService: specService,
+	}
+}
+
+func (h *SpecHandler) GetProductSpecs(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	specs, err := h.specService.GetProductSpecs(c.Request.Context(), productID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, specs)
+}
+
+func (h *SpecHandler) ReplaceProductSpecs(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	var req dtos.ReplaceProductSpecsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	specs, err := h.specService.ReplaceProductSpecs(c.Request.Context(), productID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, specs)
+}
+
+func (h *SpecHandler) UpdateProductSpecs(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	var req dtos.UpdateProductSpecsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	specs, err := h.specService.UpdateProductSpecs(c.Request.Context(), productID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, specs)
+}
+
+func (h *SpecHandler) RemoveProductSpec(c *gin.Context) {
+	productID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_PRODUCT_ID", err))
+		return
+	}
+
+	specs, err := h.specService.RemoveProductSpec(c.Request.Context(), productID, c.Param("name"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, specs)
+}
+
+func (h *SpecHandler) GetCategoryDefinitions(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_CATEGORY_ID", err))
+		return
+	}
+
+	definitions, err := h.specService.GetCategoryDefinitions(c.Request.Context(), categoryID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, definitions)
+}
+
+func (h *SpecHandler) SetCategoryDefinitions(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_CATEGORY_ID", err))
+		return
+	}
+
+	var req dtos.SetCategorySpecDefinitionsDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	definitions, err := h.specService.SetCategoryDefinitions(c.Request.Context(), categoryID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, definitions)
+}
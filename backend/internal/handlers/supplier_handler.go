@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+type SupplierHandler struct {
+	supplierService *services.SupplierService
+}
+
+func NewSupplierHandler(supplierService *services.SupplierService) *SupplierHandler {
+	return &SupplierHandler{
+		supplierService: supplierService,
+	}
+}
+
+func (h *SupplierHandler) GetSuppliers(c *gin.Context) {
+	suppliers, err := h.supplierService.GetSuppliers(c.Request.Context())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, suppliers)
+}
+
+func (h *SupplierHandler) GetSupplier(c *gin.Context) {
+	supplierID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_SUPPLIER_ID", err))
+		return
+	}
+
+	supplier, err := h.supplierService.GetSupplier(c.Request.Context(), supplierID)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, supplier)
+}
+
+func (h *SupplierHandler) CreateSupplier(c *gin.Context) {
+	var req dtos.CreateSupplierDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	supplier, err := h.supplierService.CreateSupplier(c.Request.Context(), &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, supplier)
+}
+
+func (h *SupplierHandler) UpdateSupplier(c *gin.Context) {
+	supplierID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_SUPPLIER_ID", err))
+		return
+	}
+
+	var req dtos.UpdateSupplierDTO
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errs.BadRequest("INVALID_REQUEST_BODY", err))
+		return
+	}
+
+	supplier, err := h.supplierService.UpdateSupplier(c.Request.Context(), supplierID, &req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, supplier)
+}
+
+func (h *SupplierHandler) DeleteSupplier(c *gin.Context) {
+	supplierID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.Error(errs.BadRequest("INVALID_SUPPLIER_ID", err))
+		return
+	}
+
+	if err := h.supplierService.DeleteSupplier(c.Request.Context(), supplierID); err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -27,19 +27,7 @@ const UserClaimsKey contextKey = "user_claims"
 // AuthMiddleware verifies JWT tokens in requests
 func (a *AuthMiddleware) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := ""
-		// Check cookie first
-		if cookie, err := c.Cookie("token"); err == nil {
-			tokenString = cookie
-		} else {
-			// Fallback to Authorization header
-			authHeader := c.GetHeader("Authorization")
-			parts := strings.Split(authHeader, " ")
-			if len(parts) == 2 && parts[0] == "Bearer" {
-				tokenString = parts[1]
-			}
-		}
-
+		tokenString := requestToken(c)
 		if tokenString == "" {
 			c.Error(errs.Unauthorized("missing token", nil))
 			c.Abort()
@@ -60,6 +48,42 @@ func (a *AuthMiddleware) AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// OptionalAuthMiddleware sets the claims when the request carries a token and lets
+// anonymous requests through; an invalid token is still rejected
+func (a *AuthMiddleware) OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tokenString := requestToken(c)
+		if tokenString == "" {
+			c.Next()
+			return
+		}
+
+		claims, err := a.jwtService.ValidateToken(tokenString)
+		if err != nil {
+			c.Error(errs.Unauthorized("invalid token", err))
+			c.Abort()
+			return
+		}
+
+		ctx := context.WithValue(c.Request.Context(), UserClaimsKey, claims)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// requestToken reads the JWT from the token cookie, or else the Authorization header
+func requestToken(c *gin.Context) string {
+	if cookie, err := c.Cookie("token"); err == nil {
+		return cookie
+	}
+	authHeader := c.GetHeader("Authorization")
+	parts := strings.Split(authHeader, " ")
+	if len(parts) == 2 && parts[0] == "Bearer" {
+		return parts[1]
+	}
+	return ""
+}
+
 // RequireRole creates a middleware that checks if the user has the required role
 func (a *AuthMiddleware) RequireRole(requiredRole string) gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -14,10 +14,14 @@ func ErrorHandler() gin.HandlerFunc {
 			err := c.Errors.Last().Err
 
 			if appErr, ok := err.(*errs.AppError); ok {
-				c.JSON(appErr.StatusCode, gin.H{
+				body := gin.H{
 					"error": appErr.Message,
 					"code":  appErr.Code,
-				})
+				}
+				if appErr.Meta != nil {
+					body["details"] = appErr.Meta
+				}
+				c.JSON(appErr.StatusCode, body)
 				return
 			}
 
@@ -4,12 +4,31 @@ import (
 	"time"
 )
 
+const (
+	BuildVisibilityPrivate  = "private"
+	BuildVisibilityUnlisted = "unlisted"
+	BuildVisibilityPublic   = "public"
+)
+
+const (
+	PSUStatusOK               = "ok"
+	PSUStatusBelowRecommended = "below_recommended"
+	PSUStatusUndersized       = "undersized"
+)
+
+// CustomBuild is a user's build. Unlisted builds are visible to anyone with the link,
+// public ones are listed in the gallery as well; Slug is set once the build is shared.
 type CustomBuild struct {
-	BuildID    string    `json:"build_id"`
-	UserID     string    `json:"user_id"`
-	Name       string    `json:"name"`
-	CreatedAt  time.Time `json:"created_at"`
-	TotalPrice float64   `json:"total_price"`
+	BuildID    string     `json:"build_id"`
+	UserID     string     `json:"user_id"`
+	Name       string     `json:"name"`
+	CreatedAt  time.Time  `json:"created_at"`
+	TotalPrice float64    `json:"total_price"`
+	Visibility string     `json:"visibility"`
+	Slug       *string    `json:"slug"`
+	CloneCount int        `json:"clone_count"`
+	ClonedFrom *string    `json:"cloned_from"`
+	SharedAt   *time.Time `json:"shared_at"`
 }
 
 type BuildItem struct {
@@ -21,6 +40,11 @@ type BuildItem struct {
 	Description  string  `json:"description"`
 	BrandName    string  `json:"brand_name"`
 	CategoryName string  `json:"category_name"`
+	// SavedPrice is what the item cost when the build was last saved, CurrentPrice what
+	// it costs now with discounts
+	SavedPrice    float64 `json:"saved_price"`
+	CurrentPrice  float64 `json:"current_price"`
+	StockQuantity int     `json:"stock_quantity"`
 }
 
 type BuildWithItems struct {
@@ -28,12 +52,141 @@ type BuildWithItems struct {
 	Items []BuildItem `json:"items"`
 }
 
+// BuildSummary is a gallery entry; ItemCount counts units, not distinct products
+type BuildSummary struct {
+	CustomBuild
+	ItemCount int `json:"item_count"`
+}
+
+type BuildGallery struct {
+	Builds []BuildSummary `json:"builds"`
+	Total  int            `json:"total"`
+	Limit  int            `json:"limit"`
+	Offset int            `json:"offset"`
+}
+
 type CompatibleProduct struct {
-	ProductID    int               `json:"product_id"`
-	ProductName  string            `json:"product_name"`
-	Price        float64           `json:"price"`
-	Description  string            `json:"description"`
-	BrandName    string            `json:"brand_name"`
-	CategoryName string            `json:"category_name"`
-	Specs        map[string]string `json:"specs"`
+	ProductID     int               `json:"product_id"`
+	ProductName   string            `json:"product_name"`
+	Price         float64           `json:"price"`
+	Pricing       PriceBreakdown    `json:"pricing"`
+	Description   string            `json:"description"`
+	BrandName     string            `json:"brand_name"`
+	CategoryName  string            `json:"category_name"`
+	StockQuantity int               `json:"stock_quantity"`
+	Specs         map[string]string `json:"specs"`
+}
+
+// ComponentPower is the estimated draw of one build item; components without a tdp_watts
+// spec are covered by the base system allowance and have no TDP
+type ComponentPower struct {
+	ProductID    int      `json:"product_id"`
+	ProductName  string   `json:"product_name"`
+	Quantity     int      `json:"quantity"`
+	TDPWatts     *float64 `json:"tdp_watts"`
+	PeakWatts    float64  `json:"peak_watts"`
+	TypicalWatts float64  `json:"typical_watts"`
+}
+
+// PSUCheck compares the power supplies of a build with the estimated draw. Status is
+// "ok", "below_recommended" (covers the peak but not the headroom) or "undersized".
+type PSUCheck struct {
+	ProductIDs     []int   `json:"product_ids"`
+	WattageWatts   float64 `json:"wattage_watts"`
+	Status         string  `json:"status"`
+	ShortfallWatts float64 `json:"shortfall_watts"`
+}
+
+// BuildPowerAnalysis is the power budget of a build and the PSU it needs
+type BuildPowerAnalysis struct {
+	BuildID             string           `json:"build_id"`
+	Components          []ComponentPower `json:"components"`
+	BaseSystemWatts     float64          `json:"base_system_watts"`
+	PeakWatts           float64          `json:"peak_watts"`
+	TypicalWatts        float64          `json:"typical_watts"`
+	HeadroomPercent     float64          `json:"headroom_percent"`
+	RecommendedPSUWatts float64          `json:"recommended_psu_watts"`
+	PSU                 *PSUCheck        `json:"psu"`
+}
+
+const (
+	BuildPreferenceCheapest    = "cheapest"
+	BuildPreferenceBalanced    = "balanced"
+	BuildPreferencePerformance = "performance"
+)
+
+// CompletionItem is an item of a suggested build; Added marks the suggested ones
+type CompletionItem struct {
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	CategoryID  int     `json:"category_id"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"`
+	Added       bool    `json:"added"`
+}
+
+// BuildCompletion is one way of filling the missing categories of a partial build
+type BuildCompletion struct {
+	Rank       int                    `json:"rank"`
+	Items      []CompletionItem       `json:"items"`
+	TotalPrice float64                `json:"total_price"`
+	Validation *BuildValidationReport `json:"validation"`
+}
+
+const (
+	BuildChangeCreated         = "created"
+	BuildChangeUpdated         = "updated"
+	BuildChangeCloned          = "cloned"
+	BuildChangeItemAdded       = "item_added"
+	BuildChangeItemRemoved     = "item_removed"
+	BuildChangeQuantityChanged = "quantity_changed"
+	BuildChangeRestored        = "restored"
+)
+
+type BuildRevisionItem struct {
+	ProductID int `json:"product_id"`
+	Quantity  int `json:"quantity"`
+}
+
+// BuildRevision is the state of a build after one change; RestoredFrom is set when the
+// change restored an earlier revision
+type BuildRevision struct {
+	BuildID      string              `json:"build_id"`
+	Revision     int                 `json:"revision"`
+	Name         string              `json:"name"`
+	Items        []BuildRevisionItem `json:"items"`
+	TotalPrice   float64             `json:"total_price"`
+	Change       string              `json:"change"`
+	RestoredFrom *int                `json:"restored_from"`
+	CreatedAt    time.Time           `json:"created_at"`
+}
+
+const (
+	BuildPriceEdited   = "edited"
+	BuildPriceRepriced = "repriced"
+)
+
+// BuildPricePoint is the total of a build from a moment on; Reason tells whether the owner
+// changed the items or product prices changed
+type BuildPricePoint struct {
+	TotalPrice float64   `json:"total_price"`
+	Reason     string    `json:"reason"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// BuildPriceAlert notifies the owner when the total of a build drops below Threshold.
+// IsBelow is whether the total already is, so that only a new drop is notified.
+type BuildPriceAlert struct {
+	BuildID    string     `json:"build_id"`
+	Threshold  float64    `json:"threshold"`
+	IsBelow    bool       `json:"is_below"`
+	CreatedAt  time.Time  `json:"created_at"`
+	NotifiedAt *time.Time `json:"notified_at"`
+}
+
+type BuildPriceHistory struct {
+	BuildID      string             `json:"build_id"`
+	CurrentTotal float64            `json:"current_total"`
+	Alert        *BuildPriceAlert   `json:"alert"`
+	History      []*BuildPricePoint `json:"history"`
 }
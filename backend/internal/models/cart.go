@@ -0,0 +1,13 @@
+package models
+
+type CartItem struct {
+	UserID             string  `json:"user_id"`
+	ProductID          int     `json:"product_id"`
+	ProductName        string  `json:"product_name"`
+	OriginalPrice      float64 `json:"original_price"`
+	DiscountPercentage float64 `json:"discount_percentage"`
+	Price              float64 `json:"price"` // effective unit price
+	Quantity           int     `json:"quantity"`
+	LineTotal          float64 `json:"line_total"`
+	StockQuantity      int     `json:"stock_quantity"`
+}
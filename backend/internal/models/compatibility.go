@@ -0,0 +1,99 @@
+package models
+
+import "time"
+
+const (
+	RuleSeverityError   = "error"
+	RuleSeverityWarning = "warning"
+
+	RuleOperatorEquals = "equals"
+	RuleOperatorInSet  = "in_set"
+	RuleOperatorGTE    = "gte"
+	RuleOperatorLTE    = "lte"
+
+	RuleAggregateSum   = "sum"
+	RuleAggregateCount = "count"
+
+	RuleQuantifierAll = "all"
+	RuleQuantifierAny = "any"
+
+	RuleStatusPassed        = "passed"
+	RuleStatusFailed        = "failed"
+	RuleStatusNotApplicable = "not_applicable"
+)
+
+// CompatibilityRule checks the targets of a build against its subject; see migration 000016
+type CompatibilityRule struct {
+	RuleID             int       `json:"rule_id"`
+	Name               string    `json:"name"`
+	Description        *string   `json:"description"`
+	Severity           string    `json:"severity"`
+	SubjectCategoryID  *int      `json:"subject_category_id"`
+	SubjectProductID   *int      `json:"subject_product_id"`
+	SubjectFilterSpec  *string   `json:"subject_filter_spec"`
+	SubjectFilterValue *string   `json:"subject_filter_value"`
+	TargetCategoryID   *int      `json:"target_category_id"`
+	TargetFilterSpec   *string   `json:"target_filter_spec"`
+	TargetFilterValue  *string   `json:"target_filter_value"`
+	TargetSpec         *string   `json:"target_spec"`
+	TargetAggregate    *string   `json:"target_aggregate"`
+	Operator           string    `json:"operator"`
+	Quantifier         string    `json:"quantifier"`
+	Margin             float64   `json:"margin"`
+	SubjectSpec        *string   `json:"subject_spec"`
+	Value              *string   `json:"value"`
+	CreatedAt          time.Time `json:"created_at"`
+}
+
+// BuildComponent is a product of a build as the rule engine sees it
+type BuildComponent struct {
+	ProductID   int               `json:"product_id"`
+	Name        string            `json:"name"`
+	CategoryID  int               `json:"category_id"`
+	CategoryIDs []int             `json:"-"` // its category and every ancestor
+	Quantity    int               `json:"quantity"`
+	Specs       map[string]string `json:"specs"`
+}
+
+// RuleResult is the outcome of one rule for a build
+type RuleResult struct {
+	RuleID     int             `json:"rule_id"`
+	Name       string          `json:"name"`
+	Severity   string          `json:"severity"`
+	Status     string          `json:"status"`
+	Violations []RuleViolation `json:"violations"`
+}
+
+// RuleViolation is one failed check of a rule, with replacements that would clear it
+type RuleViolation struct {
+	SubjectProductID int                  `json:"subject_product_id"`
+	TargetProductIDs []int                `json:"target_product_ids"`
+	ProductIDs       []int                `json:"product_ids"`
+	Required         string               `json:"required"`
+	Actual           *string              `json:"actual"`
+	Message          string               `json:"message"`
+	Alternatives     []ProductAlternative `json:"alternatives,omitempty"`
+}
+
+// ProductAlternative is a product that could replace ReplacesProductID in a build
+type ProductAlternative struct {
+	ReplacesProductID int     `json:"replaces_product_id"`
+	ProductID         int     `json:"product_id"`
+	ProductName       string  `json:"product_name"`
+	Price             float64 `json:"price"`
+}
+
+// BuildValidationReport lists the rules a build fails
+type BuildValidationReport struct {
+	Compatible bool         `json:"compatible"`
+	Errors     int          `json:"errors"`
+	Warnings   int          `json:"warnings"`
+	Rules      []RuleResult `json:"rules"`
+}
+
+// RuleTestResult is the outcome of running one rule against a set of products
+type RuleTestResult struct {
+	Rule       *CompatibilityRule `json:"rule"`
+	ProductIDs []int              `json:"product_ids"`
+	Result     RuleResult         `json:"result"`
+}
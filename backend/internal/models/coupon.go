@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+type Coupon struct {
+	CouponID          int        `json:"coupon_id"`
+	Code              string     `json:"code"`
+	Description       *string    `json:"description"`
+	CouponType        string     `json:"coupon_type"`
+	Value             float64    `json:"value"`
+	MinOrderAmount    float64    `json:"min_order_amount"`
+	MaxDiscountAmount *float64   `json:"max_discount_amount"`
+	UsageLimit        *int       `json:"usage_limit"`
+	PerUserLimit      *int       `json:"per_user_limit"`
+	StartsAt          time.Time  `json:"starts_at"`
+	ExpiresAt         *time.Time `json:"expires_at"`
+	IsActive          bool       `json:"is_active"`
+	CreatedAt         time.Time  `json:"created_at"`
+	TimesRedeemed     int        `json:"times_redeemed"`
+	CategoryIDs       []int      `json:"category_ids"`
+	BrandIDs          []int      `json:"brand_ids"`
+}
+
+type CouponRedemption struct {
+	RedemptionID   int       `json:"redemption_id"`
+	CouponID       int       `json:"coupon_id"`
+	Code           string    `json:"code"`
+	UserID         string    `json:"user_id"`
+	OrderID        string    `json:"order_id"`
+	DiscountAmount float64   `json:"discount_amount"`
+	RedeemedAt     time.Time `json:"redeemed_at"`
+}
+
+// CouponQuote is what a coupon is worth for a set of items, before it is redeemed
+type CouponQuote struct {
+	CouponID         int     `json:"coupon_id"`
+	Code             string  `json:"code"`
+	EligibleSubtotal float64 `json:"eligible_subtotal"`
+	DiscountAmount   float64 `json:"discount_amount"`
+}
+
+const (
+	CouponTypePercentage = "percentage"
+	CouponTypeFixed      = "fixed"
+)
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+type Discount struct {
+	DiscountID         int       `json:"discount_id"`
+	ProductID          *int      `json:"product_id"`
+	CategoryID         *int      `json:"category_id"`
+	DiscountPercentage float64   `json:"discount_percentage"`
+	StartDate          time.Time `json:"start_date"`
+	EndDate            time.Time `json:"end_date"`
+	Active             bool      `json:"active"`
+}
+
+// PriceBreakdown is a product's list price with the best discount currently applied to it
+type PriceBreakdown struct {
+	OriginalPrice      float64 `json:"original_price"`
+	DiscountID         *int    `json:"discount_id"`
+	DiscountPercentage float64 `json:"discount_percentage"`
+	DiscountAmount     float64 `json:"discount_amount"`
+	FinalPrice         float64 `json:"final_price"`
+}
@@ -0,0 +1,67 @@
+package models
+
+import "time"
+
+type Supplier struct {
+	SupplierID   int       `json:"supplier_id"`
+	Name         string    `json:"name"`
+	ContactEmail *string   `json:"contact_email"`
+	Phone        *string   `json:"phone"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// InventoryLot is a quantity of a product received from one supplier
+type InventoryLot struct {
+	InventoryID  int       `json:"inventory_id"`
+	ProductID    int       `json:"product_id"`
+	SupplierID   *int      `json:"supplier_id"`
+	SupplierName *string   `json:"supplier_name"`
+	Quantity     int       `json:"quantity"`
+	LastUpdated  time.Time `json:"last_updated"`
+}
+
+// StockMovement is one row of the product_stock_ledger view
+type StockMovement struct {
+	MovementID     int       `json:"movement_id"`
+	ProductID      int       `json:"product_id"`
+	InventoryID    *int      `json:"inventory_id"`
+	SupplierID     *int      `json:"supplier_id"`
+	SupplierName   *string   `json:"supplier_name"`
+	OrderID        *string   `json:"order_id"`
+	QuantityChange int       `json:"quantity_change"`
+	BalanceAfter   int       `json:"balance_after"`
+	Reason         string    `json:"reason"`
+	Note           *string   `json:"note"`
+	PerformedBy    *string   `json:"performed_by"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type StockLedger struct {
+	ProductID     int             `json:"product_id"`
+	ProductName   string          `json:"product_name"`
+	StockQuantity int             `json:"stock_quantity"`
+	Lots          []InventoryLot  `json:"lots"`
+	Movements     []StockMovement `json:"movements"`
+}
+
+type LowStockProduct struct {
+	ProductID     int        `json:"product_id"`
+	Name          string     `json:"name"`
+	CategoryID    int        `json:"category_id"`
+	BrandID       int        `json:"brand_id"`
+	StockQuantity int        `json:"stock_quantity"`
+	LastReceiptAt *time.Time `json:"last_receipt_at"`
+}
+
+const (
+	StockReasonOpeningBalance = "opening_balance"
+	StockReasonReceipt        = "receipt"
+	StockReasonDamaged        = "damaged"
+	StockReasonLost           = "lost"
+	StockReasonFound          = "found"
+	StockReasonCorrection     = "correction"
+	StockReasonCustomerReturn = "customer_return"
+	StockReasonSupplierReturn = "supplier_return"
+	StockReasonSale           = "sale"
+	StockReasonOrderCancelled = "order_cancelled"
+)
@@ -0,0 +1,18 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const NotificationBuildPriceDrop = "build_price_drop"
+
+type Notification struct {
+	NotificationID int             `json:"notification_id"`
+	UserID         string          `json:"user_id"`
+	Type           string          `json:"type"`
+	Message        string          `json:"message"`
+	Data           json.RawMessage `json:"data"`
+	CreatedAt      time.Time       `json:"created_at"`
+	ReadAt         *time.Time      `json:"read_at"`
+}
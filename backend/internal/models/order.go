@@ -12,3 +12,43 @@ type Order struct {
 	PaymentMethod string     `json:"payment_method"`
 	PaymentDate   *time.Time `json:"payment_date"` // optional
 }
+
+type OrderItem struct {
+	OrderID     string  `json:"order_id"`
+	ProductID   int     `json:"product_id"`
+	ProductName string  `json:"product_name"`
+	Quantity    int     `json:"quantity"`
+	UnitPrice   float64 `json:"unit_price"`
+}
+
+type OrderWithItems struct {
+	Order
+	Items  []OrderItem       `json:"items"`
+	Coupon *CouponRedemption `json:"coupon,omitempty"`
+}
+
+type OrderStatusHistory struct {
+	HistoryID  int       `json:"history_id"`
+	OrderID    string    `json:"order_id"`
+	FromStatus *string   `json:"from_status"`
+	ToStatus   string    `json:"to_status"`
+	ChangedBy  *string   `json:"changed_by"`
+	Reason     *string   `json:"reason"`
+	ChangedAt  time.Time `json:"changed_at"`
+}
+
+const (
+	OrderStatusPending   = "pending"
+	OrderStatusPaid      = "paid"
+	OrderStatusShipped   = "shipped"
+	OrderStatusDelivered = "delivered"
+	OrderStatusCancelled = "cancelled"
+)
+
+// OrderStatusTransitions lists the statuses an order may move to from each status.
+// delivered and cancelled are terminal.
+var OrderStatusTransitions = map[string][]string{
+	OrderStatusPending: {OrderStatusPaid, OrderStatusCancelled},
+	OrderStatusPaid:    {OrderStatusShipped, OrderStatusCancelled},
+	OrderStatusShipped: {OrderStatusDelivered},
+}
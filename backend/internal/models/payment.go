@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+type PaymentIntent struct {
+	IntentID          string    `json:"intent_id"`
+	OrderID           string    `json:"order_id"`
+	Provider          string    `json:"provider"`
+	ProviderReference *string   `json:"provider_reference"`
+	Amount            float64   `json:"amount"`
+	Status            string    `json:"status"`
+	CheckoutURL       *string   `json:"checkout_url"`
+	RefundReference   *string   `json:"refund_reference,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
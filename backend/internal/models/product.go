@@ -3,14 +3,18 @@ package models
 import "time"
 
 type Products struct {
-	ProductID     int       `json:"product_id"`
-	CategoryID    int       `json:"categroy_id"`
-	BrandID       int       `json:"brand_id"`
-	Name          string    `json:"name"`
-	Description   string    `json:"description"`
-	Price         *float64  `json:"price"`
-	StockQuantity *int      `json:"stock_quantity"`
-	CreatedAt     time.Time `json:"created_at"`
+	ProductID     int             `json:"product_id"`
+	CategoryID    int             `json:"categroy_id"`
+	BrandID       int             `json:"brand_id"`
+	Name          string          `json:"name"`
+	Description   string          `json:"description"`
+	Price         *float64        `json:"price"`
+	StockQuantity *int            `json:"stock_quantity"`
+	CreatedAt     time.Time       `json:"created_at"`
+	Pricing       *PriceBreakdown `json:"pricing,omitempty"`
+
+	// Specifications is filled in on single-product responses
+	Specifications map[string]string `json:"specifications,omitempty"`
 }
 
 type ProductSpecifications struct {
@@ -18,11 +22,3 @@ type ProductSpecifications struct {
 	SpecName  string `json:"spec_name"`
 	SpecValue string `json:"spec_value"`
 }
-type Review struct {
-	ReviewId   int       `json:"review_id"`
-	UserId     int       `json:"user_id"`
-	ProductId  int       `json:"product_id"`
-	Rating     int       `json:"rating"`
-	Comment    string    `json:"comment"`
-	ReviewDate time.Time `json:"review_date"`
-}
@@ -0,0 +1,76 @@
+package models
+
+import "time"
+
+const (
+	ReviewStatusPublished = "published"
+	ReviewStatusHeld      = "held"
+	ReviewStatusHidden    = "hidden"
+)
+
+// Moderation actions as recorded in review_moderation_log
+const (
+	ModerationActionHeld     = "held"
+	ModerationActionApproved = "approved"
+	ModerationActionHidden   = "hidden"
+	ModerationActionDeleted  = "deleted"
+)
+
+type Review struct {
+	ReviewId   string    `json:"review_id"`
+	UserId     string    `json:"user_id"`
+	ProductId  int       `json:"product_id"`
+	Rating     int       `json:"rating"`
+	Comment    string    `json:"comment"`
+	ReviewDate time.Time `json:"review_date"`
+
+	// VerifiedPurchase is set when the author has a delivered order containing the product
+	VerifiedPurchase bool `json:"verified_purchase"`
+
+	// Only published reviews are listed; held ones wait for a moderator and hidden ones were rejected
+	Status           string  `json:"status"`
+	ModerationReason *string `json:"moderation_reason,omitempty"`
+	HelpfulCount     int     `json:"helpful_count"`
+}
+
+// ProductReview is a review as listed on a product page, with its author's display name
+type ProductReview struct {
+	Review
+	AuthorName string `json:"author_name"`
+}
+
+// RatingSummary aggregates every published review of a product. Distribution maps each
+// star rating (1-5) to the number of reviews that gave it.
+type RatingSummary struct {
+	ProductID     int         `json:"product_id"`
+	AverageRating float64     `json:"average_rating"`
+	ReviewCount   int         `json:"review_count"`
+	VerifiedCount int         `json:"verified_count"`
+	Distribution  map[int]int `json:"distribution"`
+}
+
+type ProductReviewList struct {
+	Summary RatingSummary    `json:"summary"`
+	Reviews []*ProductReview `json:"reviews"`
+	Limit   int              `json:"limit"`
+	Offset  int              `json:"offset"`
+}
+
+type ReviewReport struct {
+	ReportID   int        `json:"report_id"`
+	ReviewID   string     `json:"review_id"`
+	UserID     string     `json:"user_id"`
+	Reason     string     `json:"reason"`
+	Note       *string    `json:"note"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at"`
+}
+
+// ModerationQueueItem is a review awaiting a moderator, with its unresolved reports
+type ModerationQueueItem struct {
+	Review
+	AuthorName    string   `json:"author_name"`
+	ProductName   string   `json:"product_name"`
+	OpenReports   int      `json:"open_reports"`
+	ReportReasons []string `json:"report_reasons"`
+}
@@ -0,0 +1,69 @@
+package models
+
+// ProductSearchResult is one page of search results with the facets of the whole result set
+type ProductSearchResult struct {
+	Products []*ProductSearchHit `json:"products"`
+	Total    int                 `json:"total"`
+	Limit    int                 `json:"limit"`
+	Offset   int                 `json:"offset"`
+	Facets   ProductFacets       `json:"facets"`
+}
+
+// ProductSearchHit is a matching product. Keyword searches add the full-text rank and the
+// name and description with matched terms wrapped in <mark> tags.
+type ProductSearchHit struct {
+	*Products
+	Rank      *float64          `json:"rank,omitempty"`
+	Highlight *ProductHighlight `json:"highlight,omitempty"`
+}
+
+type ProductHighlight struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// ProductFacets count the matching products per brand and per specification value. Each
+// facet ignores its own filter, so the counts show what selecting another value would give.
+type ProductFacets struct {
+	Brands []BrandFacet `json:"brands"`
+	Specs  []SpecFacet  `json:"specs"`
+}
+
+type BrandFacet struct {
+	BrandID int    `json:"brand_id"`
+	Name    string `json:"name"`
+	Count   int    `json:"count"`
+}
+
+// SpecFacet counts the values of a specification; numeric specs also give the lowest and
+// highest value for range filters
+type SpecFacet struct {
+	SpecName string           `json:"spec_name"`
+	Range    *SpecFacetRange  `json:"range,omitempty"`
+	Values   []SpecFacetValue `json:"values"`
+}
+
+type SpecFacetRange struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+type SpecFacetValue struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+const (
+	SuggestionTypeProduct  = "product"
+	SuggestionTypeBrand    = "brand"
+	SuggestionTypeCategory = "category"
+)
+
+// SearchSuggestion is one entry of the search box dropdown. Score combines trigram
+// similarity with a bonus for names starting with the typed text.
+type SearchSuggestion struct {
+	Type  string  `json:"type"`
+	ID    int     `json:"id"`
+	Text  string  `json:"text"`
+	Score float64 `json:"score"`
+}
@@ -0,0 +1,35 @@
+package models
+
+const (
+	SpecTypeText    = "text"
+	SpecTypeEnum    = "enum"
+	SpecTypeInteger = "integer"
+	SpecTypeDecimal = "decimal"
+	SpecTypeBoolean = "boolean"
+)
+
+// SpecDefinition declares a specification products of a category may carry. Definitions
+// are inherited by subcategories, which may override them; DefinedIn is the category the
+// definition comes from.
+type SpecDefinition struct {
+	CategoryID    int      `json:"category_id"`
+	SpecName      string   `json:"spec_name"`
+	DataType      string   `json:"data_type"`
+	Unit          *string  `json:"unit"`
+	AllowedValues []string `json:"allowed_values,omitempty"`
+	Required      bool     `json:"required"`
+	Filterable    bool     `json:"filterable"`
+	Description   *string  `json:"description"`
+	DefinedIn     int      `json:"defined_in"`
+}
+
+// IsNumeric reports whether values of the specification are numbers that can be range filtered
+func (d *SpecDefinition) IsNumeric() bool {
+	return d.DataType == SpecTypeInteger || d.DataType == SpecTypeDecimal
+}
+
+// SpecValue is a specification value in canonical form; numeric specs also carry the number
+type SpecValue struct {
+	Value   string
+	Numeric *float64
+}
@@ -0,0 +1,142 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+type CBEBirrConfig struct {
+	BaseURL    string
+	MerchantID string
+	APIKey     string
+}
+
+// CBEBirrProvider takes payments through the CBE Birr merchant gateway
+type CBEBirrProvider struct {
+	config CBEBirrConfig
+	client *signedClient
+}
+
+func NewCBEBirrProvider(config CBEBirrConfig) *CBEBirrProvider {
+	return &CBEBirrProvider{
+		config: config,
+		client: newSignedClient(config.BaseURL, config.APIKey, "X-CBE-Signature"),
+	}
+}
+
+type cbeBirrResponse struct {
+	Status          string     `json:"status"`
+	Message         string     `json:"message"`
+	TransactionRef  string     `json:"transaction_reference"`
+	PaymentURL      string     `json:"payment_url"`
+	PaymentStatus   string     `json:"payment_status"`
+	Amount          float64    `json:"amount"`
+	CompletedAt     *time.Time `json:"completed_at"`
+	RefundReference string     `json:"refund_reference"`
+}
+
+type cbeBirrCallback struct {
+	TransactionRef string `json:"transaction_reference"`
+	PaymentStatus  string `json:"payment_status"`
+}
+
+// Name returns cbe_banking, the payment_method enum value CBE Birr payments are stored under
+func (p *CBEBirrProvider) Name() string {
+	return "cbe_banking"
+}
+
+func (p *CBEBirrProvider) Initiate(ctx context.Context, req *InitiateRequest) (*InitiateResult, error) {
+	body := map[string]any{
+		"merchant_id":  p.config.MerchantID,
+		"bill_ref":     req.IntentID,
+		"amount":       req.Amount,
+		"currency":     req.Currency,
+		"description":  req.Description,
+		"callback_url": req.CallbackURL,
+		"return_url":   req.ReturnURL,
+	}
+
+	var resp cbeBirrResponse
+	if err := p.client.postJSON(ctx, "/api/v1/payments", body, &resp); err != nil {
+		return nil, fmt.Errorf("cbe birr: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("cbe birr: payment request rejected: %s", resp.Message)
+	}
+	return &InitiateResult{
+		ProviderReference: resp.TransactionRef,
+		CheckoutURL:       resp.PaymentURL,
+	}, nil
+}
+
+func (p *CBEBirrProvider) Verify(ctx context.Context, providerReference string) (*VerifyResult, error) {
+	body := map[string]any{
+		"merchant_id":           p.config.MerchantID,
+		"transaction_reference": providerReference,
+	}
+
+	var resp cbeBirrResponse
+	if err := p.client.postJSON(ctx, "/api/v1/payments/status", body, &resp); err != nil {
+		return nil, fmt.Errorf("cbe birr: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("cbe birr: status query rejected: %s", resp.Message)
+	}
+	return &VerifyResult{
+		ProviderReference: providerReference,
+		Status:            cbeBirrStatus(resp.PaymentStatus),
+		Amount:            resp.Amount,
+		PaidAt:            resp.CompletedAt,
+	}, nil
+}
+
+func (p *CBEBirrProvider) Refund(ctx context.Context, providerReference string, amount float64) (*RefundResult, error) {
+	body := map[string]any{
+		"merchant_id":           p.config.MerchantID,
+		"transaction_reference": providerReference,
+		"amount":                amount,
+	}
+
+	var resp cbeBirrResponse
+	if err := p.client.postJSON(ctx, "/api/v1/payments/refund", body, &resp); err != nil {
+		return nil, fmt.Errorf("cbe birr: %w", err)
+	}
+	if resp.Status != "success" {
+		return nil, fmt.Errorf("cbe birr: refund rejected: %s", resp.Message)
+	}
+	return &RefundResult{
+		ProviderReference: providerReference,
+		RefundReference:   resp.RefundReference,
+		Status:            StatusRefunded,
+	}, nil
+}
+
+func (p *CBEBirrProvider) ParseCallback(body []byte, header http.Header) (*CallbackEvent, error) {
+	if !validSignature(p.config.APIKey, body, header.Get("X-CBE-Signature")) {
+		return nil, ErrInvalidSignature
+	}
+	var callback cbeBirrCallback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return nil, fmt.Errorf("cbe birr: invalid callback body: %w", err)
+	}
+	return &CallbackEvent{
+		ProviderReference: callback.TransactionRef,
+		Status:            cbeBirrStatus(callback.PaymentStatus),
+	}, nil
+}
+
+func cbeBirrStatus(status string) PaymentStatus {
+	switch status {
+	case "COMPLETED":
+		return StatusSucceeded
+	case "FAILED", "CANCELLED", "EXPIRED":
+		return StatusFailed
+	case "REFUNDED":
+		return StatusRefunded
+	default:
+		return StatusPending
+	}
+}
@@ -0,0 +1,60 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// signedClient posts JSON to a gateway and signs each body with HMAC-SHA256
+type signedClient struct {
+	baseURL         string
+	secret          string
+	signatureHeader string
+	httpClient      *http.Client
+}
+
+func newSignedClient(baseURL, secret, signatureHeader string) *signedClient {
+	return &signedClient{
+		baseURL:         baseURL,
+		secret:          secret,
+		signatureHeader: signatureHeader,
+		httpClient:      &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+func (c *signedClient) postJSON(ctx context.Context, path string, body any, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(c.signatureHeader, sign(c.secret, payload))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response from %s: %w", path, err)
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%s returned status %d: %s", path, resp.StatusCode, string(respBody))
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("failed to decode response from %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package payments
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FakeProvider is an in-memory provider for local development and testing.
+// Every payment it initiates succeeds as soon as it is verified, and callbacks
+// are accepted without a signature. It must never be enabled in production.
+type FakeProvider struct {
+	name        string
+	checkoutURL string
+
+	mu       sync.Mutex
+	payments map[string]*VerifyResult
+}
+
+// NewFakeProvider returns a fake standing in for the provider with the given payment_method name
+func NewFakeProvider(name, checkoutURL string) *FakeProvider {
+	return &FakeProvider{
+		name:        name,
+		checkoutURL: checkoutURL,
+		payments:    make(map[string]*VerifyResult),
+	}
+}
+
+type fakeCallback struct {
+	Reference string `json:"reference"`
+	Status    string `json:"status"`
+}
+
+func (p *FakeProvider) Name() string {
+	return p.name
+}
+
+func (p *FakeProvider) Initiate(ctx context.Context, req *InitiateRequest) (*InitiateResult, error) {
+	reference := "fake_" + randomReference()
+
+	p.mu.Lock()
+	p.payments[reference] = &VerifyResult{
+		ProviderReference: reference,
+		Status:            StatusPending,
+		Amount:            req.Amount,
+	}
+	p.mu.Unlock()
+
+	return &InitiateResult{
+		ProviderReference: reference,
+		CheckoutURL:       fmt.Sprintf("%s?reference=%s", p.checkoutURL, reference),
+	}, nil
+}
+
+func (p *FakeProvider) Verify(ctx context.Context, providerReference string) (*VerifyResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[providerReference]
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown payment %s", providerReference)
+	}
+	if payment.Status == StatusPending {
+		now := time.Now()
+		payment.Status = StatusSucceeded
+		payment.PaidAt = &now
+	}
+	result := *payment
+	return &result, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, providerReference string, amount float64) (*RefundResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[providerReference]
+	if !ok {
+		return nil, fmt.Errorf("fake: unknown payment %s", providerReference)
+	}
+	payment.Status = StatusRefunded
+	return &RefundResult{
+		ProviderReference: providerReference,
+		RefundReference:   "fake_refund_" + randomReference(),
+		Status:            StatusRefunded,
+	}, nil
+}
+
+func (p *FakeProvider) ParseCallback(body []byte, header http.Header) (*CallbackEvent, error) {
+	var callback fakeCallback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return nil, fmt.Errorf("fake: invalid callback body: %w", err)
+	}
+	status := StatusSucceeded
+	if callback.Status == string(StatusFailed) {
+		status = StatusFailed
+		// make the following Verify report the failure instead of auto-succeeding
+		p.mu.Lock()
+		if payment, ok := p.payments[callback.Reference]; ok && payment.Status == StatusPending {
+			payment.Status = StatusFailed
+		}
+		p.mu.Unlock()
+	}
+	return &CallbackEvent{
+		ProviderReference: callback.Reference,
+		Status:            status,
+	}, nil
+}
+
+func randomReference() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
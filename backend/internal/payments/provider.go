@@ -0,0 +1,83 @@
+package payments
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// PaymentStatus is the provider-agnostic state of a payment
+type PaymentStatus string
+
+const (
+	StatusPending   PaymentStatus = "pending"
+	StatusSucceeded PaymentStatus = "succeeded"
+	StatusFailed    PaymentStatus = "failed"
+	StatusRefunded  PaymentStatus = "refunded"
+)
+
+var (
+	ErrInvalidSignature = errors.New("payments: invalid callback signature")
+	ErrUnknownProvider  = errors.New("payments: unknown payment provider")
+)
+
+type InitiateRequest struct {
+	IntentID    string
+	OrderID     string
+	Amount      float64
+	Currency    string
+	Description string
+	CallbackURL string
+	ReturnURL   string
+}
+
+type InitiateResult struct {
+	ProviderReference string
+	CheckoutURL       string
+}
+
+type VerifyResult struct {
+	ProviderReference string
+	Status            PaymentStatus
+	Amount            float64
+	PaidAt            *time.Time
+}
+
+type RefundResult struct {
+	ProviderReference string
+	RefundReference   string
+	Status            PaymentStatus
+}
+
+// CallbackEvent is what a provider tells us asynchronously about a payment.
+// The status in a callback is only a hint; callers confirm it with Verify.
+type CallbackEvent struct {
+	ProviderReference string
+	Status            PaymentStatus
+}
+
+// PaymentProvider is implemented by every payment gateway we can take money through
+type PaymentProvider interface {
+	// Name returns the payment_method enum value the provider serves
+	Name() string
+	Initiate(ctx context.Context, req *InitiateRequest) (*InitiateResult, error)
+	Verify(ctx context.Context, providerReference string) (*VerifyResult, error)
+	Refund(ctx context.Context, providerReference string, amount float64) (*RefundResult, error)
+	// ParseCallback authenticates and decodes a callback request body sent by the provider
+	ParseCallback(body []byte, header http.Header) (*CallbackEvent, error)
+}
+
+// sign returns the hex encoded HMAC-SHA256 of payload using secret
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func validSignature(secret string, payload []byte, signature string) bool {
+	return hmac.Equal([]byte(sign(secret, payload)), []byte(signature))
+}
@@ -0,0 +1,22 @@
+package payments
+
+// Registry resolves the provider responsible for a payment_method
+type Registry struct {
+	providers map[string]PaymentProvider
+}
+
+func NewRegistry(providers ...PaymentProvider) *Registry {
+	registry := &Registry{providers: make(map[string]PaymentProvider)}
+	for _, provider := range providers {
+		registry.providers[provider.Name()] = provider
+	}
+	return registry
+}
+
+func (r *Registry) Provider(paymentMethod string) (PaymentProvider, error) {
+	provider, ok := r.providers[paymentMethod]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+	return provider, nil
+}
@@ -0,0 +1,157 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type TelebirrConfig struct {
+	BaseURL      string
+	AppID        string
+	AppKey       string
+	MerchantCode string
+}
+
+// TelebirrProvider takes payments through the Telebirr merchant web checkout
+type TelebirrProvider struct {
+	config TelebirrConfig
+	client *signedClient
+}
+
+func NewTelebirrProvider(config TelebirrConfig) *TelebirrProvider {
+	return &TelebirrProvider{
+		config: config,
+		client: newSignedClient(config.BaseURL, config.AppKey, "X-Telebirr-Signature"),
+	}
+}
+
+type telebirrResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"msg"`
+	Data    struct {
+		PrepayID    string `json:"prepay_id"`
+		ToPayURL    string `json:"toPayUrl"`
+		TradeStatus string `json:"trade_status"`
+		TotalAmount string `json:"total_amount"`
+		PaidAt      string `json:"trans_time"`
+		RefundID    string `json:"refund_request_no"`
+	} `json:"data"`
+}
+
+type telebirrCallback struct {
+	PrepayID    string `json:"prepay_id"`
+	OutTradeNo  string `json:"out_trade_no"`
+	TradeStatus string `json:"trade_status"`
+}
+
+func (p *TelebirrProvider) Name() string {
+	return "telebirr"
+}
+
+func (p *TelebirrProvider) Initiate(ctx context.Context, req *InitiateRequest) (*InitiateResult, error) {
+	body := map[string]string{
+		"appid":          p.config.AppID,
+		"merch_code":     p.config.MerchantCode,
+		"out_trade_no":   req.IntentID,
+		"title":          req.Description,
+		"total_amount":   strconv.FormatFloat(req.Amount, 'f', 2, 64),
+		"trans_currency": req.Currency,
+		"notify_url":     req.CallbackURL,
+		"redirect_url":   req.ReturnURL,
+		"timestamp":      strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	var resp telebirrResponse
+	if err := p.client.postJSON(ctx, "/payment/v1/merchant/preOrder", body, &resp); err != nil {
+		return nil, fmt.Errorf("telebirr: %w", err)
+	}
+	if resp.Code != "0" {
+		return nil, fmt.Errorf("telebirr: pre-order rejected: %s", resp.Message)
+	}
+	return &InitiateResult{
+		ProviderReference: resp.Data.PrepayID,
+		CheckoutURL:       resp.Data.ToPayURL,
+	}, nil
+}
+
+func (p *TelebirrProvider) Verify(ctx context.Context, providerReference string) (*VerifyResult, error) {
+	body := map[string]string{
+		"appid":      p.config.AppID,
+		"merch_code": p.config.MerchantCode,
+		"prepay_id":  providerReference,
+		"timestamp":  strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	var resp telebirrResponse
+	if err := p.client.postJSON(ctx, "/payment/v1/merchant/queryOrder", body, &resp); err != nil {
+		return nil, fmt.Errorf("telebirr: %w", err)
+	}
+	if resp.Code != "0" {
+		return nil, fmt.Errorf("telebirr: order query rejected: %s", resp.Message)
+	}
+
+	amount, _ := strconv.ParseFloat(resp.Data.TotalAmount, 64)
+	result := &VerifyResult{
+		ProviderReference: providerReference,
+		Status:            telebirrStatus(resp.Data.TradeStatus),
+		Amount:            amount,
+	}
+	if paidAt, err := time.Parse(time.RFC3339, resp.Data.PaidAt); err == nil {
+		result.PaidAt = &paidAt
+	}
+	return result, nil
+}
+
+func (p *TelebirrProvider) Refund(ctx context.Context, providerReference string, amount float64) (*RefundResult, error) {
+	body := map[string]string{
+		"appid":         p.config.AppID,
+		"merch_code":    p.config.MerchantCode,
+		"prepay_id":     providerReference,
+		"refund_amount": strconv.FormatFloat(amount, 'f', 2, 64),
+		"timestamp":     strconv.FormatInt(time.Now().Unix(), 10),
+	}
+
+	var resp telebirrResponse
+	if err := p.client.postJSON(ctx, "/payment/v1/merchant/refund", body, &resp); err != nil {
+		return nil, fmt.Errorf("telebirr: %w", err)
+	}
+	if resp.Code != "0" {
+		return nil, fmt.Errorf("telebirr: refund rejected: %s", resp.Message)
+	}
+	return &RefundResult{
+		ProviderReference: providerReference,
+		RefundReference:   resp.Data.RefundID,
+		Status:            StatusRefunded,
+	}, nil
+}
+
+func (p *TelebirrProvider) ParseCallback(body []byte, header http.Header) (*CallbackEvent, error) {
+	if !validSignature(p.config.AppKey, body, header.Get("X-Telebirr-Signature")) {
+		return nil, ErrInvalidSignature
+	}
+	var callback telebirrCallback
+	if err := json.Unmarshal(body, &callback); err != nil {
+		return nil, fmt.Errorf("telebirr: invalid callback body: %w", err)
+	}
+	return &CallbackEvent{
+		ProviderReference: callback.PrepayID,
+		Status:            telebirrStatus(callback.TradeStatus),
+	}, nil
+}
+
+func telebirrStatus(tradeStatus string) PaymentStatus {
+	switch tradeStatus {
+	case "Completed", "PAY_SUCCESS":
+		return StatusSucceeded
+	case "Failure", "PAY_FAILED", "ORDER_CLOSED":
+		return StatusFailed
+	case "REFUND_SUCCESS":
+		return StatusRefunded
+	default:
+		return StatusPending
+	}
+}
@@ -3,11 +3,15 @@ package repositories
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 
 	"github.com/amha-mersha/sanqa-suq/internal/database"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
 	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
 	"github.com/amha-mersha/sanqa-suq/internal/models"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type BuildRepository struct {
@@ -18,73 +22,50 @@ func NewBuildRepository(db *database.DB) *BuildRepository {
 	return &BuildRepository{DB: db}
 }
 
-func (r *BuildRepository) CreateBuild(ctx context.Context, build *models.CustomBuild, items []models.BuildItem) (*models.BuildWithItems, error) {
-	tx, err := r.DB.Pool.Begin(ctx)
-	if err != nil {
-		return nil, errs.InternalError("failed to begin transaction", err)
-	}
-	defer tx.Rollback(ctx)
-
-	// Insert the build
-	var buildID string
-	err = tx.QueryRow(ctx,
-		`INSERT INTO custom_builds (user_id, name) 
-		 VALUES ($1, $2) 
-		 RETURNING build_id`,
-		build.UserID, build.Name,
-	).Scan(&buildID)
-	if err != nil {
-		return nil, errs.InternalError("failed to create build", err)
-	}
-
-	// Insert build items
-	for _, item := range items {
-		_, err = tx.Exec(ctx,
-			`INSERT INTO build_items (build_id, product_id, quantity)
-			 VALUES ($1, $2, $3)`,
-			buildID, item.ProductID, item.Quantity,
-		)
-		if err != nil {
-			return nil, errs.InternalError("failed to create build item", err)
-		}
-	}
+// buildColumns are the custom_builds columns in the order scanBuild reads them
+const buildColumns = `b.build_id, b.user_id, b.name, b.created_at, b.total_price,
+	b.visibility::text, b.slug, b.clone_count, b.cloned_from, b.shared_at`
 
-	// Validate build compatibility using the database function
-	var isCompatible bool
-	var message string
-	err = tx.QueryRow(ctx,
-		`SELECT is_compatible, message FROM validate_build($1)`,
-		buildID,
-	).Scan(&isCompatible, &message)
-	if err != nil {
-		return nil, errs.InternalError("failed to validate build", err)
-	}
+// buildQuerier is satisfied by the pool and by a transaction, so builds can be read
+// inside the transaction that wrote them
+type buildQuerier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
 
-	if !isCompatible {
-		return nil, errs.UnprocessableEntity(message, nil)
-	}
+func scanBuild(row pgx.Row, build *models.CustomBuild) error {
+	return row.Scan(&build.BuildID, &build.UserID, &build.Name, &build.CreatedAt, &build.TotalPrice,
+		&build.Visibility, &build.Slug, &build.CloneCount, &build.ClonedFrom, &build.SharedAt)
+}
 
-	// Get the created build with items
-	var result models.BuildWithItems
-	err = tx.QueryRow(ctx,
-		`SELECT b.build_id, b.user_id, b.name, b.created_at, b.total_price
+// fetchBuild reads a build with its items and their product details
+func fetchBuild(ctx context.Context, q buildQuerier, buildID string) (*models.BuildWithItems, error) {
+	var build models.BuildWithItems
+	err := scanBuild(q.QueryRow(ctx,
+		`SELECT `+buildColumns+`
 		 FROM custom_builds b
 		 WHERE b.build_id = $1`,
 		buildID,
-	).Scan(&result.BuildID, &result.UserID, &result.Name, &result.CreatedAt, &result.TotalPrice)
+	), &build.CustomBuild)
 	if err != nil {
-		return nil, errs.InternalError("failed to fetch created build", err)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound("build not found", nil)
+		}
+		return nil, errs.InternalError("failed to fetch build", err)
 	}
 
 	// Get build items with product details
-	rows, err := tx.Query(ctx,
+	rows, err := q.Query(ctx,
 		`SELECT bi.product_id, bi.quantity, p.name, p.price, p.description,
-				b.name as brand_name, c.name as category_name
+				b.name as brand_name, c.category_name as category_name,
+				bi.saved_price, ep.final_price, p.stock_quantity
 		 FROM build_items bi
 		 JOIN products p ON bi.product_id = p.product_id
+		 JOIN product_effective_prices ep ON bi.product_id = ep.product_id
 		 JOIN brands b ON p.brand_id = b.brand_id
 		 JOIN categories c ON p.category_id = c.category_id
-		 WHERE bi.build_id = $1`,
+		 WHERE bi.build_id = $1
+		 ORDER BY bi.product_id`,
 		buildID,
 	)
 	if err != nil {
@@ -92,31 +73,154 @@ func (r *BuildRepository) CreateBuild(ctx context.Context, build *models.CustomB
 	}
 	defer rows.Close()
 
+	build.Items = []models.BuildItem{}
 	for rows.Next() {
 		var item models.BuildItem
 		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.ProductName, &item.Price,
-			&item.Description, &item.BrandName, &item.CategoryName); err != nil {
+			&item.Description, &item.BrandName, &item.CategoryName,
+			&item.SavedPrice, &item.CurrentPrice, &item.StockQuantity); err != nil {
 			return nil, errs.InternalError("failed to scan build item", err)
 		}
-		buildID := buildID // Create a new variable to avoid closure issues
-		item.BuildID = buildID
-		result.Items = append(result.Items, item)
+		item.BuildID = build.BuildID
+		build.Items = append(build.Items, item)
 	}
 
 	if err = rows.Err(); err != nil {
 		return nil, errs.InternalError("error iterating build items", err)
 	}
 
+	return &build, nil
+}
+
+// insertBuildItems adds items to a build at their current price; an unknown product is
+// not found
+func insertBuildItems(ctx context.Context, tx pgx.Tx, buildID string, items []models.BuildItem) error {
+	for _, item := range items {
+		_, err := tx.Exec(ctx,
+			`INSERT INTO build_items (build_id, product_id, quantity, saved_price)
+			 VALUES ($1, $2, $3, COALESCE((SELECT final_price FROM product_effective_prices WHERE product_id = $2), 0))`,
+			buildID, item.ProductID, item.Quantity,
+		)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return errs.NotFound(fmt.Sprintf("product with id %d not found", item.ProductID), err)
+			}
+			return errs.InternalError("failed to create build item", err)
+		}
+	}
+	return nil
+}
+
+// replaceBuildItems swaps all items of a locked build for the given ones
+func replaceBuildItems(ctx context.Context, tx pgx.Tx, buildID string, items []models.BuildItem) error {
+	_, err := tx.Exec(ctx, `DELETE FROM build_items WHERE build_id = $1`, buildID)
+	if err != nil {
+		return errs.InternalError("failed to delete existing build items", err)
+	}
+	return insertBuildItems(ctx, tx, buildID, items)
+}
+
+// recordBuildRevision stores the current state of a build as its next revision, and its
+// total in the price history when that changed. The build must be locked or newly created
+// so that revision numbers do not clash.
+func recordBuildRevision(ctx context.Context, tx pgx.Tx, buildID string, change string, restoredFrom *int) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO build_revisions (build_id, revision, name, items, total_price, change, restored_from)
+		 SELECT b.build_id,
+				COALESCE((SELECT MAX(r.revision) FROM build_revisions r WHERE r.build_id = b.build_id), 0) + 1,
+				b.name,
+				COALESCE((
+					SELECT jsonb_agg(jsonb_build_object('product_id', bi.product_id, 'quantity', bi.quantity) ORDER BY bi.product_id)
+					FROM build_items bi
+					WHERE bi.build_id = b.build_id
+				), '[]'::jsonb),
+				b.total_price, $2, $3
+		 FROM custom_builds b
+		 WHERE b.build_id = $1`,
+		buildID, change, restoredFrom,
+	)
+	if err != nil {
+		return errs.InternalError("failed to record build revision", err)
+	}
+	return recordBuildPrice(ctx, tx, buildID)
+}
+
+// recordBuildPrice adds the total of a build to its price history if it differs from the
+// last one recorded. A price alert follows the new total without notifying, since the owner
+// made the change.
+func recordBuildPrice(ctx context.Context, tx pgx.Tx, buildID string) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO build_price_history (build_id, total_price, reason)
+		 SELECT b.build_id, b.total_price, $2
+		 FROM custom_builds b
+		 WHERE b.build_id = $1
+		   AND b.total_price IS DISTINCT FROM (
+				SELECT h.total_price FROM build_price_history h
+				WHERE h.build_id = b.build_id
+				ORDER BY h.recorded_at DESC, h.history_id DESC
+				LIMIT 1
+		   )`,
+		buildID, models.BuildPriceEdited,
+	)
+	if err != nil {
+		return errs.InternalError("failed to record build price", err)
+	}
+	_, err = tx.Exec(ctx,
+		`UPDATE build_price_alerts a
+		 SET is_below = b.total_price < a.threshold
+		 FROM custom_builds b
+		 WHERE a.build_id = $1 AND b.build_id = a.build_id`,
+		buildID,
+	)
+	if err != nil {
+		return errs.InternalError("failed to update build price alert", err)
+	}
+	return nil
+}
+
+func (r *BuildRepository) CreateBuild(ctx context.Context, build *models.CustomBuild, items []models.BuildItem) (*models.BuildWithItems, error) {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Insert the build
+	var buildID string
+	err = tx.QueryRow(ctx,
+		`INSERT INTO custom_builds (user_id, name, cloned_from) 
+		 VALUES ($1, $2, $3) 
+		 RETURNING build_id`,
+		build.UserID, build.Name, build.ClonedFrom,
+	).Scan(&buildID)
+	if err != nil {
+		return nil, errs.InternalError("failed to create build", err)
+	}
+
+	if err = insertBuildItems(ctx, tx, buildID, items); err != nil {
+		return nil, err
+	}
+	if err = recordBuildRevision(ctx, tx, buildID, models.BuildChangeCreated, nil); err != nil {
+		return nil, err
+	}
+
+	// Get the created build with items
+	result, err := fetchBuild(ctx, tx, buildID)
+	if err != nil {
+		return nil, err
+	}
+
 	if err = tx.Commit(ctx); err != nil {
 		return nil, errs.InternalError("failed to commit transaction", err)
 	}
 
-	return &result, nil
+	return result, nil
 }
 
 func (r *BuildRepository) GetUserBuilds(ctx context.Context, userID string) ([]models.BuildWithItems, error) {
 	rows, err := r.DB.Pool.Query(ctx,
-		`SELECT b.build_id, b.user_id, b.name, b.created_at, b.total_price
+		`SELECT b.build_id
 		 FROM custom_builds b
 		 WHERE b.user_id = $1
 		 ORDER BY b.created_at DESC`,
@@ -125,129 +229,80 @@ func (r *BuildRepository) GetUserBuilds(ctx context.Context, userID string) ([]m
 	if err != nil {
 		return nil, errs.InternalError("failed to fetch user builds", err)
 	}
-	defer rows.Close()
-
-	var builds []models.BuildWithItems
-	for rows.Next() {
-		var build models.BuildWithItems
-		if err := rows.Scan(&build.BuildID, &build.UserID, &build.Name, &build.CreatedAt, &build.TotalPrice); err != nil {
-			return nil, errs.InternalError("failed to scan build", err)
-		}
+	buildIDs, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect user builds", err)
+	}
 
-		// Get build items with product details
-		itemRows, err := r.DB.Pool.Query(ctx,
-			`SELECT bi.product_id, bi.quantity, p.name, p.price, p.description,
-					b.name as brand_name, c.name as category_name
-			 FROM build_items bi
-			 JOIN products p ON bi.product_id = p.product_id
-			 JOIN brands b ON p.brand_id = b.brand_id
-			 JOIN categories c ON p.category_id = c.category_id
-			 WHERE bi.build_id = $1`,
-			build.BuildID,
-		)
+	builds := make([]models.BuildWithItems, 0, len(buildIDs))
+	for _, buildID := range buildIDs {
+		build, err := fetchBuild(ctx, r.DB.Pool, buildID)
 		if err != nil {
-			return nil, errs.InternalError("failed to fetch build items", err)
-		}
-		defer itemRows.Close()
-
-		for itemRows.Next() {
-			var item models.BuildItem
-			if err := itemRows.Scan(&item.ProductID, &item.Quantity, &item.ProductName, &item.Price,
-				&item.Description, &item.BrandName, &item.CategoryName); err != nil {
-				return nil, errs.InternalError("failed to scan build item", err)
-			}
-			item.BuildID = build.BuildID
-			build.Items = append(build.Items, item)
-		}
-
-		if err = itemRows.Err(); err != nil {
-			return nil, errs.InternalError("error iterating build items", err)
+			return nil, err
 		}
-
-		builds = append(builds, build)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, errs.InternalError("error iterating builds", err)
+		builds = append(builds, *build)
 	}
 
 	return builds, nil
 }
 
 func (r *BuildRepository) GetBuildByID(ctx context.Context, buildID string) (*models.BuildWithItems, error) {
-	var build models.BuildWithItems
-	err := r.DB.Pool.QueryRow(ctx,
-		`SELECT b.build_id, b.user_id, b.name, b.created_at, b.total_price
-		 FROM custom_builds b
-		 WHERE b.build_id = $1`,
-		buildID,
-	).Scan(&build.BuildID, &build.UserID, &build.Name, &build.CreatedAt, &build.TotalPrice)
+	return fetchBuild(ctx, r.DB.Pool, buildID)
+}
+
+// GetBuildBySlug finds a build by the slug of its share link
+func (r *BuildRepository) GetBuildBySlug(ctx context.Context, slug string) (*models.BuildWithItems, error) {
+	var buildID string
+	err := r.DB.Pool.QueryRow(ctx, `SELECT build_id FROM custom_builds WHERE slug = $1`, slug).Scan(&buildID)
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.NotFound("build not found", nil)
 		}
 		return nil, errs.InternalError("failed to fetch build", err)
 	}
-
-	// Get build items with product details
-	rows, err := r.DB.Pool.Query(ctx,
-		`SELECT bi.product_id, bi.quantity, p.name, p.price, p.description,
-				b.name as brand_name, c.name as category_name
-		 FROM build_items bi
-		 JOIN products p ON bi.product_id = p.product_id
-		 JOIN brands b ON p.brand_id = b.brand_id
-		 JOIN categories c ON p.category_id = c.category_id
-		 WHERE bi.build_id = $1`,
-		buildID,
-	)
-	if err != nil {
-		return nil, errs.InternalError("failed to fetch build items", err)
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var item models.BuildItem
-		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.ProductName, &item.Price,
-			&item.Description, &item.BrandName, &item.CategoryName); err != nil {
-			return nil, errs.InternalError("failed to scan build item", err)
-		}
-		item.BuildID = buildID
-		build.Items = append(build.Items, item)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, errs.InternalError("error iterating build items", err)
-	}
-
-	return &build, nil
+	return fetchBuild(ctx, r.DB.Pool, buildID)
 }
 
-func (r *BuildRepository) UpdateBuild(ctx context.Context, buildID string, userID string, name *string, items []models.BuildItem) (*models.BuildWithItems, error) {
-	// First verify the build belongs to the user
-	var existingBuild models.CustomBuild
-	err := r.DB.Pool.QueryRow(ctx,
-		`SELECT build_id, user_id, name 
-		 FROM custom_builds 
-		 WHERE build_id = $1`,
+// lockOwnBuild locks a build for changes by its owner; other users get not found for a
+// private build, as when reading it
+func lockOwnBuild(ctx context.Context, tx pgx.Tx, buildID string, userID string) (*models.CustomBuild, error) {
+	var build models.CustomBuild
+	err := scanBuild(tx.QueryRow(ctx,
+		`SELECT `+buildColumns+`
+		 FROM custom_builds b
+		 WHERE b.build_id = $1
+		 FOR UPDATE`,
 		buildID,
-	).Scan(&existingBuild.BuildID, &existingBuild.UserID, &existingBuild.Name)
+	), &build)
 	if err != nil {
-		if err == pgx.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.NotFound("build not found", nil)
 		}
 		return nil, errs.InternalError("failed to fetch build", err)
 	}
-
-	if existingBuild.UserID != userID {
-		return nil, errs.Forbidden("you can only update your own builds", nil)
+	if build.UserID != userID {
+		if build.Visibility == models.BuildVisibilityPrivate {
+			return nil, errs.NotFound("build not found", nil)
+		}
+		return nil, errs.Forbidden("you can only change your own builds", nil)
 	}
+	return &build, nil
+}
 
+// UpdateBuild renames a build and/or replaces its items, recording the result as a
+// revision with the given change; restoredFrom names the revision a restore came from
+func (r *BuildRepository) UpdateBuild(ctx context.Context, buildID string, userID string, name *string, items []models.BuildItem, change string, restoredFrom *int) (*models.BuildWithItems, error) {
 	tx, err := r.DB.Pool.Begin(ctx)
 	if err != nil {
 		return nil, errs.InternalError("failed to begin transaction", err)
 	}
 	defer tx.Rollback(ctx)
 
+	// First verify the build belongs to the user
+	if _, err = lockOwnBuild(ctx, tx, buildID, userID); err != nil {
+		return nil, err
+	}
+
 	// Update build name if provided
 	if name != nil {
 		_, err = tx.Exec(ctx,
@@ -263,131 +318,316 @@ func (r *BuildRepository) UpdateBuild(ctx context.Context, buildID string, userI
 
 	// Update items if provided
 	if len(items) > 0 {
-		// Delete existing items
-		_, err = tx.Exec(ctx,
-			`DELETE FROM build_items 
-			 WHERE build_id = $1`,
-			buildID,
-		)
-		if err != nil {
-			return nil, errs.InternalError("failed to delete existing build items", err)
+		if err = replaceBuildItems(ctx, tx, buildID, items); err != nil {
+			return nil, err
 		}
+	}
+	if err = recordBuildRevision(ctx, tx, buildID, change, restoredFrom); err != nil {
+		return nil, err
+	}
 
-		// Insert new items
-		for _, item := range items {
-			_, err = tx.Exec(ctx,
-				`INSERT INTO build_items (build_id, product_id, quantity)
-				 VALUES ($1, $2, $3)`,
-				buildID, item.ProductID, item.Quantity,
-			)
-			if err != nil {
-				return nil, errs.InternalError("failed to insert build item", err)
-			}
-		}
+	// Get the updated build with items
+	result, err := fetchBuild(ctx, tx, buildID)
+	if err != nil {
+		return nil, err
+	}
 
-		// Validate build compatibility
-		var isCompatible bool
-		var message string
-		err = tx.QueryRow(ctx,
-			`SELECT is_compatible, message FROM validate_build($1)`,
-			buildID,
-		).Scan(&isCompatible, &message)
-		if err != nil {
-			return nil, errs.InternalError("failed to validate build", err)
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errs.InternalError("failed to commit transaction", err)
+	}
+
+	return result, nil
+}
+
+// EditBuildItems replaces the items of a build of the user with what edit makes of the
+// current ones, recording the result as a revision with the given change. The build stays
+// locked from reading the items to saving the edit, so concurrent edits apply in turn
+// instead of overwriting each other; an error from edit leaves the build unchanged.
+func (r *BuildRepository) EditBuildItems(ctx context.Context, buildID string, userID string, change string, edit func(items []models.BuildItem) ([]models.BuildItem, error)) (*models.BuildWithItems, error) {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = lockOwnBuild(ctx, tx, buildID, userID); err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx,
+		`SELECT product_id, quantity FROM build_items WHERE build_id = $1 ORDER BY product_id`,
+		buildID,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch build items", err)
+	}
+	current := []models.BuildItem{}
+	var item models.BuildItem
+	_, err = pgx.ForEachRow(rows, []any{&item.ProductID, &item.Quantity}, func() error {
+		current = append(current, models.BuildItem{BuildID: buildID, ProductID: item.ProductID, Quantity: item.Quantity})
+		return nil
+	})
+	if err != nil {
+		return nil, errs.InternalError("failed to collect build items", err)
+	}
+
+	items, err := edit(current)
+	if err != nil {
+		return nil, err
+	}
+	if err = replaceBuildItems(ctx, tx, buildID, items); err != nil {
+		return nil, err
+	}
+	if err = recordBuildRevision(ctx, tx, buildID, change, nil); err != nil {
+		return nil, err
+	}
+
+	result, err := fetchBuild(ctx, tx, buildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errs.InternalError("failed to commit transaction", err)
+	}
+	return result, nil
+}
+
+// DeleteBuild removes a build of the user along with its items and revisions
+func (r *BuildRepository) DeleteBuild(ctx context.Context, buildID string, userID string) error {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = lockOwnBuild(ctx, tx, buildID, userID); err != nil {
+		return err
+	}
+	if _, err = tx.Exec(ctx, `DELETE FROM custom_builds WHERE build_id = $1`, buildID); err != nil {
+		return errs.InternalError("failed to delete build", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errs.InternalError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+const buildRevisionColumns = `build_id, revision, name, items, total_price::float8, change, restored_from, created_at`
+
+// GetBuildRevisions lists the revisions of a build, newest first
+func (r *BuildRepository) GetBuildRevisions(ctx context.Context, buildID string) ([]*models.BuildRevision, error) {
+	rows, err := r.DB.Pool.Query(ctx,
+		`SELECT `+buildRevisionColumns+`
+		 FROM build_revisions
+		 WHERE build_id = $1
+		 ORDER BY revision DESC`,
+		buildID,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch build revisions", err)
+	}
+	revisions, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.BuildRevision])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect build revisions", err)
+	}
+	return revisions, nil
+}
+
+func (r *BuildRepository) GetBuildRevision(ctx context.Context, buildID string, revision int) (*models.BuildRevision, error) {
+	rows, err := r.DB.Pool.Query(ctx,
+		`SELECT `+buildRevisionColumns+`
+		 FROM build_revisions
+		 WHERE build_id = $1 AND revision = $2`,
+		buildID, revision,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch build revision", err)
+	}
+	result, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByPos[models.BuildRevision])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("build has no revision %d", revision), nil)
 		}
+		return nil, errs.InternalError("failed to collect build revision", err)
+	}
+	return result, nil
+}
+
+// SetBuildVisibility changes who can see a build. The first time it is shared the build
+// gets the slug passed in, which it keeps so that links stay valid; a slug taken by
+// another build is a conflict.
+func (r *BuildRepository) SetBuildVisibility(ctx context.Context, buildID string, userID string, visibility string, slug string) (*models.BuildWithItems, error) {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = lockOwnBuild(ctx, tx, buildID, userID); err != nil {
+		return nil, err
+	}
 
-		if !isCompatible {
-			return nil, errs.UnprocessableEntity(message, nil)
+	_, err = tx.Exec(ctx,
+		`UPDATE custom_builds
+		 SET visibility = $2::build_visibility,
+			 slug = CASE WHEN $2 = 'private' THEN slug ELSE COALESCE(slug, $3) END,
+			 shared_at = CASE
+				 WHEN $2 <> 'public' THEN NULL
+				 WHEN visibility = 'public' THEN shared_at
+				 ELSE CURRENT_TIMESTAMP
+			 END
+		 WHERE build_id = $1`,
+		buildID, visibility, slug,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.Conflict("build slug is already taken", err)
 		}
+		return nil, errs.InternalError("failed to update build visibility", err)
 	}
 
-	// Get the updated build with items
-	var result models.BuildWithItems
+	result, err := fetchBuild(ctx, tx, buildID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errs.InternalError("failed to commit transaction", err)
+	}
+
+	return result, nil
+}
+
+// CloneBuild copies the items of a build into a new private build of the user and counts
+// the clone on the source build, unless the user owns it
+func (r *BuildRepository) CloneBuild(ctx context.Context, sourceID string, userID string, name string) (*models.BuildWithItems, error) {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var buildID string
 	err = tx.QueryRow(ctx,
-		`SELECT b.build_id, b.user_id, b.name, b.created_at, b.total_price
-		 FROM custom_builds b
-		 WHERE b.build_id = $1`,
-		buildID,
-	).Scan(&result.BuildID, &result.UserID, &result.Name, &result.CreatedAt, &result.TotalPrice)
+		`INSERT INTO custom_builds (user_id, name, cloned_from)
+		 VALUES ($1, $2, $3)
+		 RETURNING build_id`,
+		userID, name, sourceID,
+	).Scan(&buildID)
 	if err != nil {
-		return nil, errs.InternalError("failed to fetch updated build", err)
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return nil, errs.NotFound("build not found", err)
+		}
+		return nil, errs.InternalError("failed to clone build", err)
 	}
 
-	// Get build items with product details
-	rows, err := tx.Query(ctx,
-		`SELECT bi.product_id, bi.quantity, p.name, p.price, p.description,
-				b.name as brand_name, c.name as category_name
+	_, err = tx.Exec(ctx,
+		`INSERT INTO build_items (build_id, product_id, quantity, saved_price)
+		 SELECT $1, bi.product_id, bi.quantity, ep.final_price
 		 FROM build_items bi
-		 JOIN products p ON bi.product_id = p.product_id
-		 JOIN brands b ON p.brand_id = b.brand_id
-		 JOIN categories c ON p.category_id = c.category_id
-		 WHERE bi.build_id = $1`,
-		buildID,
+		 JOIN product_effective_prices ep ON ep.product_id = bi.product_id
+		 WHERE bi.build_id = $2`,
+		buildID, sourceID,
 	)
 	if err != nil {
-		return nil, errs.InternalError("failed to fetch build items", err)
+		return nil, errs.InternalError("failed to copy build items", err)
 	}
-	defer rows.Close()
 
-	for rows.Next() {
-		var item models.BuildItem
-		if err := rows.Scan(&item.ProductID, &item.Quantity, &item.ProductName, &item.Price,
-			&item.Description, &item.BrandName, &item.CategoryName); err != nil {
-			return nil, errs.InternalError("failed to scan build item", err)
-		}
-		item.BuildID = buildID
-		result.Items = append(result.Items, item)
+	_, err = tx.Exec(ctx,
+		`UPDATE custom_builds SET clone_count = clone_count + 1 WHERE build_id = $1 AND user_id <> $2`,
+		sourceID, userID,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to count build clone", err)
+	}
+	if err = recordBuildRevision(ctx, tx, buildID, models.BuildChangeCloned, nil); err != nil {
+		return nil, err
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, errs.InternalError("error iterating build items", err)
+	result, err := fetchBuild(ctx, tx, buildID)
+	if err != nil {
+		return nil, err
 	}
 
 	if err = tx.Commit(ctx); err != nil {
 		return nil, errs.InternalError("failed to commit transaction", err)
 	}
 
-	return &result, nil
+	return result, nil
+}
+
+// GetPublicBuilds lists the public builds with the number of items in each
+func (r *BuildRepository) GetPublicBuilds(ctx context.Context, filter *dtos.BuildGalleryDTO) (*models.BuildGallery, error) {
+	orderBy := map[string]string{
+		"recent":     "b.shared_at DESC",
+		"popular":    "b.clone_count DESC, b.shared_at DESC",
+		"price_asc":  "b.total_price ASC, b.shared_at DESC",
+		"price_desc": "b.total_price DESC, b.shared_at DESC",
+	}[filter.Sort]
+
+	rows, err := r.DB.Pool.Query(ctx,
+		`SELECT `+buildColumns+`,
+			(SELECT COALESCE(SUM(bi.quantity), 0) FROM build_items bi WHERE bi.build_id = b.build_id),
+			COUNT(*) OVER ()
+		 FROM custom_builds b
+		 WHERE b.visibility = 'public'
+		 ORDER BY `+orderBy+`, b.build_id
+		 LIMIT $1 OFFSET $2`,
+		filter.Limit, filter.Offset,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch public builds", err)
+	}
+	defer rows.Close()
+
+	gallery := &models.BuildGallery{Builds: []models.BuildSummary{}, Limit: filter.Limit, Offset: filter.Offset}
+	for rows.Next() {
+		var summary models.BuildSummary
+		err := rows.Scan(&summary.BuildID, &summary.UserID, &summary.Name, &summary.CreatedAt, &summary.TotalPrice,
+			&summary.Visibility, &summary.Slug, &summary.CloneCount, &summary.ClonedFrom, &summary.SharedAt,
+			&summary.ItemCount, &gallery.Total)
+		if err != nil {
+			return nil, errs.InternalError("failed to scan public build", err)
+		}
+		gallery.Builds = append(gallery.Builds, summary)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errs.InternalError("error iterating public builds", err)
+	}
+
+	return gallery, nil
 }
 
-func (r *BuildRepository) GetCompatibleProducts(ctx context.Context, categoryID int, selectedItems []int) ([]models.CompatibleProduct, error) {
+// GetCompatibleProducts lists the candidate products of a category with their specs,
+// cheapest first; the build service drops the ones breaking a compatibility rule
+// GetCompatibleProducts lists the products of a category cheapest first, at most limit of
+// them when limit is positive
+func (r *BuildRepository) GetCompatibleProducts(ctx context.Context, categoryID int, limit int) ([]models.CompatibleProduct, error) {
 	query := `
-		WITH selected_specs AS (
-			SELECT DISTINCT ps.spec_name, ps.spec_value
-			FROM product_specifications ps
-			WHERE ps.product_id = ANY($1)
-		),
-		compatible_products AS (
-			SELECT p.product_id, p.name, p.price, p.description,
-				   b.name as brand_name, c.name as category_name
+		WITH compatible_products AS (
+			SELECT p.product_id, p.name, p.price, ep.discount_id, ep.discount_percentage,
+				   ep.discount_amount, ep.final_price, p.description,
+				   b.name as brand_name, c.category_name as category_name, p.stock_quantity
 			FROM products p
+			JOIN product_effective_prices ep ON p.product_id = ep.product_id
 			JOIN brands b ON p.brand_id = b.brand_id
 			JOIN categories c ON p.category_id = c.category_id
-			WHERE p.category_id = $2
-			AND NOT EXISTS (
-				-- Check for any incompatible rules
-				SELECT 1
-				FROM compatibility_rules cr
-				JOIN product_specifications ps ON cr.product_id = ps.product_id AND cr.spec_id = ps.spec_name
-				WHERE cr.product_id = p.product_id
-				AND cr.spec_value != 'ANY'
-				AND NOT EXISTS (
-					-- Verify selected components meet the rule
-					SELECT 1
-					FROM selected_specs ss
-					WHERE ss.spec_name = ps.spec_name
-					AND ss.spec_value = cr.spec_value
-				)
-			)
+			WHERE p.category_id = $1
 		)
 		SELECT cp.*, 
-			   jsonb_object_agg(ps.spec_name, ps.spec_value) as specs
+			   COALESCE(jsonb_object_agg(ps.spec_name, ps.spec_value) FILTER (WHERE ps.spec_name IS NOT NULL), '{}') as specs
 		FROM compatible_products cp
 		LEFT JOIN product_specifications ps ON cp.product_id = ps.product_id
-		GROUP BY cp.product_id, cp.name, cp.price, cp.description, cp.brand_name, cp.category_name
-		ORDER BY cp.price ASC`
+		GROUP BY cp.product_id, cp.name, cp.price, cp.discount_id, cp.discount_percentage,
+			cp.discount_amount, cp.final_price, cp.description, cp.brand_name, cp.category_name, cp.stock_quantity
+		ORDER BY cp.final_price ASC, cp.product_id
+		LIMIT NULLIF($2, 0)`
 
-	rows, err := r.DB.Pool.Query(ctx, query, selectedItems, categoryID)
+	rows, err := r.DB.Pool.Query(ctx, query, categoryID, max(limit, 0))
 	if err != nil {
 		return nil, errs.InternalError("failed to fetch compatible products", err)
 	}
@@ -401,14 +641,21 @@ func (r *BuildRepository) GetCompatibleProducts(ctx context.Context, categoryID
 			&product.ProductID,
 			&product.ProductName,
 			&product.Price,
+			&product.Pricing.DiscountID,
+			&product.Pricing.DiscountPercentage,
+			&product.Pricing.DiscountAmount,
+			&product.Pricing.FinalPrice,
 			&product.Description,
 			&product.BrandName,
 			&product.CategoryName,
+			&product.StockQuantity,
 			&specsJSON,
 		); err != nil {
 			return nil, errs.InternalError("failed to scan compatible product", err)
 		}
 
+		product.Pricing.OriginalPrice = product.Price
+
 		// Parse specs JSON into map
 		if err := json.Unmarshal(specsJSON, &product.Specs); err != nil {
 			return nil, errs.InternalError("failed to parse product specs", err)
@@ -423,3 +670,139 @@ func (r *BuildRepository) GetCompatibleProducts(ctx context.Context, categoryID
 
 	return products, nil
 }
+
+// GetProductPrices returns the current selling price of each product found, keyed by ID
+func (r *BuildRepository) GetProductPrices(ctx context.Context, productIDs []int) (map[int]float64, error) {
+	rows, err := r.DB.Pool.Query(ctx,
+		`SELECT product_id, final_price::float8 FROM product_effective_prices WHERE product_id = ANY($1)`,
+		productIDs,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch product prices", err)
+	}
+	defer rows.Close()
+
+	prices := make(map[int]float64, len(productIDs))
+	var productID int
+	var price float64
+	_, err = pgx.ForEachRow(rows, []any{&productID, &price}, func() error {
+		prices[productID] = price
+		return nil
+	})
+	if err != nil {
+		return nil, errs.InternalError("failed to collect product prices", err)
+	}
+	return prices, nil
+}
+
+// GetBuildPriceHistory lists the totals a build has had, oldest first
+func (r *BuildRepository) GetBuildPriceHistory(ctx context.Context, buildID string) ([]*models.BuildPricePoint, error) {
+	rows, err := r.DB.Pool.Query(ctx,
+		`SELECT total_price::float8, reason, recorded_at
+		 FROM build_price_history
+		 WHERE build_id = $1
+		 ORDER BY recorded_at, history_id`,
+		buildID,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch build price history", err)
+	}
+	history, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.BuildPricePoint])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect build price history", err)
+	}
+	return history, nil
+}
+
+const buildPriceAlertColumns = `build_id, threshold::float8, is_below, created_at, notified_at`
+
+// GetBuildPriceAlert returns the price alert of a build, or nil if it has none
+func (r *BuildRepository) GetBuildPriceAlert(ctx context.Context, buildID string) (*models.BuildPriceAlert, error) {
+	rows, err := r.DB.Pool.Query(ctx,
+		`SELECT `+buildPriceAlertColumns+` FROM build_price_alerts WHERE build_id = $1`,
+		buildID,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch build price alert", err)
+	}
+	alert, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByPos[models.BuildPriceAlert])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errs.InternalError("failed to collect build price alert", err)
+	}
+	return alert, nil
+}
+
+// SetBuildPriceAlert sets the threshold of the price alert of a build of the user. A total
+// already below it counts as notified, so the alert only fires on a later drop.
+func (r *BuildRepository) SetBuildPriceAlert(ctx context.Context, buildID string, userID string, threshold float64) (*models.BuildPriceAlert, error) {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	build, err := lockOwnBuild(ctx, tx, buildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := tx.Query(ctx,
+		`INSERT INTO build_price_alerts (build_id, threshold, is_below)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (build_id) DO UPDATE SET threshold = EXCLUDED.threshold, is_below = EXCLUDED.is_below
+		 RETURNING `+buildPriceAlertColumns,
+		buildID, threshold, build.TotalPrice < threshold,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to set build price alert", err)
+	}
+	alert, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByPos[models.BuildPriceAlert])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect build price alert", err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errs.InternalError("failed to commit transaction", err)
+	}
+	return alert, nil
+}
+
+func (r *BuildRepository) DeleteBuildPriceAlert(ctx context.Context, buildID string, userID string) error {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = lockOwnBuild(ctx, tx, buildID, userID); err != nil {
+		return err
+	}
+	tag, err := tx.Exec(ctx, `DELETE FROM build_price_alerts WHERE build_id = $1`, buildID)
+	if err != nil {
+		return errs.InternalError("failed to delete build price alert", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return errs.NotFound("build has no price alert", nil)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errs.InternalError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+// RefreshBuildTotals recomputes the totals of all builds at current prices and returns how
+// many changed. Price and discount edits refresh the builds they affect as they happen;
+// this catches discounts that start or end on their own.
+func (r *BuildRepository) RefreshBuildTotals(ctx context.Context) (int, error) {
+	var refreshed int
+	err := r.DB.Pool.QueryRow(ctx,
+		`SELECT refresh_build_totals(ARRAY(SELECT build_id FROM custom_builds))`,
+	).Scan(&refreshed)
+	if err != nil {
+		return 0, errs.InternalError("failed to refresh build totals", err)
+	}
+	return refreshed, nil
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type CartRepository struct {
+	DB *database.DB
+}
+
+func NewCartRepository(db *database.DB) *CartRepository {
+	return &CartRepository{DB: db}
+}
+
+// GetCartItems returns the user's cart lines priced from the user_cart_summary view at their
+// effective price, together with the list price, the applied discount and the current stock.
+func (r *CartRepository) GetCartItems(ctx context.Context, userID string) ([]models.CartItem, error) {
+	query := `
+		SELECT s.user_id, s.product_id, s.name, ep.original_price, ep.discount_percentage,
+		       s.price, s.quantity, s.total, p.stock_quantity
+		FROM user_cart_summary s
+		JOIN products p ON s.product_id = p.product_id
+		JOIN product_effective_prices ep ON s.product_id = ep.product_id
+		WHERE s.user_id = $1
+		ORDER BY s.name`
+
+	rows, err := r.DB.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch cart items", err)
+	}
+	defer rows.Close()
+
+	var items []models.CartItem
+	for rows.Next() {
+		var item models.CartItem
+		if err := rows.Scan(
+			&item.UserID,
+			&item.ProductID,
+			&item.ProductName,
+			&item.OriginalPrice,
+			&item.DiscountPercentage,
+			&item.Price,
+			&item.Quantity,
+			&item.LineTotal,
+			&item.StockQuantity,
+		); err != nil {
+			return nil, errs.InternalError("failed to scan cart item", err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, errs.InternalError("error iterating cart items", err)
+	}
+
+	return items, nil
+}
+
+// AddCartItem inserts a cart line or increases the quantity of an existing one.
+func (r *CartRepository) AddCartItem(ctx context.Context, userID string, productID, quantity int) error {
+	query := `
+		INSERT INTO cart (user_id, product_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, product_id)
+		DO UPDATE SET quantity = cart.quantity + EXCLUDED.quantity`
+
+	if _, err := r.DB.Pool.Exec(ctx, query, userID, productID, quantity); err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return errs.NotFound(fmt.Sprintf("product with id %d not found", productID), err)
+		}
+		return errs.InternalError("failed to add cart item", err)
+	}
+	return nil
+}
+
+func (r *CartRepository) UpdateCartItemQuantity(ctx context.Context, userID string, productID, quantity int) error {
+	query := `UPDATE cart SET quantity = $1 WHERE user_id = $2 AND product_id = $3`
+
+	result, err := r.DB.Pool.Exec(ctx, query, quantity, userID, productID)
+	if err != nil {
+		return errs.InternalError("failed to update cart item", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.NotFound(fmt.Sprintf("product with id %d is not in the cart", productID), nil)
+	}
+	return nil
+}
+
+func (r *CartRepository) RemoveCartItem(ctx context.Context, userID string, productID int) error {
+	query := `DELETE FROM cart WHERE user_id = $1 AND product_id = $2`
+
+	result, err := r.DB.Pool.Exec(ctx, query, userID, productID)
+	if err != nil {
+		return errs.InternalError("failed to remove cart item", err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.NotFound(fmt.Sprintf("product with id %d is not in the cart", productID), nil)
+	}
+	return nil
+}
+
+func (r *CartRepository) ClearCart(ctx context.Context, userID string) error {
+	query := `DELETE FROM cart WHERE user_id = $1`
+
+	if _, err := r.DB.Pool.Exec(ctx, query, userID); err != nil {
+		return errs.InternalError("failed to clear cart", err)
+	}
+	return nil
+}
+
+// MergeCartItems folds the given lines into the user's cart in a single transaction.
+// Quantities of products that are already in the cart are summed.
+func (r *CartRepository) MergeCartItems(ctx context.Context, userID string, items []models.CartItem) error {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	for _, item := range items {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO cart (user_id, product_id, quantity)
+			 VALUES ($1, $2, $3)
+			 ON CONFLICT (user_id, product_id)
+			 DO UPDATE SET quantity = cart.quantity + EXCLUDED.quantity`,
+			userID, item.ProductID, item.Quantity,
+		)
+		if err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return errs.NotFound(fmt.Sprintf("product with id %d not found", item.ProductID), err)
+			}
+			return errs.InternalError("failed to merge cart item", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errs.InternalError("failed to commit transaction", err)
+	}
+	return nil
+}
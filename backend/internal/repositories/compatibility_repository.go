@@ -0,0 +1,157 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type CompatibilityRepository struct {
+	DB *database.DB
+}
+
+func NewCompatibilityRepository(db *database.DB) *CompatibilityRepository {
+	return &CompatibilityRepository{DB: db}
+}
+
+const compatibilityRuleColumns = `rule_id, name, description, severity::text, subject_category_id, subject_product_id,
+	subject_filter_spec, subject_filter_value, target_category_id, target_filter_spec, target_filter_value, target_spec,
+	target_aggregate::text, operator::text, quantifier::text, margin::float8, subject_spec, value, created_at`
+
+func mapCompatibilityRuleWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23503":
+			return errs.NotFound("rule category or product not found", err)
+		case "23514":
+			return errs.UnprocessableEntity("INVALID_COMPATIBILITY_RULE", err)
+		}
+	}
+	return errs.InternalError("failed to save compatibility rule", err)
+}
+
+func (r *CompatibilityRepository) FetchRules(ctx context.Context, filter *dtos.CompatibilityRuleFilterDTO) ([]*models.CompatibilityRule, error) {
+	conditions := []string{}
+	args := []any{}
+	argPos := 1
+
+	if filter.CategoryID != nil {
+		conditions = append(conditions, fmt.Sprintf("(subject_category_id = $%d OR target_category_id = $%d)", argPos, argPos))
+		args = append(args, *filter.CategoryID)
+		argPos++
+	}
+	if filter.ProductID != nil {
+		conditions = append(conditions, fmt.Sprintf("subject_product_id = $%d", argPos))
+		args = append(args, *filter.ProductID)
+		argPos++
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM compatibility_rules", compatibilityRuleColumns)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY rule_id"
+
+	rows, err := r.DB.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch compatibility rules", err)
+	}
+	defer rows.Close()
+
+	rules, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.CompatibilityRule])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect compatibility rules", err)
+	}
+	return rules, nil
+}
+
+func (r *CompatibilityRepository) FindRuleByID(ctx context.Context, ruleID int) (*models.CompatibilityRule, error) {
+	query := fmt.Sprintf("SELECT %s FROM compatibility_rules WHERE rule_id = $1", compatibilityRuleColumns)
+
+	rows, err := r.DB.Pool.Query(ctx, query, ruleID)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch compatibility rule with id %d", ruleID), err)
+	}
+	rule, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByPos[models.CompatibilityRule])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("compatibility rule with id %d not found", ruleID), err)
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch compatibility rule with id %d", ruleID), err)
+	}
+	return rule, nil
+}
+
+func (r *CompatibilityRepository) InsertRule(ctx context.Context, rule *models.CompatibilityRule) (*models.CompatibilityRule, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO compatibility_rules (
+			name, description, severity, subject_category_id, subject_product_id, subject_filter_spec,
+			subject_filter_value, target_category_id, target_filter_spec, target_filter_value, target_spec,
+			target_aggregate, operator, quantifier, margin, subject_spec, value
+		)
+		VALUES ($1, $2, $3::compatibility_severity, $4, $5, $6, $7, $8, $9, $10, $11,
+			$12::compatibility_aggregate, $13::compatibility_operator, $14::compatibility_quantifier, $15, $16, $17)
+		RETURNING %s`, compatibilityRuleColumns)
+
+	rows, err := r.DB.Pool.Query(ctx, query,
+		rule.Name, rule.Description, rule.Severity, rule.SubjectCategoryID, rule.SubjectProductID, rule.SubjectFilterSpec,
+		rule.SubjectFilterValue, rule.TargetCategoryID, rule.TargetFilterSpec, rule.TargetFilterValue, rule.TargetSpec,
+		rule.TargetAggregate, rule.Operator, rule.Quantifier, rule.Margin, rule.SubjectSpec, rule.Value)
+	if err != nil {
+		return nil, mapCompatibilityRuleWriteError(err)
+	}
+	created, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByPos[models.CompatibilityRule])
+	if err != nil {
+		return nil, mapCompatibilityRuleWriteError(err)
+	}
+	return created, nil
+}
+
+func (r *CompatibilityRepository) DeleteRule(ctx context.Context, ruleID int) error {
+	result, err := r.DB.Pool.Exec(ctx, `DELETE FROM compatibility_rules WHERE rule_id = $1`, ruleID)
+	if err != nil {
+		return errs.InternalError(fmt.Sprintf("failed to delete compatibility rule with id %d", ruleID), err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.NotFound(fmt.Sprintf("compatibility rule with id %d not found", ruleID), nil)
+	}
+	return nil
+}
+
+// FetchBuildComponents loads the products the rule engine evaluates, with their category
+// ancestry and specifications. Unknown ids are left out; quantities are up to the caller.
+func (r *CompatibilityRepository) FetchBuildComponents(ctx context.Context, productIDs []int) ([]*models.BuildComponent, error) {
+	rows, err := r.DB.Pool.Query(ctx, `
+		SELECT p.product_id, p.name, p.category_id,
+		       ARRAY(SELECT ancestor_id FROM category_ancestors WHERE category_id = p.category_id),
+		       COALESCE(jsonb_object_agg(ps.spec_name, ps.spec_value) FILTER (WHERE ps.spec_name IS NOT NULL), '{}')
+		FROM products p
+		LEFT JOIN product_specifications ps ON ps.product_id = p.product_id
+		WHERE p.product_id = ANY($1)
+		GROUP BY p.product_id
+		ORDER BY p.product_id
+	`, productIDs)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch build components", err)
+	}
+	defer rows.Close()
+
+	components, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.BuildComponent, error) {
+		component := &models.BuildComponent{Quantity: 1}
+		err := row.Scan(&component.ProductID, &component.Name, &component.CategoryID, &component.CategoryIDs, &component.Specs)
+		return component, err
+	})
+	if err != nil {
+		return nil, errs.InternalError("failed to collect build components", err)
+	}
+	return components, nil
+}
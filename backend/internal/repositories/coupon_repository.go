@@ -0,0 +1,327 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type CouponRepository struct {
+	DB *database.DB
+}
+
+func NewCouponRepository(db *database.DB) *CouponRepository {
+	return &CouponRepository{DB: db}
+}
+
+const couponColumns = `c.coupon_id, c.code, c.description, c.coupon_type, c.value, c.min_order_amount,
+	c.max_discount_amount, c.usage_limit, c.per_user_limit, c.starts_at, c.expires_at, c.is_active, c.created_at,
+	(SELECT COUNT(*) FROM coupon_redemptions r JOIN orders o ON o.order_id = r.order_id
+	 WHERE r.coupon_id = c.coupon_id AND o.status <> 'cancelled'),
+	ARRAY(SELECT category_id FROM coupon_categories WHERE coupon_id = c.coupon_id ORDER BY category_id),
+	ARRAY(SELECT brand_id FROM coupon_brands WHERE coupon_id = c.coupon_id ORDER BY brand_id)`
+
+func scanCoupon(row pgx.Row) (*models.Coupon, error) {
+	var coupon models.Coupon
+	err := row.Scan(
+		&coupon.CouponID,
+		&coupon.Code,
+		&coupon.Description,
+		&coupon.CouponType,
+		&coupon.Value,
+		&coupon.MinOrderAmount,
+		&coupon.MaxDiscountAmount,
+		&coupon.UsageLimit,
+		&coupon.PerUserLimit,
+		&coupon.StartsAt,
+		&coupon.ExpiresAt,
+		&coupon.IsActive,
+		&coupon.CreatedAt,
+		&coupon.TimesRedeemed,
+		&coupon.CategoryIDs,
+		&coupon.BrandIDs,
+	)
+	return &coupon, err
+}
+
+// mapCouponError translates the SQLSTATE codes raised by quote_coupon and the coupon table
+// constraints into API errors. quote_coupon puts the API error code in the message.
+func mapCouponError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "SC404":
+			return errs.NotFound(pgErr.Message, err)
+		case "SC409":
+			return errs.Conflict(pgErr.Message, err)
+		case "SC422":
+			return errs.UnprocessableEntity(pgErr.Message, err)
+		case "23505":
+			return errs.Conflict("COUPON_CODE_ALREADY_EXISTS", err)
+		case "23514":
+			return errs.UnprocessableEntity("INVALID_COUPON", err)
+		case "23503":
+			return errs.NotFound("category or brand not found", err)
+		}
+	}
+	return errs.InternalError("failed to process coupon", err)
+}
+
+func (r *CouponRepository) FetchCoupons(ctx context.Context, filter *dtos.CouponFilterDTO) ([]*models.Coupon, error) {
+	conditions := []string{}
+	args := []any{}
+	argPos := 1
+
+	if filter.Active != nil {
+		conditions = append(conditions, fmt.Sprintf(
+			"(c.is_active AND c.starts_at <= CURRENT_TIMESTAMP AND (c.expires_at IS NULL OR c.expires_at >= CURRENT_TIMESTAMP)) = $%d", argPos))
+		args = append(args, *filter.Active)
+		argPos++
+	}
+	if filter.Code != "" {
+		conditions = append(conditions, fmt.Sprintf("c.code LIKE '%%' || UPPER($%d) || '%%'", argPos))
+		args = append(args, filter.Code)
+		argPos++
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM coupons c", couponColumns)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY c.created_at DESC"
+
+	rows, err := r.DB.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch coupons", err)
+	}
+	defer rows.Close()
+
+	coupons, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.Coupon, error) {
+		return scanCoupon(row)
+	})
+	if err != nil {
+		return nil, errs.InternalError("failed to collect coupons", err)
+	}
+	return coupons, nil
+}
+
+func (r *CouponRepository) FindCouponByID(ctx context.Context, couponID int) (*models.Coupon, error) {
+	query := fmt.Sprintf("SELECT %s FROM coupons c WHERE c.coupon_id = $1", couponColumns)
+
+	coupon, err := scanCoupon(r.DB.Pool.QueryRow(ctx, query, couponID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("coupon with id %d not found", couponID), err)
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch coupon with id %d", couponID), err)
+	}
+	return coupon, nil
+}
+
+// replaceCouponRestrictions swaps the category and brand restrictions of a coupon; a nil
+// slice leaves that restriction untouched
+func replaceCouponRestrictions(ctx context.Context, tx pgx.Tx, couponID int, categoryIDs, brandIDs *[]int) error {
+	if categoryIDs != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM coupon_categories WHERE coupon_id = $1`, couponID); err != nil {
+			return errs.InternalError("failed to clear coupon categories", err)
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO coupon_categories (coupon_id, category_id)
+			 SELECT $1, UNNEST($2::int[])
+			 ON CONFLICT DO NOTHING`,
+			couponID, *categoryIDs,
+		)
+		if err != nil {
+			return mapCouponError(err)
+		}
+	}
+	if brandIDs != nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM coupon_brands WHERE coupon_id = $1`, couponID); err != nil {
+			return errs.InternalError("failed to clear coupon brands", err)
+		}
+		_, err := tx.Exec(ctx,
+			`INSERT INTO coupon_brands (coupon_id, brand_id)
+			 SELECT $1, UNNEST($2::int[])
+			 ON CONFLICT DO NOTHING`,
+			couponID, *brandIDs,
+		)
+		if err != nil {
+			return mapCouponError(err)
+		}
+	}
+	return nil
+}
+
+// InsertCoupon creates a coupon with its restrictions. The code is expected upper case.
+func (r *CouponRepository) InsertCoupon(ctx context.Context, dto *dtos.CreateCouponDTO) (*models.Coupon, error) {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var couponID int
+	err = tx.QueryRow(ctx,
+		`INSERT INTO coupons (code, description, coupon_type, value, min_order_amount, max_discount_amount,
+		                      usage_limit, per_user_limit, starts_at, expires_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8, COALESCE($9, CURRENT_TIMESTAMP), $10)
+		 RETURNING coupon_id`,
+		dto.Code, dto.Description, dto.CouponType, dto.Value, dto.MinOrderAmount, dto.MaxDiscountAmount,
+		dto.UsageLimit, dto.PerUserLimit, dto.StartsAt, dto.ExpiresAt,
+	).Scan(&couponID)
+	if err != nil {
+		return nil, mapCouponError(err)
+	}
+
+	if err = replaceCouponRestrictions(ctx, tx, couponID, &dto.CategoryIDs, &dto.BrandIDs); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errs.InternalError("failed to commit transaction", err)
+	}
+	return r.FindCouponByID(ctx, couponID)
+}
+
+func (r *CouponRepository) UpdateCoupon(ctx context.Context, couponID int, dto *dtos.UpdateCouponDTO) (*models.Coupon, error) {
+	setClauses := []string{}
+	args := []any{}
+	argPos := 1
+
+	addClause := func(column string, value any) {
+		setClauses = append(setClauses, fmt.Sprintf("%s = $%d", column, argPos))
+		args = append(args, value)
+		argPos++
+	}
+	if dto.Description != nil {
+		addClause("description", *dto.Description)
+	}
+	if dto.Value != nil {
+		addClause("value", *dto.Value)
+	}
+	if dto.MinOrderAmount != nil {
+		addClause("min_order_amount", *dto.MinOrderAmount)
+	}
+	if dto.MaxDiscountAmount != nil {
+		addClause("max_discount_amount", *dto.MaxDiscountAmount)
+	}
+	if dto.UsageLimit != nil {
+		addClause("usage_limit", *dto.UsageLimit)
+	}
+	if dto.PerUserLimit != nil {
+		addClause("per_user_limit", *dto.PerUserLimit)
+	}
+	if dto.StartsAt != nil {
+		addClause("starts_at", *dto.StartsAt)
+	}
+	if dto.ExpiresAt != nil {
+		addClause("expires_at", *dto.ExpiresAt)
+	}
+	if dto.IsActive != nil {
+		addClause("is_active", *dto.IsActive)
+	}
+
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// With only restriction changes the row is locked instead of updated
+	query := `SELECT coupon_id FROM coupons WHERE coupon_id = $1 FOR UPDATE`
+	if len(setClauses) > 0 {
+		query = fmt.Sprintf(`UPDATE coupons SET %s WHERE coupon_id = $%d RETURNING coupon_id`,
+			strings.Join(setClauses, ", "), argPos)
+	}
+	args = append(args, couponID)
+
+	var id int
+	if err = tx.QueryRow(ctx, query, args...).Scan(&id); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("coupon with id %d not found", couponID), err)
+		}
+		return nil, mapCouponError(err)
+	}
+
+	if err = replaceCouponRestrictions(ctx, tx, couponID, dto.CategoryIDs, dto.BrandIDs); err != nil {
+		return nil, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errs.InternalError("failed to commit transaction", err)
+	}
+	return r.FindCouponByID(ctx, couponID)
+}
+
+// DeleteCoupon removes a coupon that has never been redeemed; used coupons should be
+// deactivated instead so their redemptions stay on record.
+func (r *CouponRepository) DeleteCoupon(ctx context.Context, couponID int) error {
+	result, err := r.DB.Pool.Exec(ctx, `DELETE FROM coupons WHERE coupon_id = $1`, couponID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return errs.Conflict("COUPON_HAS_REDEMPTIONS", err)
+		}
+		return errs.InternalError(fmt.Sprintf("failed to delete coupon with id %d", couponID), err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.NotFound(fmt.Sprintf("coupon with id %d not found", couponID), nil)
+	}
+	return nil
+}
+
+func (r *CouponRepository) FetchRedemptions(ctx context.Context, couponID int) ([]*models.CouponRedemption, error) {
+	query := `
+		SELECT r.redemption_id, r.coupon_id, c.code, r.user_id, r.order_id, r.discount_amount, r.redeemed_at
+		FROM coupon_redemptions r
+		JOIN coupons c ON c.coupon_id = r.coupon_id
+		WHERE r.coupon_id = $1
+		ORDER BY r.redeemed_at DESC`
+
+	rows, err := r.DB.Pool.Query(ctx, query, couponID)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch redemptions of coupon %d", couponID), err)
+	}
+	defer rows.Close()
+
+	redemptions, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.CouponRedemption])
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to collect redemptions of coupon %d", couponID), err)
+	}
+	return redemptions, nil
+}
+
+// QuoteCoupon works out what a coupon is worth for the given items without redeeming it
+func (r *CouponRepository) QuoteCoupon(ctx context.Context, userID, code string, items []models.OrderItem) (*models.CouponQuote, error) {
+	type line struct {
+		ProductID int `json:"product_id"`
+		Quantity  int `json:"quantity"`
+	}
+	lines := make([]line, len(items))
+	for i, item := range items {
+		lines[i] = line{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	itemsJSON, err := json.Marshal(lines)
+	if err != nil {
+		return nil, errs.InternalError("failed to encode coupon items", err)
+	}
+
+	quote := &models.CouponQuote{Code: strings.ToUpper(code)}
+	err = r.DB.Pool.QueryRow(ctx,
+		`SELECT coupon_id, eligible_subtotal, discount_amount FROM quote_coupon($1, $2, $3::jsonb)`,
+		userID, code, string(itemsJSON),
+	).Scan(&quote.CouponID, &quote.EligibleSubtotal, &quote.DiscountAmount)
+	if err != nil {
+		return nil, mapCouponError(err)
+	}
+	return quote, nil
+}
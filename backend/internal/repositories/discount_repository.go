@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type DiscountRepository struct {
+	DB *database.DB
+}
+
+func NewDiscountRepository(db *database.DB) *DiscountRepository {
+	return &DiscountRepository{DB: db}
+}
+
+const discountColumns = `discount_id, product_id, category_id, discount_percentage, start_date, end_date,
+	CURRENT_TIMESTAMP BETWEEN start_date AND end_date AS active`
+
+func mapDiscountWriteError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23503":
+			return errs.NotFound("discounted product or category not found", err)
+		case "23514":
+			return errs.UnprocessableEntity("INVALID_DISCOUNT", err)
+		}
+	}
+	return errs.InternalError("failed to save discount", err)
+}
+
+func (r *DiscountRepository) FetchDiscounts(ctx context.Context, filter *dtos.DiscountFilterDTO) ([]*models.Discount, error) {
+	conditions := []string{}
+	args := []any{}
+	argPos := 1
+
+	if filter.ProductID != nil {
+		conditions = append(conditions, fmt.Sprintf("product_id = $%d", argPos))
+		args = append(args, *filter.ProductID)
+		argPos++
+	}
+	if filter.CategoryID != nil {
+		conditions = append(conditions, fmt.Sprintf("category_id = $%d", argPos))
+		args = append(args, *filter.CategoryID)
+		argPos++
+	}
+	if filter.Active != nil {
+		conditions = append(conditions, fmt.Sprintf("(CURRENT_TIMESTAMP BETWEEN start_date AND end_date) = $%d", argPos))
+		args = append(args, *filter.Active)
+		argPos++
+	}
+
+	query := fmt.Sprintf("SELECT %s FROM discounts", discountColumns)
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY start_date DESC, discount_id DESC"
+
+	rows, err := r.DB.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch discounts", err)
+	}
+	defer rows.Close()
+
+	discounts, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.Discount])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect discounts", err)
+	}
+	return discounts, nil
+}
+
+func (r *DiscountRepository) FindDiscountByID(ctx context.Context, discountID int) (*models.Discount, error) {
+	query := fmt.Sprintf("SELECT %s FROM discounts WHERE discount_id = $1", discountColumns)
+
+	rows, err := r.DB.Pool.Query(ctx, query, discountID)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch discount with id %d", discountID), err)
+	}
+	discount, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByPos[models.Discount])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("discount with id %d not found", discountID), err)
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch discount with id %d", discountID), err)
+	}
+	return discount, nil
+}
+
+func (r *DiscountRepository) InsertDiscount(ctx context.Context, discount *models.Discount) (*models.Discount, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO discounts (product_id, category_id, discount_percentage, start_date, end_date)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING %s`, discountColumns)
+
+	rows, err := r.DB.Pool.Query(ctx, query,
+		discount.ProductID, discount.CategoryID, discount.DiscountPercentage, discount.StartDate, discount.EndDate)
+	if err != nil {
+		return nil, mapDiscountWriteError(err)
+	}
+	created, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByPos[models.Discount])
+	if err != nil {
+		return nil, mapDiscountWriteError(err)
+	}
+	return created, nil
+}
+
+// UpdateDiscount overwrites every column of an existing discount
+func (r *DiscountRepository) UpdateDiscount(ctx context.Context, discount *models.Discount) (*models.Discount, error) {
+	query := fmt.Sprintf(`
+		UPDATE discounts
+		SET product_id = $1, category_id = $2, discount_percentage = $3, start_date = $4, end_date = $5
+		WHERE discount_id = $6
+		RETURNING %s`, discountColumns)
+
+	rows, err := r.DB.Pool.Query(ctx, query,
+		discount.ProductID, discount.CategoryID, discount.DiscountPercentage, discount.StartDate, discount.EndDate,
+		discount.DiscountID)
+	if err != nil {
+		return nil, mapDiscountWriteError(err)
+	}
+	updated, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByPos[models.Discount])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("discount with id %d not found", discount.DiscountID), err)
+		}
+		return nil, mapDiscountWriteError(err)
+	}
+	return updated, nil
+}
+
+func (r *DiscountRepository) DeleteDiscount(ctx context.Context, discountID int) error {
+	result, err := r.DB.Pool.Exec(ctx, `DELETE FROM discounts WHERE discount_id = $1`, discountID)
+	if err != nil {
+		return errs.InternalError(fmt.Sprintf("failed to delete discount with id %d", discountID), err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.NotFound(fmt.Sprintf("discount with id %d not found", discountID), nil)
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type InventoryRepository struct {
+	DB *database.DB
+}
+
+func NewInventoryRepository(db *database.DB) *InventoryRepository {
+	return &InventoryRepository{DB: db}
+}
+
+// lockProduct serializes stock changes of a product for the rest of the transaction
+func lockProduct(ctx context.Context, tx pgx.Tx, productID int) error {
+	var id int
+	err := tx.QueryRow(ctx, `SELECT product_id FROM products WHERE product_id = $1 FOR UPDATE`, productID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.NotFound(fmt.Sprintf("product with id %d not found", productID), err)
+		}
+		return errs.InternalError(fmt.Sprintf("failed to lock product with id %d", productID), err)
+	}
+	return nil
+}
+
+func insertStockMovement(ctx context.Context, tx pgx.Tx, productID int, inventoryID, supplierID *int, quantityChange int, reason, note string, performedBy string) error {
+	_, err := tx.Exec(ctx,
+		`INSERT INTO stock_movements (product_id, inventory_id, supplier_id, quantity_change, reason, note, performed_by)
+		 VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)`,
+		productID, inventoryID, supplierID, quantityChange, reason, note, performedBy,
+	)
+	if err != nil {
+		return errs.InternalError("failed to record stock movement", err)
+	}
+	return nil
+}
+
+// ReceiveStock books a delivery from a supplier as a new inventory lot
+func (r *InventoryRepository) ReceiveStock(ctx context.Context, productID int, dto *dtos.ReceiveStockDTO, performedBy string) error {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	var inventoryID int
+	err = tx.QueryRow(ctx,
+		`INSERT INTO inventory (product_id, supplier_id, quantity)
+		 VALUES ($1, $2, $3)
+		 RETURNING inventory_id`,
+		productID, dto.SupplierID, dto.Quantity,
+	).Scan(&inventoryID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return errs.NotFound(fmt.Sprintf("supplier with id %d not found", dto.SupplierID), err)
+		}
+		return errs.InternalError("failed to insert inventory lot", err)
+	}
+
+	err = insertStockMovement(ctx, tx, productID, &inventoryID, &dto.SupplierID, dto.Quantity, models.StockReasonReceipt, dto.Note, performedBy)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errs.InternalError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+// AdjustStock applies a manual correction. Positive changes are added to the most recent lot
+// (a new lot is opened if there is none); negative changes consume the oldest lots first and
+// are recorded once per lot touched.
+func (r *InventoryRepository) AdjustStock(ctx context.Context, productID int, dto *dtos.AdjustStockDTO, performedBy string) error {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	if dto.QuantityChange > 0 {
+		err = addToLatestLot(ctx, tx, productID, dto, performedBy)
+	} else {
+		err = takeFromOldestLots(ctx, tx, productID, dto, performedBy)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errs.InternalError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+func addToLatestLot(ctx context.Context, tx pgx.Tx, productID int, dto *dtos.AdjustStockDTO, performedBy string) error {
+	var inventoryID int
+	var supplierID *int
+	err := tx.QueryRow(ctx,
+		`UPDATE inventory
+		 SET quantity = quantity + $1, last_updated = CURRENT_TIMESTAMP
+		 WHERE inventory_id = (
+			SELECT inventory_id FROM inventory
+			WHERE product_id = $2 AND ($3::int IS NULL OR supplier_id = $3)
+			ORDER BY last_updated DESC, inventory_id DESC
+			LIMIT 1
+		 )
+		 RETURNING inventory_id, supplier_id`,
+		dto.QuantityChange, productID, dto.SupplierID,
+	).Scan(&inventoryID, &supplierID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		err = tx.QueryRow(ctx,
+			`INSERT INTO inventory (product_id, supplier_id, quantity)
+			 VALUES ($1, $2, $3)
+			 RETURNING inventory_id, supplier_id`,
+			productID, dto.SupplierID, dto.QuantityChange,
+		).Scan(&inventoryID, &supplierID)
+	}
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return errs.NotFound(fmt.Sprintf("supplier with id %d not found", *dto.SupplierID), err)
+		}
+		return errs.InternalError("failed to adjust inventory", err)
+	}
+
+	return insertStockMovement(ctx, tx, productID, &inventoryID, supplierID, dto.QuantityChange, dto.Reason, dto.Note, performedBy)
+}
+
+func takeFromOldestLots(ctx context.Context, tx pgx.Tx, productID int, dto *dtos.AdjustStockDTO, performedBy string) error {
+	rows, err := tx.Query(ctx,
+		`SELECT inventory_id, supplier_id, quantity
+		 FROM inventory
+		 WHERE product_id = $1 AND quantity > 0 AND ($2::int IS NULL OR supplier_id = $2)
+		 ORDER BY last_updated, inventory_id
+		 FOR UPDATE`,
+		productID, dto.SupplierID,
+	)
+	if err != nil {
+		return errs.InternalError("failed to fetch inventory lots", err)
+	}
+	lots, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.InventoryLot, error) {
+		var lot models.InventoryLot
+		err := row.Scan(&lot.InventoryID, &lot.SupplierID, &lot.Quantity)
+		return lot, err
+	})
+	if err != nil {
+		return errs.InternalError("failed to collect inventory lots", err)
+	}
+
+	remaining := -dto.QuantityChange
+	for _, lot := range lots {
+		if remaining == 0 {
+			break
+		}
+		taken := min(lot.Quantity, remaining)
+		_, err = tx.Exec(ctx,
+			`UPDATE inventory SET quantity = quantity - $1, last_updated = CURRENT_TIMESTAMP WHERE inventory_id = $2`,
+			taken, lot.InventoryID,
+		)
+		if err != nil {
+			return errs.InternalError("failed to adjust inventory", err)
+		}
+		err = insertStockMovement(ctx, tx, productID, &lot.InventoryID, lot.SupplierID, -taken, dto.Reason, dto.Note, performedBy)
+		if err != nil {
+			return err
+		}
+		remaining -= taken
+	}
+
+	if remaining > 0 {
+		return errs.UnprocessableEntity("INSUFFICIENT_STOCK", nil)
+	}
+	return nil
+}
+
+// FetchStockLedger returns the product's current stock, its lots and a page of its
+// movements, newest first
+func (r *InventoryRepository) FetchStockLedger(ctx context.Context, productID int, limit, offset int) (*models.StockLedger, error) {
+	ledger := &models.StockLedger{ProductID: productID}
+	err := r.DB.Pool.QueryRow(ctx,
+		`SELECT name, stock_quantity FROM products WHERE product_id = $1`, productID,
+	).Scan(&ledger.ProductName, &ledger.StockQuantity)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("product with id %d not found", productID), err)
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch product with id %d", productID), err)
+	}
+
+	rows, err := r.DB.Pool.Query(ctx,
+		`SELECT i.inventory_id, i.product_id, i.supplier_id, s.name, i.quantity, i.last_updated
+		 FROM inventory i
+		 LEFT JOIN suppliers s ON i.supplier_id = s.supplier_id
+		 WHERE i.product_id = $1 AND i.quantity > 0
+		 ORDER BY i.last_updated, i.inventory_id`,
+		productID,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch inventory lots", err)
+	}
+	ledger.Lots, err = pgx.CollectRows(rows, pgx.RowToStructByPos[models.InventoryLot])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect inventory lots", err)
+	}
+
+	rows, err = r.DB.Pool.Query(ctx,
+		`SELECT movement_id, product_id, inventory_id, supplier_id, supplier_name, order_id,
+		        quantity_change, balance_after, reason, note, performed_by, created_at
+		 FROM product_stock_ledger
+		 WHERE product_id = $1
+		 ORDER BY created_at DESC, movement_id DESC
+		 LIMIT $2 OFFSET $3`,
+		productID, limit, offset,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch stock movements", err)
+	}
+	ledger.Movements, err = pgx.CollectRows(rows, pgx.RowToStructByPos[models.StockMovement])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect stock movements", err)
+	}
+
+	return ledger, nil
+}
+
+// FetchLowStockProducts lists products whose stock is at or below the threshold, emptiest first
+func (r *InventoryRepository) FetchLowStockProducts(ctx context.Context, threshold int, filter *dtos.LowStockFilterDTO) ([]*models.LowStockProduct, error) {
+	conditions := []string{"p.stock_quantity <= $1"}
+	args := []any{threshold}
+	argPos := 2
+
+	if filter.CategoryID != nil {
+		conditions = append(conditions, fmt.Sprintf("p.category_id = $%d", argPos))
+		args = append(args, *filter.CategoryID)
+		argPos++
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 50
+	}
+
+	query := fmt.Sprintf(`
+		SELECT p.product_id, p.name, p.category_id, p.brand_id, p.stock_quantity,
+		       (SELECT MAX(m.created_at) FROM stock_movements m
+		        WHERE m.product_id = p.product_id AND m.reason = 'receipt')
+		FROM products p
+		WHERE %s
+		ORDER BY p.stock_quantity, p.name
+		LIMIT $%d OFFSET $%d`,
+		strings.Join(conditions, " AND "), argPos, argPos+1)
+	args = append(args, limit, filter.Offset)
+
+	rows, err := r.DB.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch low stock products", err)
+	}
+	defer rows.Close()
+
+	products, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.LowStockProduct])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect low stock products", err)
+	}
+	return products, nil
+}
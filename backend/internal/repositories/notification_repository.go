@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// Notifications are written by the database, e.g. when a build gets cheaper than its price
+// alert; the application only reads them and marks them as read
+type NotificationRepository struct {
+	DB *database.DB
+}
+
+func NewNotificationRepository(db *database.DB) *NotificationRepository {
+	return &NotificationRepository{DB: db}
+}
+
+const notificationColumns = `notification_id, user_id, type, message, data, created_at, read_at`
+
+// GetUserNotifications lists a page of the notifications of a user, newest first
+func (r *NotificationRepository) GetUserNotifications(ctx context.Context, userID string, filter *dtos.NotificationFilterDTO) ([]*models.Notification, error) {
+	rows, err := r.DB.Pool.Query(ctx,
+		`SELECT `+notificationColumns+`
+		 FROM notifications
+		 WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+		 ORDER BY created_at DESC, notification_id DESC
+		 LIMIT $3 OFFSET $4`,
+		userID, filter.Unread, filter.Limit, filter.Offset,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch notifications", err)
+	}
+	notifications, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.Notification])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect notifications", err)
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead marks a notification of the user as read; one read before keeps its
+// first read time
+func (r *NotificationRepository) MarkNotificationRead(ctx context.Context, notificationID int, userID string) (*models.Notification, error) {
+	rows, err := r.DB.Pool.Query(ctx,
+		`UPDATE notifications
+		 SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		 WHERE notification_id = $1 AND user_id = $2
+		 RETURNING `+notificationColumns,
+		notificationID, userID,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to mark notification as read", err)
+	}
+	notification, err := pgx.CollectOneRow(rows, pgx.RowToAddrOfStructByPos[models.Notification])
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound("notification not found", nil)
+		}
+		return nil, errs.InternalError("failed to collect notification", err)
+	}
+	return notification, nil
+}
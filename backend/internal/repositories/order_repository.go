@@ -2,16 +2,18 @@ package repositories
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 
 	"github.com/amha-mersha/sanqa-suq/internal/database"
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
 	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
 	"github.com/amha-mersha/sanqa-suq/internal/models"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type OrderRepository struct {
@@ -22,19 +24,57 @@ func NewOrderRepository(db *database.DB) *OrderRepository {
 	return &OrderRepository{DB: db}
 }
 
-// FetchAllOrders returns every order in the DB
-func (r *OrderRepository) FetchAllOrders(ctx context.Context) ([]*models.Order, error) {
-	query := `
+// FetchAllOrders returns the orders matching the given filter, newest first
+func (r *OrderRepository) FetchAllOrders(ctx context.Context, filter *dtos.OrderFilterDTO) ([]*models.Order, error) {
+	conditions := []string{}
+	args := []any{}
+	i := 1
+	if filter.Status != "" {
+		conditions = append(conditions, fmt.Sprintf("status = $%d", i))
+		args = append(args, filter.Status)
+		i++
+	}
+	if filter.UserID != "" {
+		conditions = append(conditions, fmt.Sprintf("user_id = $%d", i))
+		args = append(args, filter.UserID)
+		i++
+	}
+	if filter.From != "" {
+		conditions = append(conditions, fmt.Sprintf("order_date >= $%d::date", i))
+		args = append(args, filter.From)
+		i++
+	}
+	if filter.To != "" {
+		conditions = append(conditions, fmt.Sprintf("order_date < $%d::date + 1", i))
+		args = append(args, filter.To)
+		i++
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = 50
+	}
+	args = append(args, limit, filter.Offset)
+
+	query := fmt.Sprintf(`
         SELECT order_id, user_id, address_id, order_date, status, total_amount, payment_method, payment_date
         FROM orders
-    `
-	rows, err := r.DB.Pool.Query(ctx, query)
+        %s
+        ORDER BY order_date DESC
+        LIMIT $%d OFFSET $%d
+    `, where, i, i+1)
+	rows, err := r.DB.Pool.Query(ctx, query, args...)
 	if err != nil {
 		return nil, errs.InternalError("failed to fetch orders", err)
 	}
 	defer rows.Close()
 
-	orders, err := pgx.CollectRows(rows, pgx.RowToStructByPos[*models.Order])
+	orders, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.Order])
 	if err != nil {
 		return nil, errs.InternalError("failed to collect orders", err)
 	}
@@ -74,6 +114,7 @@ func (r *OrderRepository) FetchOrdersByUserID(ctx context.Context, userID string
         SELECT order_id, user_id, address_id, order_date, status, total_amount, payment_method, payment_date
         FROM orders
         WHERE user_id = $1
+        ORDER BY order_date DESC
     `
 	rows, err := r.DB.Pool.Query(ctx, query, userID)
 	if err != nil {
@@ -81,13 +122,10 @@ func (r *OrderRepository) FetchOrdersByUserID(ctx context.Context, userID string
 	}
 	defer rows.Close()
 
-	orders, err := pgx.CollectRows(rows, pgx.RowToStructByPos[*models.Order])
+	orders, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.Order])
 	if err != nil {
 		return nil, errs.InternalError(fmt.Sprintf("failed to collect orders for user %s", userID), err)
 	}
-	if len(orders) == 0 {
-		return nil, errs.NotFound(fmt.Sprintf("no orders found for user %s", userID), nil)
-	}
 	return orders, nil
 }
 
@@ -132,9 +170,6 @@ func (r *OrderRepository) InsertNewOrder(ctx context.Context, dto *dtos.CreateOr
 // UpdateOrder performs a partial update, returning the updated row
 func (r *OrderRepository) UpdateOrder(ctx context.Context, orderID string, dto *dtos.UpdateOrderDTO) (*models.Order, error) {
 	fields := make(map[string]any)
-	if dto.Status != nil {
-		fields["status"] = *dto.Status
-	}
 	if dto.TotalAmount != nil {
 		fields["total_amount"] = *dto.TotalAmount
 	}
@@ -198,3 +233,370 @@ func (r *OrderRepository) DeleteOrderByID(ctx context.Context, orderID string) e
 	}
 	return nil
 }
+
+// PlaceOrder creates an order through the place_order database function, which prices the
+// items server-side and reserves stock. When clearCart is set the user's cart is emptied in
+// the same transaction so a failed checkout leaves the cart untouched. A non-empty couponCode
+// is redeemed through apply_coupon and taken off the order total. agreedPrices, keyed by
+// product ID, are the unit prices the user accepted; the order is rejected if the database
+// priced any of them differently.
+func (r *OrderRepository) PlaceOrder(ctx context.Context, userID string, addressID int, paymentMethod string, items []models.OrderItem, agreedPrices map[int]float64, clearCart bool, couponCode string) (*models.OrderWithItems, error) {
+	type orderLine struct {
+		ProductID int `json:"product_id"`
+		Quantity  int `json:"quantity"`
+	}
+	lines := make([]orderLine, len(items))
+	for i, item := range items {
+		lines[i] = orderLine{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	itemsJSON, err := json.Marshal(lines)
+	if err != nil {
+		return nil, errs.InternalError("failed to encode order items", err)
+	}
+
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var orderID string
+	err = tx.QueryRow(ctx,
+		`SELECT place_order($1, $2, $3::payment_method, $4::jsonb)`,
+		userID, addressID, paymentMethod, string(itemsJSON),
+	).Scan(&orderID)
+	if err != nil {
+		return nil, mapPlaceOrderError(err)
+	}
+
+	if len(agreedPrices) > 0 {
+		if err = checkAgreedPrices(ctx, tx, orderID, agreedPrices); err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(ctx,
+		`INSERT INTO order_status_history (order_id, from_status, to_status, changed_by)
+		 VALUES ($1, NULL, 'pending', $2)`,
+		orderID, userID,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to record order status", err)
+	}
+
+	if err = recordOrderStockMovements(ctx, tx, orderID, models.StockReasonSale, &userID); err != nil {
+		return nil, err
+	}
+
+	if couponCode != "" {
+		if _, err = tx.Exec(ctx, `SELECT apply_coupon($1, $2)`, orderID, couponCode); err != nil {
+			return nil, mapCouponError(err)
+		}
+	}
+
+	if clearCart {
+		if _, err = tx.Exec(ctx, `DELETE FROM cart WHERE user_id = $1`, userID); err != nil {
+			return nil, errs.InternalError("failed to clear cart", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errs.InternalError("failed to commit transaction", err)
+	}
+
+	return r.FindOrderWithItems(ctx, orderID)
+}
+
+// FindOrderWithItems returns an order together with its order_items lines
+func (r *OrderRepository) FindOrderWithItems(ctx context.Context, orderID string) (*models.OrderWithItems, error) {
+	order, err := r.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.FetchOrderItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	coupon, err := r.FindOrderCoupon(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OrderWithItems{Order: *order, Items: items, Coupon: coupon}, nil
+}
+
+// FindOrderCoupon returns the coupon redeemed for an order, or nil when none was used
+func (r *OrderRepository) FindOrderCoupon(ctx context.Context, orderID string) (*models.CouponRedemption, error) {
+	query := `
+        SELECT r.redemption_id, r.coupon_id, c.code, r.user_id, r.order_id, r.discount_amount, r.redeemed_at
+        FROM coupon_redemptions r
+        JOIN coupons c ON c.coupon_id = r.coupon_id
+        WHERE r.order_id = $1
+    `
+	var redemption models.CouponRedemption
+	err := r.DB.Pool.QueryRow(ctx, query, orderID).Scan(
+		&redemption.RedemptionID,
+		&redemption.CouponID,
+		&redemption.Code,
+		&redemption.UserID,
+		&redemption.OrderID,
+		&redemption.DiscountAmount,
+		&redemption.RedeemedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch coupon of order %s", orderID), err)
+	}
+	return &redemption, nil
+}
+
+// FetchOrderItems returns the lines of an order with the unit price captured at purchase time
+func (r *OrderRepository) FetchOrderItems(ctx context.Context, orderID string) ([]models.OrderItem, error) {
+	query := `
+        SELECT oi.order_id, oi.product_id, p.name, oi.quantity, oi.unit_price
+        FROM order_items oi
+        JOIN products p ON oi.product_id = p.product_id
+        WHERE oi.order_id = $1
+        ORDER BY p.name
+    `
+	rows, err := r.DB.Pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch items for order %s", orderID), err)
+	}
+	defer rows.Close()
+
+	items := []models.OrderItem{}
+	for rows.Next() {
+		var item models.OrderItem
+		if err := rows.Scan(&item.OrderID, &item.ProductID, &item.ProductName, &item.Quantity, &item.UnitPrice); err != nil {
+			return nil, errs.InternalError("failed to scan order item", err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, errs.InternalError("error iterating order items", err)
+	}
+	return items, nil
+}
+
+// mapPlaceOrderError translates the SQLSTATE codes raised by place_order into API errors
+// checkAgreedPrices compares the unit prices place_order charged with the agreed ones
+func checkAgreedPrices(ctx context.Context, tx pgx.Tx, orderID string, agreedPrices map[int]float64) error {
+	rows, err := tx.Query(ctx,
+		`SELECT product_id, unit_price::float8 FROM order_items WHERE order_id = $1 ORDER BY product_id`,
+		orderID,
+	)
+	if err != nil {
+		return errs.InternalError("failed to fetch order items", err)
+	}
+	changed := []int{}
+	var productID int
+	var unitPrice float64
+	_, err = pgx.ForEachRow(rows, []any{&productID, &unitPrice}, func() error {
+		if agreed, ok := agreedPrices[productID]; ok && math.Abs(agreed-unitPrice) > 0.005 {
+			changed = append(changed, productID)
+		}
+		return nil
+	})
+	if err != nil {
+		return errs.InternalError("failed to collect order items", err)
+	}
+	if len(changed) > 0 {
+		appErr := errs.Conflict("BUILD_PRICES_CHANGED", nil)
+		appErr.Meta = map[string]any{"product_ids": changed}
+		return appErr
+	}
+	return nil
+}
+
+func mapPlaceOrderError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "SQ403":
+			return errs.Forbidden("ADDRESS_NOT_OWNED_BY_USER", err)
+		case "SQ404":
+			return errs.NotFound(pgErr.Message, err)
+		case "SQ409":
+			return errs.Conflict(pgErr.Message, err)
+		case "22023":
+			return errs.BadRequest("INVALID_ORDER_ITEMS", err)
+		}
+	}
+	return errs.InternalError("failed to place order", err)
+}
+
+// ChangeOrderStatus moves an order from one status to another and records the change in
+// order_status_history. The update only applies while the order is still in fromStatus, so
+// concurrent changes are reported as a conflict. When restoreStock is set the order's items
+// are returned to inventory in the same transaction. changedBy may be nil for system changes.
+func (r *OrderRepository) ChangeOrderStatus(ctx context.Context, orderID, fromStatus, toStatus string, changedBy *string, reason string, restoreStock bool) error {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		`UPDATE orders SET status = $1 WHERE order_id = $2 AND status = $3`,
+		toStatus, orderID, fromStatus,
+	)
+	if err != nil {
+		return errs.InternalError(fmt.Sprintf("failed to update status of order %s", orderID), err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.Conflict("ORDER_STATUS_CHANGED_CONCURRENTLY", nil)
+	}
+
+	var reasonArg *string
+	if reason != "" {
+		reasonArg = &reason
+	}
+	_, err = tx.Exec(ctx,
+		`INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, reason)
+		 VALUES ($1, $2, $3, $4, $5)`,
+		orderID, fromStatus, toStatus, changedBy, reasonArg,
+	)
+	if err != nil {
+		return errs.InternalError("failed to record order status", err)
+	}
+
+	if toStatus == models.OrderStatusCancelled {
+		if err := cancelOrderPayments(ctx, tx, orderID); err != nil {
+			return err
+		}
+	}
+
+	if restoreStock {
+		if err := restoreOrderStock(ctx, tx, orderID); err != nil {
+			return err
+		}
+		if err := recordOrderStockMovements(ctx, tx, orderID, models.StockReasonOrderCancelled, changedBy); err != nil {
+			return err
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errs.InternalError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+// cancelOrderPayments gives up on the pending payments of an order being cancelled, so a
+// payment completed afterwards is refunded. An order whose payment went through cannot be
+// cancelled until the payment is refunded.
+func cancelOrderPayments(ctx context.Context, tx pgx.Tx, orderID string) error {
+	var paid bool
+	err := tx.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM payment_intents WHERE order_id = $1 AND status = 'succeeded')`,
+		orderID,
+	).Scan(&paid)
+	if err != nil {
+		return errs.InternalError("failed to check order payments", err)
+	}
+	if paid {
+		return errs.Conflict("ORDER_PAYMENT_NOT_REFUNDED", nil)
+	}
+
+	_, err = tx.Exec(ctx,
+		`UPDATE payment_intents
+		 SET status = 'failed', updated_at = CURRENT_TIMESTAMP
+		 WHERE order_id = $1 AND status = 'pending'`,
+		orderID,
+	)
+	if err != nil {
+		return errs.InternalError("failed to cancel order payments", err)
+	}
+	return nil
+}
+
+// restoreOrderStock puts the quantities of an order back into stock. Products tracked in
+// inventory get the quantity added to their most recent lot; untracked products have
+// products.stock_quantity incremented directly, mirroring how place_order reserved them.
+func restoreOrderStock(ctx context.Context, tx pgx.Tx, orderID string) error {
+	rows, err := tx.Query(ctx, `SELECT product_id, quantity FROM order_items WHERE order_id = $1`, orderID)
+	if err != nil {
+		return errs.InternalError("failed to fetch order items", err)
+	}
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.OrderItem, error) {
+		var item models.OrderItem
+		err := row.Scan(&item.ProductID, &item.Quantity)
+		return item, err
+	})
+	if err != nil {
+		return errs.InternalError("failed to collect order items", err)
+	}
+
+	for _, item := range items {
+		result, err := tx.Exec(ctx,
+			`UPDATE inventory
+			 SET quantity = quantity + $1, last_updated = CURRENT_TIMESTAMP
+			 WHERE inventory_id = (
+				SELECT inventory_id FROM inventory
+				WHERE product_id = $2
+				ORDER BY last_updated DESC, inventory_id DESC
+				LIMIT 1
+			 )`,
+			item.Quantity, item.ProductID,
+		)
+		if err != nil {
+			return errs.InternalError(fmt.Sprintf("failed to restore inventory for product %d", item.ProductID), err)
+		}
+		if result.RowsAffected() > 0 {
+			continue
+		}
+		_, err = tx.Exec(ctx,
+			`UPDATE products SET stock_quantity = stock_quantity + $1 WHERE product_id = $2`,
+			item.Quantity, item.ProductID,
+		)
+		if err != nil {
+			return errs.InternalError(fmt.Sprintf("failed to restore stock for product %d", item.ProductID), err)
+		}
+	}
+	return nil
+}
+
+// recordOrderStockMovements writes one stock ledger row per order line. Sales take stock out,
+// every other reason puts it back.
+func recordOrderStockMovements(ctx context.Context, tx pgx.Tx, orderID string, reason string, performedBy *string) error {
+	sign := 1
+	if reason == models.StockReasonSale {
+		sign = -1
+	}
+	_, err := tx.Exec(ctx,
+		`INSERT INTO stock_movements (product_id, order_id, quantity_change, reason, performed_by)
+		 SELECT product_id, order_id, $2 * quantity, $3::stock_movement_reason, $4::uuid
+		 FROM order_items
+		 WHERE order_id = $1`,
+		orderID, sign, reason, performedBy,
+	)
+	if err != nil {
+		return errs.InternalError(fmt.Sprintf("failed to record stock movements for order %s", orderID), err)
+	}
+	return nil
+}
+
+// FetchOrderStatusHistory returns the status changes of an order, oldest first
+func (r *OrderRepository) FetchOrderStatusHistory(ctx context.Context, orderID string) ([]*models.OrderStatusHistory, error) {
+	query := `
+        SELECT history_id, order_id, from_status, to_status, changed_by, reason, changed_at
+        FROM order_status_history
+        WHERE order_id = $1
+        ORDER BY changed_at, history_id
+    `
+	rows, err := r.DB.Pool.Query(ctx, query, orderID)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch status history for order %s", orderID), err)
+	}
+	defer rows.Close()
+
+	history, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.OrderStatusHistory])
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to collect status history for order %s", orderID), err)
+	}
+	return history, nil
+}
@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type PaymentRepository struct {
+	DB *database.DB
+}
+
+func NewPaymentRepository(db *database.DB) *PaymentRepository {
+	return &PaymentRepository{DB: db}
+}
+
+const paymentIntentColumns = `intent_id, order_id, provider, provider_reference, amount, status,
+	checkout_url, refund_reference, created_at, updated_at`
+
+func scanPaymentIntent(row pgx.Row) (*models.PaymentIntent, error) {
+	var intent models.PaymentIntent
+	err := row.Scan(
+		&intent.IntentID,
+		&intent.OrderID,
+		&intent.Provider,
+		&intent.ProviderReference,
+		&intent.Amount,
+		&intent.Status,
+		&intent.CheckoutURL,
+		&intent.RefundReference,
+		&intent.CreatedAt,
+		&intent.UpdatedAt,
+	)
+	return &intent, err
+}
+
+// CreateIntent records a new pending payment intent for an order
+func (r *PaymentRepository) CreateIntent(ctx context.Context, orderID, provider string, amount float64) (*models.PaymentIntent, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO payment_intents (order_id, provider, amount)
+		VALUES ($1, $2, $3)
+		RETURNING %s`, paymentIntentColumns)
+
+	intent, err := scanPaymentIntent(r.DB.Pool.QueryRow(ctx, query, orderID, provider, amount))
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return nil, errs.Conflict("ORDER_ALREADY_HAS_A_PAYMENT", err)
+		}
+		return nil, errs.InternalError("failed to create payment intent", err)
+	}
+	return intent, nil
+}
+
+// SetProviderDetails stores what the provider returned when the payment was initiated
+func (r *PaymentRepository) SetProviderDetails(ctx context.Context, intentID, providerReference, checkoutURL string) (*models.PaymentIntent, error) {
+	query := fmt.Sprintf(`
+		UPDATE payment_intents
+		SET provider_reference = $1, checkout_url = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE intent_id = $3
+		RETURNING %s`, paymentIntentColumns)
+
+	intent, err := scanPaymentIntent(r.DB.Pool.QueryRow(ctx, query, providerReference, checkoutURL, intentID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("payment intent %s not found", intentID), err)
+		}
+		return nil, errs.InternalError("failed to update payment intent", err)
+	}
+	return intent, nil
+}
+
+// FindLiveIntentByOrderID returns the pending or succeeded intent of an order
+func (r *PaymentRepository) FindLiveIntentByOrderID(ctx context.Context, orderID string) (*models.PaymentIntent, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM payment_intents
+		WHERE order_id = $1 AND status IN ('pending', 'succeeded')`, paymentIntentColumns)
+
+	intent, err := scanPaymentIntent(r.DB.Pool.QueryRow(ctx, query, orderID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("no payment found for order %s", orderID), err)
+		}
+		return nil, errs.InternalError("failed to fetch payment intent", err)
+	}
+	return intent, nil
+}
+
+func (r *PaymentRepository) FindIntentByReference(ctx context.Context, provider, providerReference string) (*models.PaymentIntent, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM payment_intents
+		WHERE provider = $1 AND provider_reference = $2`, paymentIntentColumns)
+
+	intent, err := scanPaymentIntent(r.DB.Pool.QueryRow(ctx, query, provider, providerReference))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("payment %s not found", providerReference), err)
+		}
+		return nil, errs.InternalError("failed to fetch payment intent", err)
+	}
+	return intent, nil
+}
+
+func (r *PaymentRepository) MarkIntentFailed(ctx context.Context, intentID string) error {
+	query := `
+		UPDATE payment_intents
+		SET status = 'failed', updated_at = CURRENT_TIMESTAMP
+		WHERE intent_id = $1 AND status = 'pending'`
+
+	if _, err := r.DB.Pool.Exec(ctx, query, intentID); err != nil {
+		return errs.InternalError("failed to mark payment intent as failed", err)
+	}
+	return nil
+}
+
+// MarkIntentSucceeded settles a pending intent and, if the order is still pending, moves it
+// to paid, stamps payment_date and records the change in order_status_history.
+// It reports whether this call settled the intent and whether the order was moved to paid.
+func (r *PaymentRepository) MarkIntentSucceeded(ctx context.Context, intent *models.PaymentIntent, paidAt time.Time) (settled bool, orderPaid bool, err error) {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return false, false, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	result, err := tx.Exec(ctx,
+		`UPDATE payment_intents
+		 SET status = 'succeeded', updated_at = CURRENT_TIMESTAMP
+		 WHERE intent_id = $1 AND status = 'pending'`,
+		intent.IntentID,
+	)
+	if err != nil {
+		return false, false, errs.InternalError("failed to settle payment intent", err)
+	}
+	if result.RowsAffected() == 0 {
+		// already settled by an earlier callback
+		return false, false, nil
+	}
+
+	result, err = tx.Exec(ctx,
+		`UPDATE orders
+		 SET status = 'paid', payment_date = $1
+		 WHERE order_id = $2 AND status = 'pending'`,
+		paidAt, intent.OrderID,
+	)
+	if err != nil {
+		return false, false, errs.InternalError(fmt.Sprintf("failed to mark order %s as paid", intent.OrderID), err)
+	}
+	orderPaid = result.RowsAffected() > 0
+
+	if orderPaid {
+		_, err = tx.Exec(ctx,
+			`INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, reason)
+			 VALUES ($1, 'pending', 'paid', NULL, $2)`,
+			intent.OrderID, fmt.Sprintf("payment %s confirmed by %s", *intent.ProviderReference, intent.Provider),
+		)
+		if err != nil {
+			return false, false, errs.InternalError("failed to record order status", err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return false, false, errs.InternalError("failed to commit transaction", err)
+	}
+	return true, orderPaid, nil
+}
+
+// MarkIntentRefunded records the refund of a settled intent, or of a failed one the
+// customer was charged for anyway
+func (r *PaymentRepository) MarkIntentRefunded(ctx context.Context, intentID, refundReference string) (*models.PaymentIntent, error) {
+	query := fmt.Sprintf(`
+		UPDATE payment_intents
+		SET status = 'refunded', refund_reference = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE intent_id = $2 AND status IN ('succeeded', 'failed')
+		RETURNING %s`, paymentIntentColumns)
+
+	intent, err := scanPaymentIntent(r.DB.Pool.QueryRow(ctx, query, refundReference, intentID))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.Conflict("PAYMENT_NOT_REFUNDABLE", err)
+		}
+		return nil, errs.InternalError("failed to mark payment intent as refunded", err)
+	}
+	return intent, nil
+}
@@ -10,8 +10,8 @@ import (
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
 	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
 	"github.com/amha-mersha/sanqa-suq/internal/models"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type ProductRepository struct {
@@ -23,37 +23,45 @@ func NewProductRepository(db *database.DB) *ProductRepository {
 }
 
 func (repository *ProductRepository) FetchAllProducts(ctx context.Context) ([]*models.Products, error) {
-	query := `SELECT product_id, category_id, brand_id, name, description, price, stock_quantity FROM products`
+	query := `
+		SELECT p.product_id, p.category_id, p.brand_id, p.name, p.description, p.price, p.stock_quantity,
+		       ep.original_price, ep.discount_id, ep.discount_percentage, ep.discount_amount, ep.final_price
+		FROM products p
+		JOIN product_effective_prices ep ON p.product_id = ep.product_id`
 	rows, err := repository.DB.Pool.Query(ctx, query)
 	if err != nil {
 		return nil, errs.InternalError("failed to fetch products", err)
 	}
 	defer rows.Close()
 
-	var products []*models.Products
-	for rows.Next() {
-		product := &models.Products{}
-		if err := rows.Scan(
-			&product.ProductID,
-			&product.CategoryID,
-			&product.BrandID,
-			&product.Name,
-			&product.Description,
-			&product.Price,
-			&product.StockQuantity,
-		); err != nil {
-			return nil, errs.InternalError("failed to scan product row", err)
-		}
-		products = append(products, product)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, errs.InternalError("error iterating product rows", err)
+	products, err := pgx.CollectRows(rows, scanPricedProduct)
+	if err != nil {
+		return nil, errs.InternalError("failed to scan product row", err)
 	}
 
 	return products, nil
 }
 
+// scanPricedProduct scans a product row followed by its product_effective_prices columns
+func scanPricedProduct(row pgx.CollectableRow) (*models.Products, error) {
+	product := &models.Products{Pricing: &models.PriceBreakdown{}}
+	err := row.Scan(
+		&product.ProductID,
+		&product.CategoryID,
+		&product.BrandID,
+		&product.Name,
+		&product.Description,
+		&product.Price,
+		&product.StockQuantity,
+		&product.Pricing.OriginalPrice,
+		&product.Pricing.DiscountID,
+		&product.Pricing.DiscountPercentage,
+		&product.Pricing.DiscountAmount,
+		&product.Pricing.FinalPrice,
+	)
+	return product, err
+}
+
 func (repository *ProductRepository) FindCategoryByID(ctx context.Context, categoryId int) (*models.Categories, error) {
 	var category models.Categories
 	query := `SELECT category_id,name, parent_category_id  FROM	categories c WHERE c.category_id = $1`
@@ -81,9 +89,11 @@ func (repository *ProductRepository) FetchProductsByCategoryID(ctx context.Conte
 			FROM categories c
 			INNER JOIN category_hierarchy ch ON c.parent_category_id = ch.category_id
 		)
-		SELECT p.product_id, p.category_id, p.brand_id, p.name, p.description, p.price, p.stock_quantity
+		SELECT p.product_id, p.category_id, p.brand_id, p.name, p.description, p.price, p.stock_quantity,
+		       ep.original_price, ep.discount_id, ep.discount_percentage, ep.discount_amount, ep.final_price
 		FROM products p
 		INNER JOIN category_hierarchy ch ON p.category_id = ch.category_id
+		JOIN product_effective_prices ep ON p.product_id = ep.product_id
 	`
 	rows, err := repository.DB.Pool.Query(ctx, query, categoryId)
 	if err != nil {
@@ -91,7 +101,7 @@ func (repository *ProductRepository) FetchProductsByCategoryID(ctx context.Conte
 	}
 	defer rows.Close()
 
-	products, err := pgx.CollectRows(rows, pgx.RowToStructByPos[*models.Products])
+	products, err := pgx.CollectRows(rows, scanPricedProduct)
 	if err != nil {
 		return nil, errs.InternalError(fmt.Sprintf("failed to scan products for category ID %d and its descendants", categoryId), err)
 	}
@@ -117,9 +127,28 @@ func (repository *ProductRepository) FindBrandByID(ctx context.Context, brandId
 }
 
 func (repository *ProductRepository) FindProductByID(ctx context.Context, id int) (*models.Products, error) {
-	query := `SELECT product_id, category_id, brand_id, name, description, price, stock_quantity FROM products WHERE product_id = $1`
+	query := `
+		SELECT p.product_id, p.category_id, p.brand_id, p.name, p.description, p.price, p.stock_quantity,
+		       ep.original_price, ep.discount_id, ep.discount_percentage, ep.discount_amount, ep.final_price
+		FROM products p
+		JOIN product_effective_prices ep ON p.product_id = ep.product_id
+		WHERE p.product_id = $1`
 	var product models.Products
-	err := repository.DB.Pool.QueryRow(ctx, query, id).Scan(&product.ProductID, &product.CategoryID, &product.BrandID, &product.Name, &product.Description, &product.Price, &product.StockQuantity)
+	product.Pricing = &models.PriceBreakdown{}
+	err := repository.DB.Pool.QueryRow(ctx, query, id).Scan(
+		&product.ProductID,
+		&product.CategoryID,
+		&product.BrandID,
+		&product.Name,
+		&product.Description,
+		&product.Price,
+		&product.StockQuantity,
+		&product.Pricing.OriginalPrice,
+		&product.Pricing.DiscountID,
+		&product.Pricing.DiscountPercentage,
+		&product.Pricing.DiscountAmount,
+		&product.Pricing.FinalPrice,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, errs.NotFound(fmt.Sprintf("product with id %d not found", id), err)
@@ -129,6 +158,8 @@ func (repository *ProductRepository) FindProductByID(ctx context.Context, id int
 	return &product, nil
 }
 
+// InsertNewProduct creates a product. Opening stock is booked as a supplier-less inventory
+// lot with an opening_balance ledger entry, so products.stock_quantity stays trigger-managed.
 func (repository *ProductRepository) InsertNewProduct(ctx context.Context, productDTO *dtos.CreateProductDTO) (*models.Products, error) {
 	query := `
 		INSERT INTO products (
@@ -136,17 +167,22 @@ func (repository *ProductRepository) InsertNewProduct(ctx context.Context, produ
 			brand_id,
 			name,
 			description,
-			price,
-			stock_quantity
+			price
 		) VALUES (
-			$1, $2, $3, $4, $5, $6
+			$1, $2, $3, $4, $5
 		)
 		RETURNING product_id, category_id, brand_id, name, description, price, stock_quantity, created_at;
 	`
 
+	tx, err := repository.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
 	newProduct := &models.Products{}
 
-	err := repository.DB.Pool.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
 		productDTO.CategoryID,
@@ -154,7 +190,6 @@ func (repository *ProductRepository) InsertNewProduct(ctx context.Context, produ
 		productDTO.Name,
 		productDTO.Description,
 		productDTO.Price,
-		productDTO.StockQuantity,
 	).Scan(
 		&newProduct.ProductID,
 		&newProduct.CategoryID,
@@ -179,6 +214,31 @@ func (repository *ProductRepository) InsertNewProduct(ctx context.Context, produ
 		return nil, errs.InternalError("failed to insert new product", err)
 	}
 
+	if productDTO.StockQuantity > 0 {
+		var inventoryID int
+		err = tx.QueryRow(ctx,
+			`INSERT INTO inventory (product_id, supplier_id, quantity) VALUES ($1, NULL, $2) RETURNING inventory_id`,
+			newProduct.ProductID, productDTO.StockQuantity,
+		).Scan(&inventoryID)
+		if err != nil {
+			return nil, errs.InternalError("failed to book opening stock", err)
+		}
+		_, err = tx.Exec(ctx,
+			`INSERT INTO stock_movements (product_id, inventory_id, quantity_change, reason)
+			 VALUES ($1, $2, $3, 'opening_balance')`,
+			newProduct.ProductID, inventoryID, productDTO.StockQuantity,
+		)
+		if err != nil {
+			return nil, errs.InternalError("failed to record opening stock", err)
+		}
+		stock := productDTO.StockQuantity
+		newProduct.StockQuantity = &stock
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errs.InternalError("failed to commit transaction", err)
+	}
+
 	return newProduct, nil
 }
 
@@ -236,7 +296,7 @@ func (repository *ProductRepository) GetProductSpecifications(ctx context.Contex
 		return nil, errs.InternalError(fmt.Sprintf("failed to fetch specifications for product ID %d", productId), err)
 	}
 	defer rows.Close()
-	specifications, err := pgx.CollectRows(rows, pgx.RowToStructByPos[*models.ProductSpecifications])
+	specifications, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.ProductSpecifications])
 	if err != nil {
 		return nil, errs.InternalError(fmt.Sprintf("failed to collect specifications for product ID %d", productId), err)
 	}
@@ -0,0 +1,329 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+// maxSpecFacetValues caps how many values are reported per specification name
+const maxSpecFacetValues = 25
+
+// searchHeadlineOptions control the ts_headline snippets returned with keyword searches
+const searchHeadlineOptions = `StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=" … "`
+
+const productSearchFrom = `
+	FROM products p
+	JOIN product_effective_prices ep ON ep.product_id = p.product_id
+	LEFT JOIN top_rated_products tr ON tr.product_id = p.product_id`
+
+var productSearchSortOrders = map[string]string{
+	"newest":     "p.created_at DESC, p.product_id DESC",
+	"price_asc":  "ep.final_price ASC, p.product_id",
+	"price_desc": "ep.final_price DESC, p.product_id",
+	"name":       "p.name ASC, p.product_id",
+	"rating":     "tr.avg_rating DESC NULLS LAST, tr.review_count DESC NULLS LAST, p.product_id",
+}
+
+// searchConditions collects the WHERE clauses of one search query together with their
+// arguments, so every query only binds the parameters it references
+type searchConditions struct {
+	clauses []string
+	args    []any
+}
+
+func (c *searchConditions) param(value any) string {
+	c.args = append(c.args, value)
+	return fmt.Sprintf("$%d", len(c.args))
+}
+
+func (c *searchConditions) add(clause string) {
+	c.clauses = append(c.clauses, clause)
+}
+
+func (c *searchConditions) where() string {
+	if len(c.clauses) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(c.clauses, " AND ")
+}
+
+// searchTSQuery turns free text into a tsquery matching products containing every word.
+// The last word is matched as a prefix so results keep up while the user is typing.
+// Only letters and digits are kept, so the result is always valid tsquery syntax; it is
+// empty when the text has no searchable words.
+func searchTSQuery(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if len(words) == 0 {
+		return ""
+	}
+	words[len(words)-1] += ":*"
+	return strings.Join(words, " & ")
+}
+
+// specFilterClause matches products having one of the values for a specification
+func specFilterClause(c *searchConditions, name string, values []string) string {
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM product_specifications s
+		WHERE s.product_id = p.product_id AND LOWER(s.spec_name) = %s AND LOWER(s.spec_value) = ANY(%s)
+	)`, c.param(name), c.param(values))
+}
+
+// specRangeClause matches products whose numeric value of a specification lies in the range
+func specRangeClause(c *searchConditions, name string, specRange dtos.SpecRange) string {
+	bounds := ""
+	if specRange.Min != nil {
+		bounds += " AND s.numeric_value >= " + c.param(*specRange.Min)
+	}
+	if specRange.Max != nil {
+		bounds += " AND s.numeric_value <= " + c.param(*specRange.Max)
+	}
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM product_specifications s
+		WHERE s.product_id = p.product_id AND LOWER(s.spec_name) = %s%s
+	)`, c.param(name), bounds)
+}
+
+// specFilterNames returns the filtered specification names in a stable order
+func specFilterNames[V any](filters map[string]V) []string {
+	names := make([]string, 0, len(filters))
+	for name := range filters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// buildSearchConditions turns the filter into WHERE clauses. skipBrand leaves out the
+// brand filter for the brand facet; forSpecFacet relaxes every spec filter for rows of
+// that same specification (aliased ps) so each spec facet ignores its own filter.
+func buildSearchConditions(filter *dtos.ProductSearchDTO, skipBrand bool, forSpecFacet bool) *searchConditions {
+	c := &searchConditions{}
+
+	if tsQuery := searchTSQuery(filter.Query); tsQuery != "" {
+		c.add(fmt.Sprintf("p.search_vector @@ to_tsquery('simple', %s)", c.param(tsQuery)))
+	}
+	if filter.CategoryID != 0 {
+		c.add(fmt.Sprintf(
+			"p.category_id IN (SELECT category_id FROM category_ancestors WHERE ancestor_id = %s)",
+			c.param(filter.CategoryID),
+		))
+	}
+	if len(filter.BrandIDs) > 0 && !skipBrand {
+		c.add(fmt.Sprintf("p.brand_id = ANY(%s)", c.param(filter.BrandIDs)))
+	}
+	if filter.MinPrice != nil {
+		c.add(fmt.Sprintf("ep.final_price >= %s", c.param(*filter.MinPrice)))
+	}
+	if filter.MaxPrice != nil {
+		c.add(fmt.Sprintf("ep.final_price <= %s", c.param(*filter.MaxPrice)))
+	}
+	if filter.InStock {
+		c.add("p.stock_quantity > 0")
+	}
+	addSpecClause := func(name string, clause string) {
+		if forSpecFacet {
+			clause = fmt.Sprintf("(LOWER(ps.spec_name) = %s OR %s)", c.param(name), clause)
+		}
+		c.add(clause)
+	}
+	for _, name := range specFilterNames(filter.SpecFilters) {
+		addSpecClause(name, specFilterClause(c, name, filter.SpecFilters[name]))
+	}
+	for _, name := range specFilterNames(filter.SpecRangeFilters) {
+		addSpecClause(name, specRangeClause(c, name, filter.SpecRangeFilters[name]))
+	}
+	return c
+}
+
+// SearchProducts returns one page of the products matching the filter, the number of
+// matches and the brand and specification facets of the whole result set
+func (repository *ProductRepository) SearchProducts(ctx context.Context, filter *dtos.ProductSearchDTO) (*models.ProductSearchResult, error) {
+	result := &models.ProductSearchResult{
+		Products: []*models.ProductSearchHit{},
+		Limit:    filter.Limit,
+		Offset:   filter.Offset,
+		Facets: models.ProductFacets{
+			Brands: []models.BrandFacet{},
+			Specs:  []models.SpecFacet{},
+		},
+	}
+
+	conditions := buildSearchConditions(filter, false, false)
+	err := repository.DB.Pool.QueryRow(ctx,
+		"SELECT COUNT(*)"+productSearchFrom+" "+conditions.where(),
+		conditions.args...,
+	).Scan(&result.Total)
+	if err != nil {
+		return nil, errs.InternalError("failed to count search results", err)
+	}
+
+	if result.Total > filter.Offset {
+		products, err := repository.fetchSearchPage(ctx, filter, conditions)
+		if err != nil {
+			return nil, err
+		}
+		result.Products = products
+	}
+
+	if result.Facets.Brands, err = repository.fetchBrandFacets(ctx, filter); err != nil {
+		return nil, err
+	}
+	if result.Facets.Specs, err = repository.fetchSpecFacets(ctx, filter); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (repository *ProductRepository) fetchSearchPage(ctx context.Context, filter *dtos.ProductSearchDTO, conditions *searchConditions) ([]*models.ProductSearchHit, error) {
+	tsQuery := searchTSQuery(filter.Query)
+
+	// keyword searches also return the rank and highlighted snippets of each product
+	keywordColumns := "NULL::float8, NULL::text, NULL::text"
+	if tsQuery != "" {
+		query := fmt.Sprintf("to_tsquery('simple', %s)", conditions.param(tsQuery))
+		options := conditions.param(searchHeadlineOptions)
+		keywordColumns = fmt.Sprintf(`ts_rank(p.search_vector, %[1]s, 1)::float8,
+		       ts_headline('simple', p.name, %[1]s, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+		       ts_headline('simple', COALESCE(p.description, ''), %[1]s, %[2]s)`, query, options)
+	}
+
+	orderBy, ok := productSearchSortOrders[filter.Sort]
+	if !ok {
+		orderBy = "p.created_at DESC, p.product_id DESC"
+		if tsQuery != "" {
+			orderBy = "1 DESC, tr.review_count DESC NULLS LAST, " + orderBy
+		}
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s,
+		       p.product_id, p.category_id, p.brand_id, p.name, COALESCE(p.description, ''), p.price, p.stock_quantity,
+		       ep.original_price, ep.discount_id, ep.discount_percentage, ep.discount_amount, ep.final_price
+		%s
+		%s
+		ORDER BY %s
+		LIMIT %s OFFSET %s
+	`, keywordColumns, productSearchFrom, conditions.where(), orderBy, conditions.param(filter.Limit), conditions.param(filter.Offset))
+
+	rows, err := repository.DB.Pool.Query(ctx, query, conditions.args...)
+	if err != nil {
+		return nil, errs.InternalError("failed to search products", err)
+	}
+	defer rows.Close()
+
+	hits, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.ProductSearchHit, error) {
+		product := &models.Products{Pricing: &models.PriceBreakdown{}}
+		hit := &models.ProductSearchHit{Products: product}
+		var nameHighlight, descriptionHighlight *string
+		err := row.Scan(
+			&hit.Rank,
+			&nameHighlight,
+			&descriptionHighlight,
+			&product.ProductID,
+			&product.CategoryID,
+			&product.BrandID,
+			&product.Name,
+			&product.Description,
+			&product.Price,
+			&product.StockQuantity,
+			&product.Pricing.OriginalPrice,
+			&product.Pricing.DiscountID,
+			&product.Pricing.DiscountPercentage,
+			&product.Pricing.DiscountAmount,
+			&product.Pricing.FinalPrice,
+		)
+		if nameHighlight != nil && descriptionHighlight != nil {
+			hit.Highlight = &models.ProductHighlight{Name: *nameHighlight, Description: *descriptionHighlight}
+		}
+		return hit, err
+	})
+	if err != nil {
+		return nil, errs.InternalError("failed to scan search results", err)
+	}
+	return hits, nil
+}
+
+func (repository *ProductRepository) fetchBrandFacets(ctx context.Context, filter *dtos.ProductSearchDTO) ([]models.BrandFacet, error) {
+	conditions := buildSearchConditions(filter, true, false)
+	query := fmt.Sprintf(`
+		SELECT b.brand_id, b.name, COUNT(*)
+		%s
+		JOIN brands b ON b.brand_id = p.brand_id
+		%s
+		GROUP BY b.brand_id, b.name
+		ORDER BY COUNT(*) DESC, b.name
+	`, productSearchFrom, conditions.where())
+
+	rows, err := repository.DB.Pool.Query(ctx, query, conditions.args...)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch brand facets", err)
+	}
+	defer rows.Close()
+
+	facets, err := pgx.CollectRows(rows, pgx.RowToStructByPos[models.BrandFacet])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect brand facets", err)
+	}
+	return facets, nil
+}
+
+// fetchSpecFacets counts the values of each specification. Specs their category defines as
+// not filterable are left out; numeric specs also report the range of their values.
+func (repository *ProductRepository) fetchSpecFacets(ctx context.Context, filter *dtos.ProductSearchDTO) ([]models.SpecFacet, error) {
+	conditions := buildSearchConditions(filter, false, true)
+	conditions.add("d.filterable IS DISTINCT FROM FALSE")
+	query := fmt.Sprintf(`
+		SELECT spec_name, spec_value, product_count, min_value, max_value
+		FROM (
+			SELECT ps.spec_name, ps.spec_value, COUNT(*) AS product_count,
+			       (MIN(MIN(ps.numeric_value)) OVER (PARTITION BY ps.spec_name))::float8 AS min_value,
+			       (MAX(MAX(ps.numeric_value)) OVER (PARTITION BY ps.spec_name))::float8 AS max_value,
+			       ROW_NUMBER() OVER (PARTITION BY ps.spec_name ORDER BY COUNT(*) DESC, ps.spec_value) AS value_rank
+			%s
+			JOIN product_specifications ps ON ps.product_id = p.product_id
+			LEFT JOIN category_effective_spec_definitions d
+			       ON d.category_id = p.category_id AND d.spec_name = ps.spec_name
+			%s
+			GROUP BY ps.spec_name, ps.spec_value
+		) ranked
+		WHERE value_rank <= %s
+		ORDER BY spec_name, value_rank
+	`, productSearchFrom, conditions.where(), conditions.param(maxSpecFacetValues))
+
+	rows, err := repository.DB.Pool.Query(ctx, query, conditions.args...)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch specification facets", err)
+	}
+	defer rows.Close()
+
+	facets := []models.SpecFacet{}
+	var name, value string
+	var count int
+	var minValue, maxValue *float64
+	_, err = pgx.ForEachRow(rows, []any{&name, &value, &count, &minValue, &maxValue}, func() error {
+		if len(facets) == 0 || facets[len(facets)-1].SpecName != name {
+			facet := models.SpecFacet{SpecName: name}
+			if minValue != nil && maxValue != nil {
+				facet.Range = &models.SpecFacetRange{Min: *minValue, Max: *maxValue}
+			}
+			facets = append(facets, facet)
+		}
+		last := &facets[len(facets)-1]
+		last.Values = append(last.Values, models.SpecFacetValue{Value: value, Count: count})
+		return nil
+	})
+	if err != nil {
+		return nil, errs.InternalError("failed to collect specification facets", err)
+	}
+	return facets, nil
+}
@@ -10,8 +10,8 @@ import (
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
 	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
 	"github.com/amha-mersha/sanqa-suq/internal/models"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type ReviewRepository struct {
@@ -22,42 +22,101 @@ func NewReviewRepository(db *database.DB) *ReviewRepository {
 	return &ReviewRepository{DB: db}
 }
 
-func (repository *ReviewRepository) CreateNewReview(ctx context.Context, reviewDTO *dtos.CreateReviewDTO) (*models.Review, error) {
+// reviewSortOrders maps the supported sort options onto ORDER BY clauses; review_id
+// breaks ties so pages stay stable
+var reviewSortOrders = map[string]string{
+	"newest":  "r.review_date DESC, r.review_id",
+	"oldest":  "r.review_date ASC, r.review_id",
+	"highest": "r.rating DESC, r.review_date DESC, r.review_id",
+	"lowest":  "r.rating ASC, r.review_date DESC, r.review_id",
+	"helpful": "helpful_count DESC, r.review_date DESC, r.review_id",
+}
+
+// verifiedPurchaseCheck tells whether the author of review r has a delivered order
+// containing the reviewed product
+const verifiedPurchaseCheck = `EXISTS (
+		SELECT 1
+		FROM order_items oi
+		JOIN orders o ON o.order_id = oi.order_id
+		WHERE o.user_id = r.user_id
+		  AND oi.product_id = r.product_id
+		  AND o.status = 'delivered'
+	)`
+
+// reviewColumns selects a review aliased as r
+const reviewColumns = `
+	r.review_id, r.user_id, r.product_id, r.rating, COALESCE(r.comment, ''), r.review_date,
+	` + verifiedPurchaseCheck + `,
+	r.status, r.moderation_reason,
+	(SELECT COUNT(*) FROM review_votes v WHERE v.review_id = r.review_id) AS helpful_count`
+
+// logHeldReview records an automatic hold of the review written by the CTE r
+const logHeldReview = `
+	logged AS (
+		INSERT INTO review_moderation_log (review_id, product_id, action, reason)
+		SELECT r.review_id, r.product_id, 'held', r.moderation_reason
+		FROM r
+		WHERE r.status = 'held'
+	)`
+
+func scanReview(row pgx.Row, review *models.Review, extra ...any) error {
+	return row.Scan(append([]any{
+		&review.ReviewId,
+		&review.UserId,
+		&review.ProductId,
+		&review.Rating,
+		&review.Comment,
+		&review.ReviewDate,
+		&review.VerifiedPurchase,
+		&review.Status,
+		&review.ModerationReason,
+		&review.HelpfulCount,
+	}, extra...)...)
+}
+
+// CreateNewReview stores a review. A non-nil holdReason keeps it out of listings until a
+// moderator approves it.
+func (repository *ReviewRepository) CreateNewReview(ctx context.Context, userID string, reviewDTO *dtos.CreateReviewDTO, holdReason *string) (*models.Review, error) {
 	query := `
-		INSERT INTO reviews (
-			user_id,
-			product_id,
-			rating,
-			comment
-		) VALUES (
-			$1, $2, $3, $4
-		)
-		RETURNING review_id, user_id, product_id, rating, comment, review_date;
+		WITH r AS (
+			INSERT INTO reviews (
+				user_id,
+				product_id,
+				rating,
+				comment,
+				status,
+				moderation_reason
+			) VALUES (
+				$1, $2, $3, $4,
+				CASE WHEN $5::text IS NULL THEN 'published' ELSE 'held' END::review_status,
+				$5
+			)
+			RETURNING *
+		),` + logHeldReview + `
+		SELECT ` + reviewColumns + `
+		FROM r
 	`
 
 	newReview := &models.Review{}
 
-	err := repository.DB.Pool.QueryRow(
+	err := scanReview(repository.DB.Pool.QueryRow(
 		ctx,
 		query,
-		reviewDTO.UserID,
+		userID,
 		reviewDTO.ProductID,
 		reviewDTO.Rating,
 		reviewDTO.Comment,
-	).Scan(
-		&newReview.ReviewId,
-		&newReview.UserId,
-		&newReview.ProductId,
-		&newReview.Rating,
-		&newReview.Comment,
-		&newReview.ReviewDate,
-	)
+		holdReason,
+	), newReview)
 
 	if err != nil {
 		var pgErr *pgconn.PgError
 		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23505" {
+				return nil, errs.Conflict("REVIEW_ALREADY_EXISTS", fmt.Errorf("user already reviewed product %d; edit the existing review instead: %w", reviewDTO.ProductID, err))
+			}
 			if pgErr.Code == "23503" {
-				return nil, errs.BadRequest("FOREIGN_KEY_VIOLATION", fmt.Errorf("invalid user_id or product_id: %w", err))
+				return nil, errs.NotFound(fmt.Sprintf("product with id %d not found", reviewDTO.ProductID), err)
 			}
 		}
 		if err == pgx.ErrNoRows {
@@ -69,10 +128,12 @@ func (repository *ReviewRepository) CreateNewReview(ctx context.Context, reviewD
 	return newReview, nil
 }
 
+// UpdateReview edits a review; a non-nil holdReason sends it back to the moderation queue
 func (repository *ReviewRepository) UpdateReview(
 	ctx context.Context,
 	reviewID string,
 	dto *dtos.UpdateReviewDTO,
+	holdReason *string,
 ) (*models.Review, error) {
 	fields := map[string]any{}
 
@@ -98,23 +159,33 @@ func (repository *ReviewRepository) UpdateReview(
 		i++
 	}
 
+	logged := ""
+	if holdReason != nil {
+		setClauses = append(setClauses,
+			"status = 'held'",
+			fmt.Sprintf("moderation_reason = $%d", i),
+			"moderated_by = NULL",
+			"moderated_at = NULL",
+		)
+		args = append(args, *holdReason)
+		i++
+		logged = "," + logHeldReview
+	}
+
 	args = append(args, reviewID)
 	query := fmt.Sprintf(`
-		UPDATE reviews
-		SET %s
-		WHERE review_id = $%d
-		RETURNING review_id, user_id, product_id, rating, comment, review_date
-	`, strings.Join(setClauses, ", "), i)
+		WITH r AS (
+			UPDATE reviews
+			SET %s
+			WHERE review_id = $%d
+			RETURNING *
+		)%s
+		SELECT %s
+		FROM r
+	`, strings.Join(setClauses, ", "), i, logged, reviewColumns)
 
 	review := &models.Review{}
-	err := repository.DB.Pool.QueryRow(ctx, query, args...).Scan(
-		&review.ReviewId,
-		&review.UserId,
-		&review.ProductId,
-		&review.Rating,
-		&review.Comment,
-		&review.ReviewDate,
-	)
+	err := scanReview(repository.DB.Pool.QueryRow(ctx, query, args...), review)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -130,19 +201,12 @@ func (repository *ReviewRepository) FindReviewByID(ctx context.Context, reviewID
 	var review models.Review
 
 	query := `
-		SELECT review_id, user_id, product_id, rating, comment, review_date
-		FROM reviews
-		WHERE review_id = $1
+		SELECT ` + reviewColumns + `
+		FROM reviews r
+		WHERE r.review_id = $1
 	`
 
-	err := repository.DB.Pool.QueryRow(ctx, query, reviewID).Scan(
-		&review.ReviewId,
-		&review.UserId,
-		&review.ProductId,
-		&review.Rating,
-		&review.Comment,
-		&review.ReviewDate,
-	)
+	err := scanReview(repository.DB.Pool.QueryRow(ctx, query, reviewID), &review)
 
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -159,11 +223,281 @@ func (repository *ReviewRepository) DeleteReviewByID(ctx context.Context, review
 
 	result, err := repository.DB.Pool.Exec(ctx, query, reviewID)
 	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) || result.RowsAffected() == 0 {
+		return errs.InternalError(fmt.Sprintf("failed to delete review with id %s", reviewID), err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.NotFound(fmt.Sprintf("review with id %s not found", reviewID), nil)
+	}
+
+	return nil
+}
+
+// FetchProductReviews returns one page of a product's reviews in the requested order,
+// optionally limited to verified purchases
+func (repository *ReviewRepository) FetchProductReviews(ctx context.Context, productID int, filter *dtos.ReviewFilterDTO) ([]*models.ProductReview, error) {
+	orderBy, ok := reviewSortOrders[filter.Sort]
+	if !ok {
+		orderBy = reviewSortOrders["newest"]
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s, TRIM(CONCAT_WS(' ', u.first_name, u.last_name))
+		FROM reviews r
+		JOIN users u ON u.user_id = r.user_id
+		WHERE r.product_id = $1
+		  AND r.status = 'published'
+		  AND (NOT $4::boolean OR %s)
+		ORDER BY %s
+		LIMIT $2 OFFSET $3
+	`, reviewColumns, verifiedPurchaseCheck, orderBy)
+
+	rows, err := repository.DB.Pool.Query(ctx, query, productID, filter.Limit, filter.Offset, filter.VerifiedOnly)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch reviews for product %d", productID), err)
+	}
+	defer rows.Close()
+
+	reviews, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.ProductReview, error) {
+		review := &models.ProductReview{}
+		err := scanReview(row, &review.Review, &review.AuthorName)
+		return review, err
+	})
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to collect reviews for product %d", productID), err)
+	}
+	return reviews, nil
+}
+
+// FetchRatingSummary returns the average rating, star distribution and number of
+// verified-purchase reviews of a product.
+// A product without reviews gets an empty summary; an unknown product is not found.
+func (repository *ReviewRepository) FetchRatingSummary(ctx context.Context, productID int) (*models.RatingSummary, error) {
+	summary := &models.RatingSummary{
+		Distribution: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0},
+	}
+
+	query := `
+		SELECT p.product_id, COALESCE(ROUND(t.avg_rating, 2), 0)::float8, COALESCE(t.review_count, 0)
+		FROM products p
+		LEFT JOIN top_rated_products t ON t.product_id = p.product_id
+		WHERE p.product_id = $1
+	`
+	err := repository.DB.Pool.QueryRow(ctx, query, productID).Scan(
+		&summary.ProductID,
+		&summary.AverageRating,
+		&summary.ReviewCount,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("product with id %d not found", productID), err)
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to summarize reviews for product %d", productID), err)
+	}
+	if summary.ReviewCount == 0 {
+		return summary, nil
+	}
+
+	rows, err := repository.DB.Pool.Query(ctx, `
+		SELECT r.rating, COUNT(*), COUNT(*) FILTER (WHERE `+verifiedPurchaseCheck+`)
+		FROM reviews r
+		WHERE r.product_id = $1
+		  AND r.status = 'published'
+		GROUP BY r.rating
+	`, productID)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch rating distribution for product %d", productID), err)
+	}
+	defer rows.Close()
+
+	var rating, count, verified int
+	_, err = pgx.ForEachRow(rows, []any{&rating, &count, &verified}, func() error {
+		summary.Distribution[rating] = count
+		summary.VerifiedCount += verified
+		return nil
+	})
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to collect rating distribution for product %d", productID), err)
+	}
+	return summary, nil
+}
+
+// InsertReviewReport records a user's report of a review; each user reports a review once
+func (repository *ReviewRepository) InsertReviewReport(ctx context.Context, reviewID string, userID string, dto *dtos.ReportReviewDTO) (*models.ReviewReport, error) {
+	var note *string
+	if dto.Note != "" {
+		note = &dto.Note
+	}
+
+	report := &models.ReviewReport{}
+	err := repository.DB.Pool.QueryRow(ctx, `
+		INSERT INTO review_reports (review_id, user_id, reason, note)
+		VALUES ($1, $2, $3, $4)
+		RETURNING report_id, review_id, user_id, reason, note, created_at, resolved_at
+	`, reviewID, userID, dto.Reason, note).Scan(
+		&report.ReportID,
+		&report.ReviewID,
+		&report.UserID,
+		&report.Reason,
+		&report.Note,
+		&report.CreatedAt,
+		&report.ResolvedAt,
+	)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) {
+			if pgErr.Code == "23505" {
+				return nil, errs.Conflict("REVIEW_ALREADY_REPORTED", err)
+			}
+			if pgErr.Code == "23503" {
+				return nil, errs.NotFound(fmt.Sprintf("review with id %s not found", reviewID), err)
+			}
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to report review with id %s", reviewID), err)
+	}
+	return report, nil
+}
+
+// AddHelpfulVote marks a review as helpful for a user; voting twice has no effect
+func (repository *ReviewRepository) AddHelpfulVote(ctx context.Context, reviewID string, userID string) error {
+	_, err := repository.DB.Pool.Exec(ctx, `
+		INSERT INTO review_votes (review_id, user_id)
+		VALUES ($1, $2)
+		ON CONFLICT (review_id, user_id) DO NOTHING
+	`, reviewID, userID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
 			return errs.NotFound(fmt.Sprintf("review with id %s not found", reviewID), err)
 		}
-		return errs.InternalError(fmt.Sprintf("failed to delete review with id %s", reviewID), err)
+		return errs.InternalError(fmt.Sprintf("failed to vote for review with id %s", reviewID), err)
 	}
+	return nil
+}
 
+// RemoveHelpfulVote withdraws a user's helpful vote, if any
+func (repository *ReviewRepository) RemoveHelpfulVote(ctx context.Context, reviewID string, userID string) error {
+	_, err := repository.DB.Pool.Exec(ctx,
+		`DELETE FROM review_votes WHERE review_id = $1 AND user_id = $2`,
+		reviewID, userID,
+	)
+	if err != nil {
+		return errs.InternalError(fmt.Sprintf("failed to remove vote for review with id %s", reviewID), err)
+	}
 	return nil
 }
+
+// openReportCheck tells whether review r has reports no moderator has acted on yet
+const openReportCheck = `EXISTS (
+		SELECT 1 FROM review_reports o WHERE o.review_id = r.review_id AND o.resolved_at IS NULL
+	)`
+
+var moderationQueueConditions = map[string]string{
+	"":         "r.status = 'held' OR (r.status = 'published' AND " + openReportCheck + ")",
+	"held":     "r.status = 'held'",
+	"hidden":   "r.status = 'hidden'",
+	"reported": "r.status = 'published' AND " + openReportCheck,
+}
+
+// FetchModerationQueue lists reviews needing a moderator, most reported first and
+// otherwise oldest first
+func (repository *ReviewRepository) FetchModerationQueue(ctx context.Context, filter *dtos.ModerationQueueFilterDTO) ([]*models.ModerationQueueItem, error) {
+	query := fmt.Sprintf(`
+		SELECT %s,
+		       TRIM(CONCAT_WS(' ', u.first_name, u.last_name)),
+		       p.name,
+		       COUNT(rr.report_id),
+		       COALESCE(ARRAY_AGG(DISTINCT rr.reason::text) FILTER (WHERE rr.report_id IS NOT NULL), '{}')
+		FROM reviews r
+		JOIN users u ON u.user_id = r.user_id
+		JOIN products p ON p.product_id = r.product_id
+		LEFT JOIN review_reports rr ON rr.review_id = r.review_id AND rr.resolved_at IS NULL
+		WHERE %s
+		GROUP BY r.review_id, u.user_id, p.product_id
+		ORDER BY COUNT(rr.report_id) DESC, r.review_date ASC, r.review_id
+		LIMIT $1 OFFSET $2
+	`, reviewColumns, moderationQueueConditions[filter.Status])
+
+	rows, err := repository.DB.Pool.Query(ctx, query, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch moderation queue", err)
+	}
+	defer rows.Close()
+
+	items, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (*models.ModerationQueueItem, error) {
+		item := &models.ModerationQueueItem{}
+		err := scanReview(row, &item.Review, &item.AuthorName, &item.ProductName, &item.OpenReports, &item.ReportReasons)
+		return item, err
+	})
+	if err != nil {
+		return nil, errs.InternalError("failed to collect moderation queue", err)
+	}
+	return items, nil
+}
+
+// moderationStatuses maps moderator decisions that keep the review onto its new status
+var moderationStatuses = map[string]string{
+	models.ModerationActionApproved: models.ReviewStatusPublished,
+	models.ModerationActionHidden:   models.ReviewStatusHidden,
+}
+
+// ModerateReview applies a moderator's decision, resolves the review's open reports and
+// records the decision in the moderation log. Deleted reviews return nil.
+func (repository *ReviewRepository) ModerateReview(ctx context.Context, reviewID string, action string, reason *string, moderatorID string) (*models.Review, error) {
+	tx, err := repository.DB.Pool.Begin(ctx)
+	if err != nil {
+		return nil, errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var productID int
+	err = tx.QueryRow(ctx,
+		`SELECT product_id FROM reviews WHERE review_id = $1 FOR UPDATE`,
+		reviewID,
+	).Scan(&productID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("review with id %s not found", reviewID), err)
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to lock review with id %s", reviewID), err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO review_moderation_log (review_id, product_id, action, reason, performed_by)
+		VALUES ($1, $2, $3, $4, $5)
+	`, reviewID, productID, action, reason, moderatorID)
+	if err != nil {
+		return nil, errs.InternalError("failed to record moderation action", err)
+	}
+
+	if action == models.ModerationActionDeleted {
+		if _, err = tx.Exec(ctx, `DELETE FROM reviews WHERE review_id = $1`, reviewID); err != nil {
+			return nil, errs.InternalError(fmt.Sprintf("failed to delete review with id %s", reviewID), err)
+		}
+	} else {
+		_, err = tx.Exec(ctx, `
+			UPDATE reviews
+			SET status = $2, moderation_reason = $3, moderated_by = $4, moderated_at = CURRENT_TIMESTAMP
+			WHERE review_id = $1
+		`, reviewID, moderationStatuses[action], reason, moderatorID)
+		if err != nil {
+			return nil, errs.InternalError(fmt.Sprintf("failed to moderate review with id %s", reviewID), err)
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE review_reports
+			SET resolved_at = CURRENT_TIMESTAMP
+			WHERE review_id = $1 AND resolved_at IS NULL
+		`, reviewID)
+		if err != nil {
+			return nil, errs.InternalError(fmt.Sprintf("failed to resolve reports of review with id %s", reviewID), err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return nil, errs.InternalError("failed to commit moderation", err)
+	}
+
+	if action == models.ModerationActionDeleted {
+		return nil, nil
+	}
+	return repository.FindReviewByID(ctx, reviewID)
+}
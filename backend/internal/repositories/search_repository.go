@@ -0,0 +1,74 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type SearchRepository struct {
+	DB *database.DB
+}
+
+func NewSearchRepository(db *database.DB) *SearchRepository {
+	return &SearchRepository{DB: db}
+}
+
+// FetchSuggestions returns the products, brands and categories whose names resemble the
+// typed text, best match first. Matching uses the pg_trgm similarity operators, which are
+// served by the trigram indexes and tolerate typos; names starting with the text get a
+// bonus. productLimit and groupLimit cap the products and each of brands and categories.
+func (r *SearchRepository) FetchSuggestions(ctx context.Context, text string, productLimit int, groupLimit int) ([]*models.SearchSuggestion, error) {
+	query := `
+		WITH products_matched AS (
+			SELECT 'product' AS type, product_id AS id, name AS text,
+			       GREATEST(similarity(name, $1), word_similarity($1, name))
+			       + CASE WHEN starts_with(LOWER(name), LOWER($1)) THEN 1 ELSE 0 END AS score
+			FROM products
+			WHERE name % $1 OR $1 <% name
+			ORDER BY score DESC, name
+			LIMIT $2
+		),
+		brands_matched AS (
+			SELECT 'brand' AS type, brand_id AS id, name AS text,
+			       GREATEST(similarity(name, $1), word_similarity($1, name))
+			       + CASE WHEN starts_with(LOWER(name), LOWER($1)) THEN 1 ELSE 0 END AS score
+			FROM brands
+			WHERE name % $1 OR $1 <% name
+			ORDER BY score DESC, name
+			LIMIT $3
+		),
+		categories_matched AS (
+			SELECT 'category' AS type, category_id AS id, category_name AS text,
+			       GREATEST(similarity(category_name, $1), word_similarity($1, category_name))
+			       + CASE WHEN starts_with(LOWER(category_name), LOWER($1)) THEN 1 ELSE 0 END AS score
+			FROM categories
+			WHERE category_name % $1 OR $1 <% category_name
+			ORDER BY score DESC, category_name
+			LIMIT $3
+		)
+		SELECT type, id, text, score::float8
+		FROM (
+			SELECT * FROM categories_matched
+			UNION ALL
+			SELECT * FROM brands_matched
+			UNION ALL
+			SELECT * FROM products_matched
+		) suggestions
+		ORDER BY score DESC, text
+	`
+	rows, err := r.DB.Pool.Query(ctx, query, text, productLimit, groupLimit)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch search suggestions", err)
+	}
+	defer rows.Close()
+
+	suggestions, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.SearchSuggestion])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect search suggestions", err)
+	}
+	return suggestions, nil
+}
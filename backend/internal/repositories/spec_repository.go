@@ -0,0 +1,176 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+)
+
+type SpecRepository struct {
+	DB *database.DB
+}
+
+func NewSpecRepository(db *database.DB) *SpecRepository {
+	return &SpecRepository{DB: db}
+}
+
+// FetchCategoryDefinitions returns the specification definitions that apply to a category,
+// its own and the inherited ones; an unknown category is not found, a category without
+// definitions gets an empty list
+func (r *SpecRepository) FetchCategoryDefinitions(ctx context.Context, categoryID int) ([]*models.SpecDefinition, error) {
+	var exists bool
+	err := r.DB.Pool.QueryRow(ctx,
+		`SELECT EXISTS (SELECT 1 FROM categories WHERE category_id = $1)`,
+		categoryID,
+	).Scan(&exists)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to find category with id %d", categoryID), err)
+	}
+	if !exists {
+		return nil, errs.NotFound(fmt.Sprintf("category with id %d not found", categoryID), nil)
+	}
+
+	rows, err := r.DB.Pool.Query(ctx, `
+		SELECT category_id, spec_name, data_type::text, unit, allowed_values, required, filterable, description, defined_in
+		FROM category_effective_spec_definitions
+		WHERE category_id = $1
+		ORDER BY required DESC, spec_name
+	`, categoryID)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch spec definitions of category %d", categoryID), err)
+	}
+	defer rows.Close()
+
+	definitions, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.SpecDefinition])
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to collect spec definitions of category %d", categoryID), err)
+	}
+	return definitions, nil
+}
+
+// ReplaceCategoryDefinitions swaps the definitions a category declares itself. The numeric
+// values of the specs of every product below the category are then recomputed, so range
+// filters follow a spec that became (or stopped being) numeric. Values that do not fit the
+// new definitions are left as they are until the product's specs are next written.
+func (r *SpecRepository) ReplaceCategoryDefinitions(ctx context.Context, categoryID int, items []dtos.SpecDefinitionItemDTO) error {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var id int
+	err = tx.QueryRow(ctx, `SELECT category_id FROM categories WHERE category_id = $1 FOR UPDATE`, categoryID).Scan(&id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errs.NotFound(fmt.Sprintf("category with id %d not found", categoryID), err)
+		}
+		return errs.InternalError(fmt.Sprintf("failed to lock category with id %d", categoryID), err)
+	}
+
+	if _, err = tx.Exec(ctx, `DELETE FROM category_spec_definitions WHERE category_id = $1`, categoryID); err != nil {
+		return errs.InternalError(fmt.Sprintf("failed to clear spec definitions of category %d", categoryID), err)
+	}
+	for _, item := range items {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO category_spec_definitions
+				(category_id, spec_name, data_type, unit, allowed_values, required, filterable, description)
+			VALUES ($1, $2, $3::spec_data_type, NULLIF($4, ''), $5, $6, $7, NULLIF($8, ''))
+		`, categoryID, item.SpecName, item.DataType, item.Unit, item.AllowedValues, item.Required, item.Filterable, item.Description)
+		if err != nil {
+			return errs.InternalError(fmt.Sprintf("failed to save spec %s of category %d", item.SpecName, categoryID), err)
+		}
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE product_specifications s
+		SET numeric_value = (
+			SELECT substring(s.spec_value FROM '^\s*(-?[0-9]+(?:\.[0-9]+)?)')::numeric
+			FROM products p
+			JOIN category_effective_spec_definitions d ON d.category_id = p.category_id AND d.spec_name = s.spec_name
+			WHERE p.product_id = s.product_id AND d.data_type IN ('integer', 'decimal')
+		)
+		WHERE s.product_id IN (
+			SELECT product_id FROM products
+			WHERE category_id IN (SELECT category_id FROM category_ancestors WHERE ancestor_id = $1)
+		)
+	`, categoryID)
+	if err != nil {
+		return errs.InternalError(fmt.Sprintf("failed to refresh numeric specs below category %d", categoryID), err)
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errs.InternalError("failed to commit transaction", err)
+	}
+	return nil
+}
+
+// FetchProductSpecs returns a product's specifications keyed by name
+func (r *SpecRepository) FetchProductSpecs(ctx context.Context, productID int) (map[string]string, error) {
+	rows, err := r.DB.Pool.Query(ctx,
+		`SELECT spec_name, spec_value FROM product_specifications WHERE product_id = $1`,
+		productID,
+	)
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch specifications for product ID %d", productID), err)
+	}
+	defer rows.Close()
+
+	specs := make(map[string]string)
+	var name, value string
+	_, err = pgx.ForEachRow(rows, []any{&name, &value}, func() error {
+		specs[name] = value
+		return nil
+	})
+	if err != nil {
+		return nil, errs.InternalError(fmt.Sprintf("failed to collect specifications for product ID %d", productID), err)
+	}
+	return specs, nil
+}
+
+// SaveProductSpecs upserts and removes specifications of a product. Kept specs are updated
+// in place rather than deleted and re-inserted, because compatibility rules reference them
+// with ON DELETE CASCADE.
+func (r *SpecRepository) SaveProductSpecs(ctx context.Context, productID int, upserts map[string]models.SpecValue, removals []string) error {
+	tx, err := r.DB.Pool.Begin(ctx)
+	if err != nil {
+		return errs.InternalError("failed to begin transaction", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err = lockProduct(ctx, tx, productID); err != nil {
+		return err
+	}
+
+	if len(removals) > 0 {
+		_, err = tx.Exec(ctx,
+			`DELETE FROM product_specifications WHERE product_id = $1 AND spec_name = ANY($2)`,
+			productID, removals,
+		)
+		if err != nil {
+			return errs.InternalError(fmt.Sprintf("failed to remove specifications of product %d", productID), err)
+		}
+	}
+	for name, value := range upserts {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO product_specifications (product_id, spec_name, spec_value, numeric_value)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (product_id, spec_name)
+			DO UPDATE SET spec_value = EXCLUDED.spec_value, numeric_value = EXCLUDED.numeric_value
+		`, productID, name, value.Value, value.Numeric)
+		if err != nil {
+			return errs.InternalError(fmt.Sprintf("failed to save specification %s of product %d", name, productID), err)
+		}
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return errs.InternalError("failed to commit transaction", err)
+	}
+	return nil
+}
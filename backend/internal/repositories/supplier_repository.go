@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/database"
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type SupplierRepository struct {
+	DB *database.DB
+}
+
+func NewSupplierRepository(db *database.DB) *SupplierRepository {
+	return &SupplierRepository{DB: db}
+}
+
+func (r *SupplierRepository) FetchAllSuppliers(ctx context.Context) ([]*models.Supplier, error) {
+	query := `SELECT supplier_id, name, contact_email, phone, created_at FROM suppliers ORDER BY name`
+
+	rows, err := r.DB.Pool.Query(ctx, query)
+	if err != nil {
+		return nil, errs.InternalError("failed to fetch suppliers", err)
+	}
+	defer rows.Close()
+
+	suppliers, err := pgx.CollectRows(rows, pgx.RowToAddrOfStructByPos[models.Supplier])
+	if err != nil {
+		return nil, errs.InternalError("failed to collect suppliers", err)
+	}
+	return suppliers, nil
+}
+
+func (r *SupplierRepository) FindSupplierByID(ctx context.Context, supplierID int) (*models.Supplier, error) {
+	query := `SELECT supplier_id, name, contact_email, phone, created_at FROM suppliers WHERE supplier_id = $1`
+
+	var supplier models.Supplier
+	err := r.DB.Pool.QueryRow(ctx, query, supplierID).Scan(
+		&supplier.SupplierID,
+		&supplier.Name,
+		&supplier.ContactEmail,
+		&supplier.Phone,
+		&supplier.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("supplier with id %d not found", supplierID), err)
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to fetch supplier with id %d", supplierID), err)
+	}
+	return &supplier, nil
+}
+
+func (r *SupplierRepository) InsertSupplier(ctx context.Context, dto *dtos.CreateSupplierDTO) (*models.Supplier, error) {
+	query := `
+		INSERT INTO suppliers (name, contact_email, phone)
+		VALUES ($1, $2, $3)
+		RETURNING supplier_id, name, contact_email, phone, created_at`
+
+	var supplier models.Supplier
+	err := r.DB.Pool.QueryRow(ctx, query, dto.Name, dto.ContactEmail, dto.Phone).Scan(
+		&supplier.SupplierID,
+		&supplier.Name,
+		&supplier.ContactEmail,
+		&supplier.Phone,
+		&supplier.CreatedAt,
+	)
+	if err != nil {
+		return nil, errs.InternalError("failed to insert supplier", err)
+	}
+	return &supplier, nil
+}
+
+func (r *SupplierRepository) UpdateSupplier(ctx context.Context, supplierID int, dto *dtos.UpdateSupplierDTO) (*models.Supplier, error) {
+	setClauses := []string{}
+	args := []any{}
+	argPos := 1
+
+	if dto.Name != nil {
+		setClauses = append(setClauses, fmt.Sprintf("name = $%d", argPos))
+		args = append(args, *dto.Name)
+		argPos++
+	}
+	if dto.ContactEmail != nil {
+		setClauses = append(setClauses, fmt.Sprintf("contact_email = $%d", argPos))
+		args = append(args, *dto.ContactEmail)
+		argPos++
+	}
+	if dto.Phone != nil {
+		setClauses = append(setClauses, fmt.Sprintf("phone = $%d", argPos))
+		args = append(args, *dto.Phone)
+		argPos++
+	}
+	if len(setClauses) == 0 {
+		return nil, errs.BadRequest("NO_FIELDS_TO_UPDATE", nil)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE suppliers SET %s
+		WHERE supplier_id = $%d
+		RETURNING supplier_id, name, contact_email, phone, created_at`,
+		strings.Join(setClauses, ", "), argPos)
+	args = append(args, supplierID)
+
+	var supplier models.Supplier
+	err := r.DB.Pool.QueryRow(ctx, query, args...).Scan(
+		&supplier.SupplierID,
+		&supplier.Name,
+		&supplier.ContactEmail,
+		&supplier.Phone,
+		&supplier.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errs.NotFound(fmt.Sprintf("supplier with id %d not found", supplierID), err)
+		}
+		return nil, errs.InternalError(fmt.Sprintf("failed to update supplier with id %d", supplierID), err)
+	}
+	return &supplier, nil
+}
+
+// DeleteSupplier removes a supplier. Suppliers that still have inventory lots cannot be removed.
+func (r *SupplierRepository) DeleteSupplier(ctx context.Context, supplierID int) error {
+	result, err := r.DB.Pool.Exec(ctx, `DELETE FROM suppliers WHERE supplier_id = $1`, supplierID)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return errs.Conflict("SUPPLIER_HAS_INVENTORY", err)
+		}
+		return errs.InternalError(fmt.Sprintf("failed to delete supplier with id %d", supplierID), err)
+	}
+	if result.RowsAffected() == 0 {
+		return errs.NotFound(fmt.Sprintf("supplier with id %d not found", supplierID), nil)
+	}
+	return nil
+}
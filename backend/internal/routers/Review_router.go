@@ -2,15 +2,35 @@ package routers
 
 import (
 	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
-func NewReviewRoutes(mainRouter *gin.RouterGroup, reviewHandler *handlers.ReviewHandler) {
+func NewReviewRoutes(mainRouter *gin.RouterGroup, reviewHandler *handlers.ReviewHandler, authMiddleware *middlewares.AuthMiddleware) {
 	reviewRoute := mainRouter.Group("/review")
 
-	reviewRoute.POST("/add", reviewHandler.AddNewReview)
-	reviewRoute.PUT("/update/:id", reviewHandler.UpdateReview)
-	reviewRoute.DELETE("/remove/:id", reviewHandler.RemoveReview)
 	reviewRoute.GET("/:id", reviewHandler.GetReviewByID)
 
+	// Writing reviews requires a logged-in user; the author is taken from the token
+	authorized := reviewRoute.Group("")
+	authorized.Use(authMiddleware.AuthMiddleware())
+	{
+		authorized.POST("/add", reviewHandler.AddNewReview)
+		authorized.PUT("/update/:id", reviewHandler.UpdateReview)
+		authorized.DELETE("/remove/:id", reviewHandler.RemoveReview)
+		authorized.POST("/:id/report", reviewHandler.ReportReview)
+		authorized.POST("/:id/helpful", reviewHandler.VoteHelpful)
+		authorized.DELETE("/:id/helpful", reviewHandler.RemoveHelpfulVote)
+	}
+
+	// Moderation queue for reviews held by the blocklist or reported by users (admin only)
+	moderation := reviewRoute.Group("")
+	moderation.Use(authMiddleware.AuthMiddleware(), authMiddleware.RequireRole("admin"))
+	{
+		moderation.GET("/moderation", reviewHandler.GetModerationQueue)
+		moderation.POST("/:id/moderate", reviewHandler.ModerateReview)
+	}
+
+	// Public listing of a product's reviews with its rating summary
+	mainRouter.GET("/product/:id/reviews", reviewHandler.GetProductReviews)
 }
@@ -8,9 +8,42 @@ import (
 
 func NewBuildRoutes(router *gin.RouterGroup, buildHandler *handlers.BuildHandler, authMiddleware *middlewares.AuthMiddleware) {
 	builds := router.Group("/build")
-	builds.POST("", buildHandler.CreateBuild)
-	builds.GET("", buildHandler.GetUserBuilds)
-	builds.GET("/:id", buildHandler.GetBuildByID)
-	builds.PUT("/:id", buildHandler.UpdateBuild)
 	builds.POST("/compatible", buildHandler.GetCompatibleProducts)
+	builds.POST("/validate", buildHandler.ValidateBuild)
+	builds.GET("/gallery", buildHandler.GetPublicBuilds)
+
+	// Shared builds are readable by anyone; private ones only by their owner
+	viewable := builds.Group("")
+	viewable.Use(authMiddleware.OptionalAuthMiddleware())
+	{
+		viewable.GET("/shared/:slug", buildHandler.GetSharedBuild)
+		viewable.GET("/:id", buildHandler.GetBuildByID)
+		viewable.GET("/:id/analysis", buildHandler.GetBuildAnalysis)
+		viewable.GET("/:id/purchase-check", buildHandler.GetBuildPurchaseCheck)
+	}
+
+	owned := builds.Group("")
+	owned.Use(authMiddleware.AuthMiddleware())
+	{
+		owned.POST("", buildHandler.CreateBuild)
+		owned.GET("", buildHandler.GetUserBuilds)
+		owned.POST("/complete", buildHandler.CompleteBuild)
+		owned.PUT("/:id", buildHandler.UpdateBuild)
+		owned.DELETE("/:id", buildHandler.DeleteBuild)
+		owned.POST("/:id/items", buildHandler.AddBuildItem)
+		owned.PUT("/:id/items/:product_id", buildHandler.SetBuildItemQuantity)
+		owned.DELETE("/:id/items/:product_id", buildHandler.RemoveBuildItem)
+		owned.GET("/:id/revisions", buildHandler.GetBuildRevisions)
+		owned.GET("/:id/revisions/:revision", buildHandler.GetBuildRevision)
+		owned.POST("/:id/revisions/:revision/restore", buildHandler.RestoreBuildRevision)
+		owned.PUT("/:id/visibility", buildHandler.SetBuildVisibility)
+		owned.POST("/:id/clone", buildHandler.CloneBuild)
+		owned.POST("/:id/add-to-cart", buildHandler.AddBuildToCart)
+		owned.GET("/:id/price-history", buildHandler.GetBuildPriceHistory)
+		owned.PUT("/:id/price-alert", buildHandler.SetBuildPriceAlert)
+		owned.DELETE("/:id/price-alert", buildHandler.DeleteBuildPriceAlert)
+	}
+
+	// Totals follow price edits on their own; this catches discounts starting or ending
+	builds.POST("/refresh-prices", authMiddleware.AuthMiddleware(), authMiddleware.RequireRole("admin"), buildHandler.RefreshBuildTotals)
 }
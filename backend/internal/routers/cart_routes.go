@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func NewCartRoutes(router *gin.RouterGroup, cartHandler *handlers.CartHandler, authMiddleware *middlewares.AuthMiddleware) {
+	cart := router.Group("/cart")
+	cart.Use(authMiddleware.AuthMiddleware())
+	cart.GET("", cartHandler.GetCart)
+	cart.DELETE("", cartHandler.ClearCart)
+	cart.POST("/items", cartHandler.AddItem)
+	cart.PUT("/items/:product_id", cartHandler.UpdateItemQuantity)
+	cart.DELETE("/items/:product_id", cartHandler.RemoveItem)
+	cart.POST("/merge", cartHandler.MergeCart)
+}
@@ -0,0 +1,11 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func NewCheckoutRoutes(router *gin.RouterGroup, checkoutHandler *handlers.CheckoutHandler, authMiddleware *middlewares.AuthMiddleware) {
+	router.POST("/checkout", authMiddleware.AuthMiddleware(), checkoutHandler.Checkout)
+}
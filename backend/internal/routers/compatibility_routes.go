@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// Register compatibility rule routes; rules are managed by admins
+func NewCompatibilityRoutes(mainRouter *gin.RouterGroup, compatibilityHandler *handlers.CompatibilityHandler, authMiddleware *middlewares.AuthMiddleware) {
+	rules := mainRouter.Group("/compatibility-rule")
+	rules.Use(authMiddleware.AuthMiddleware(), authMiddleware.RequireRole("admin"))
+	{
+		rules.GET("", compatibilityHandler.GetRules)           // GET    /compatibility-rule
+		rules.GET("/:id", compatibilityHandler.GetRule)        // GET    /compatibility-rule/:id
+		rules.POST("", compatibilityHandler.CreateRule)        // POST   /compatibility-rule
+		rules.DELETE("/:id", compatibilityHandler.DeleteRule)  // DELETE /compatibility-rule/:id
+		rules.POST("/:id/test", compatibilityHandler.TestRule) // POST   /compatibility-rule/:id/test
+	}
+}
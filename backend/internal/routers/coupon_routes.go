@@ -0,0 +1,22 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// Register coupon management routes (admin only). Customers use coupons through
+// GET /cart?coupon_code= and the coupon_code field of POST /checkout.
+func NewCouponRoutes(mainRouter *gin.RouterGroup, couponHandler *handlers.CouponHandler, authMiddleware *middlewares.AuthMiddleware) {
+	coupon := mainRouter.Group("/coupon")
+	coupon.Use(authMiddleware.AuthMiddleware(), authMiddleware.RequireRole("admin"))
+	{
+		coupon.GET("", couponHandler.GetCoupons)                     // GET    /coupon
+		coupon.GET("/:id", couponHandler.GetCoupon)                  // GET    /coupon/:id
+		coupon.GET("/:id/redemptions", couponHandler.GetRedemptions) // GET    /coupon/:id/redemptions
+		coupon.POST("", couponHandler.CreateCoupon)                  // POST   /coupon
+		coupon.PUT("/:id", couponHandler.UpdateCoupon)               // PUT    /coupon/:id
+		coupon.DELETE("/:id", couponHandler.DeleteCoupon)            // DELETE /coupon/:id
+	}
+}
@@ -0,0 +1,20 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// Register discount routes; discounts are managed by sellers and admins
+func NewDiscountRoutes(mainRouter *gin.RouterGroup, discountHandler *handlers.DiscountHandler, authMiddleware *middlewares.AuthMiddleware) {
+	discount := mainRouter.Group("/discount")
+	discount.Use(authMiddleware.AuthMiddleware(), authMiddleware.RequireAnyRole("seller", "admin"))
+	{
+		discount.GET("", discountHandler.GetDiscounts)          // GET    /discount
+		discount.GET("/:id", discountHandler.GetDiscount)       // GET    /discount/:id
+		discount.POST("", discountHandler.CreateDiscount)       // POST   /discount
+		discount.PUT("/:id", discountHandler.UpdateDiscount)    // PUT    /discount/:id
+		discount.DELETE("/:id", discountHandler.DeleteDiscount) // DELETE /discount/:id
+	}
+}
@@ -0,0 +1,29 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// Register supplier and inventory routes; stock is managed by sellers and admins only
+func NewInventoryRoutes(mainRouter *gin.RouterGroup, supplierHandler *handlers.SupplierHandler, inventoryHandler *handlers.InventoryHandler, authMiddleware *middlewares.AuthMiddleware) {
+	supplier := mainRouter.Group("/supplier")
+	supplier.Use(authMiddleware.AuthMiddleware(), authMiddleware.RequireAnyRole("seller", "admin"))
+	{
+		supplier.GET("", supplierHandler.GetSuppliers)                                               // GET    /supplier
+		supplier.GET("/:id", supplierHandler.GetSupplier)                                            // GET    /supplier/:id
+		supplier.POST("", supplierHandler.CreateSupplier)                                            // POST   /supplier
+		supplier.PUT("/:id", supplierHandler.UpdateSupplier)                                         // PUT    /supplier/:id
+		supplier.DELETE("/:id", authMiddleware.RequireRole("admin"), supplierHandler.DeleteSupplier) // DELETE /supplier/:id
+	}
+
+	inventory := mainRouter.Group("/inventory")
+	inventory.Use(authMiddleware.AuthMiddleware(), authMiddleware.RequireAnyRole("seller", "admin"))
+	{
+		inventory.POST("/product/:id/receive", inventoryHandler.ReceiveStock) // POST /inventory/product/:id/receive
+		inventory.POST("/product/:id/adjust", inventoryHandler.AdjustStock)   // POST /inventory/product/:id/adjust
+		inventory.GET("/product/:id/ledger", inventoryHandler.GetStockLedger) // GET  /inventory/product/:id/ledger
+		inventory.GET("/low-stock", inventoryHandler.GetLowStockProducts)     // GET  /inventory/low-stock
+	}
+}
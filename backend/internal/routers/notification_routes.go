@@ -0,0 +1,16 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+func NewNotificationRoutes(router *gin.RouterGroup, notificationHandler *handlers.NotificationHandler, authMiddleware *middlewares.AuthMiddleware) {
+	notifications := router.Group("/notifications")
+	notifications.Use(authMiddleware.AuthMiddleware())
+	{
+		notifications.GET("", notificationHandler.GetUserNotifications)          // GET /notifications
+		notifications.PUT("/:id/read", notificationHandler.MarkNotificationRead) // PUT /notifications/:id/read
+	}
+}
@@ -2,21 +2,28 @@ package routers
 
 import (
 	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
 	"github.com/gin-gonic/gin"
 )
 
 // Register order-related routes onto the main router group
-func NewOrderRoutes(mainRouter *gin.RouterGroup, orderHandler *handlers.OrderHandler) {
-	// Group routes under /order
+func NewOrderRoutes(mainRouter *gin.RouterGroup, orderHandler *handlers.OrderHandler, authMiddleware *middlewares.AuthMiddleware) {
+	// Group routes under /order; every order route requires a logged-in user
 	order := mainRouter.Group("/order")
+	order.Use(authMiddleware.AuthMiddleware())
 	{
-		order.POST("/add", orderHandler.AddNewOrder)              // POST   /order/add
-		order.GET(":id", orderHandler.GetOrder)                   // GET    /order/:id
-		order.PUT("/update/:id", orderHandler.UpdateOrder)        // PUT    /order/update/:id
-		order.DELETE("/remove/:id", orderHandler.RemoveOrder)     // DELETE /order/remove/:id
-		order.GET("/user/:user_id", orderHandler.GetOrdersByUser) // GET    /order/user/:user_id
+		order.GET("", orderHandler.GetMyOrders)                       // GET    /order
+		order.GET("/:id", orderHandler.GetOrder)                      // GET    /order/:id
+		order.GET("/user/:user_id", orderHandler.GetOrdersByUser)     // GET    /order/user/:user_id
+		order.PATCH("/:id/status", orderHandler.UpdateOrderStatus)    // PATCH  /order/:id/status
+		order.GET("/:id/history", orderHandler.GetOrderStatusHistory) // GET    /order/:id/history
+
+		// Orders are normally created through POST /checkout; direct manipulation is admin only
+		order.POST("/add", authMiddleware.RequireRole("admin"), orderHandler.AddNewOrder)          // POST   /order/add
+		order.PUT("/update/:id", authMiddleware.RequireRole("admin"), orderHandler.UpdateOrder)    // PUT    /order/update/:id
+		order.DELETE("/remove/:id", authMiddleware.RequireRole("admin"), orderHandler.RemoveOrder) // DELETE /order/remove/:id
 	}
 
-	// Fetch all orders
-	mainRouter.GET("/orders", orderHandler.GetAllOrders) // GET /orders
+	// Fetch and filter all orders (admin only)
+	mainRouter.GET("/orders", authMiddleware.AuthMiddleware(), authMiddleware.RequireRole("admin"), orderHandler.GetAllOrders) // GET /orders
 }
@@ -0,0 +1,25 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// Register payment routes onto the main router group
+func NewPaymentRoutes(mainRouter *gin.RouterGroup, paymentHandler *handlers.PaymentHandler, authMiddleware *middlewares.AuthMiddleware) {
+	payment := mainRouter.Group("/payment")
+	{
+		order := payment.Group("/order")
+		order.Use(authMiddleware.AuthMiddleware())
+		{
+			order.GET("/:id", paymentHandler.GetPayment)                                                 // GET  /payment/order/:id
+			order.POST("/:id/initiate", paymentHandler.InitiatePayment)                                  // POST /payment/order/:id/initiate
+			order.POST("/:id/verify", paymentHandler.VerifyPayment)                                      // POST /payment/order/:id/verify
+			order.POST("/:id/refund", authMiddleware.RequireRole("admin"), paymentHandler.RefundPayment) // POST /payment/order/:id/refund
+		}
+
+		// Called by the providers themselves; authenticated by the request signature instead of a JWT
+		payment.POST("/callback/:provider", paymentHandler.HandleCallback) // POST /payment/callback/:provider
+	}
+}
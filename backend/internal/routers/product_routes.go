@@ -14,4 +14,6 @@ func NewProductRoutes(mainRouter *gin.RouterGroup, productHanlder *handlers.Prod
 	productRoute.GET("/:id", productHanlder.GetProduct)
 	productRoute.GET("/specs/:id", productHanlder.GetProductSpecifications)
 	productRoute.GET("", productHanlder.GetAllProducts)
+
+	mainRouter.GET("/products/search", productHanlder.SearchProducts)
 }
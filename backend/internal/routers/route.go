@@ -8,6 +8,7 @@ import (
 	"github.com/amha-mersha/sanqa-suq/internal/database"
 	"github.com/amha-mersha/sanqa-suq/internal/handlers"
 	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/amha-mersha/sanqa-suq/internal/payments"
 	"github.com/amha-mersha/sanqa-suq/internal/repositories"
 	"github.com/amha-mersha/sanqa-suq/internal/services"
 	"github.com/gin-gonic/gin"
@@ -61,15 +62,108 @@ func NewRoute(config *configs.Config, rtr *gin.Engine) error {
 	brandHandler := handlers.NewBrandHandler(brandService)
 	NewBrandRoutes(apiRouter, brandHandler)
 
-	buildRepo := repositories.NewBuildRepository(db)
-	buildService := services.NewBuildService(buildRepo)
-	buildHandler := handlers.NewBuildHandler(buildService)
-	NewBuildRoutes(apiRouter, buildHandler, authMiddleware)
-
 	addressRepo := repositories.NewAddressRepository(db)
 	addressService := services.NewAddressService(addressRepo)
 	addressHandler := handlers.NewAddressHandler(addressService)
 	NewAddressRoutes(apiRouter, addressHandler, authMiddleware)
 
+	couponRepo := repositories.NewCouponRepository(db)
+	couponService := services.NewCouponService(couponRepo)
+	couponHandler := handlers.NewCouponHandler(couponService)
+	NewCouponRoutes(apiRouter, couponHandler, authMiddleware)
+
+	cartRepo := repositories.NewCartRepository(db)
+	cartService := services.NewCartService(cartRepo, couponRepo)
+	cartHandler := handlers.NewCartHandler(cartService)
+	NewCartRoutes(apiRouter, cartHandler, authMiddleware)
+
+	compatibilityRepo := repositories.NewCompatibilityRepository(db)
+	buildRepo := repositories.NewBuildRepository(db)
+	buildService := services.NewBuildService(buildRepo, compatibilityRepo, cartService)
+	buildHandler := handlers.NewBuildHandler(buildService)
+	NewBuildRoutes(apiRouter, buildHandler, authMiddleware)
+
+	notificationRepo := repositories.NewNotificationRepository(db)
+	notificationService := services.NewNotificationService(notificationRepo)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	NewNotificationRoutes(apiRouter, notificationHandler, authMiddleware)
+
+	orderRepo := repositories.NewOrderRepository(db)
+	checkoutService := services.NewCheckoutService(orderRepo, cartRepo, buildService, addressRepo)
+	checkoutHandler := handlers.NewCheckoutHandler(checkoutService)
+	NewCheckoutRoutes(apiRouter, checkoutHandler, authMiddleware)
+
+	orderService := services.NewOrderService(orderRepo)
+	orderHandler := handlers.NewOrderHandler(orderService)
+	NewOrderRoutes(apiRouter, orderHandler, authMiddleware)
+
+	paymentRepo := repositories.NewPaymentRepository(db)
+	paymentService := services.NewPaymentService(
+		paymentRepo,
+		orderRepo,
+		newPaymentRegistry(config),
+		fmt.Sprintf("%s/api/%s/payment/callback", config.PublicURL, config.Version),
+		fmt.Sprintf("%s/orders", config.FrontendURL),
+	)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	NewPaymentRoutes(apiRouter, paymentHandler, authMiddleware)
+
+	supplierRepo := repositories.NewSupplierRepository(db)
+	supplierService := services.NewSupplierService(supplierRepo)
+	supplierHandler := handlers.NewSupplierHandler(supplierService)
+	inventoryRepo := repositories.NewInventoryRepository(db)
+	inventoryService := services.NewInventoryService(inventoryRepo)
+	inventoryHandler := handlers.NewInventoryHandler(inventoryService)
+	NewInventoryRoutes(apiRouter, supplierHandler, inventoryHandler, authMiddleware)
+
+	discountRepo := repositories.NewDiscountRepository(db)
+	discountService := services.NewDiscountService(discountRepo)
+	discountHandler := handlers.NewDiscountHandler(discountService)
+	NewDiscountRoutes(apiRouter, discountHandler, authMiddleware)
+
+	reviewRepo := repositories.NewReviewRepository(db)
+	reviewService := services.NewReviewService(reviewRepo, config.ReviewBlocklist)
+	reviewHandler := handlers.NewReviewHandler(reviewService)
+	NewReviewRoutes(apiRouter, reviewHandler, authMiddleware)
+
+	searchRepo := repositories.NewSearchRepository(db)
+	searchService := services.NewSearchService(searchRepo)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	NewSearchRoutes(apiRouter, searchHandler)
+
+	specRepo := repositories.NewSpecRepository(db)
+	specService := services.NewSpecService(specRepo, prodRepo)
+	specHandler := handlers.NewSpecHandler(specService)
+	NewSpecRoutes(apiRouter, specHandler, authMiddleware)
+
+	compatibilityService := services.NewCompatibilityService(compatibilityRepo)
+	compatibilityHandler := handlers.NewCompatibilityHandler(compatibilityService)
+	NewCompatibilityRoutes(apiRouter, compatibilityHandler, authMiddleware)
+
 	return nil
 }
+
+// newPaymentRegistry returns the real gateways in live mode and in-memory fakes otherwise
+func newPaymentRegistry(config *configs.Config) *payments.Registry {
+	if config.PaymentMode == "live" {
+		return payments.NewRegistry(
+			payments.NewTelebirrProvider(payments.TelebirrConfig{
+				BaseURL:      config.TelebirrBaseURL,
+				AppID:        config.TelebirrAppID,
+				AppKey:       config.TelebirrAppKey,
+				MerchantCode: config.TelebirrMerchantCode,
+			}),
+			payments.NewCBEBirrProvider(payments.CBEBirrConfig{
+				BaseURL:    config.CBEBirrBaseURL,
+				MerchantID: config.CBEBirrMerchantID,
+				APIKey:     config.CBEBirrAPIKey,
+			}),
+		)
+	}
+
+	fakeCheckoutURL := fmt.Sprintf("%s/payment/fake-checkout", config.FrontendURL)
+	return payments.NewRegistry(
+		payments.NewFakeProvider("telebirr", fakeCheckoutURL),
+		payments.NewFakeProvider("cbe_banking", fakeCheckoutURL),
+	)
+}
@@ -0,0 +1,14 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// Register search routes; suggestions are public so the search box works for guests
+func NewSearchRoutes(mainRouter *gin.RouterGroup, searchHandler *handlers.SearchHandler) {
+	search := mainRouter.Group("/search")
+	{
+		search.GET("/suggest", searchHandler.Suggest) // GET /search/suggest
+	}
+}
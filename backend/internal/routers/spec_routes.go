@@ -0,0 +1,28 @@
+package routers
+
+import (
+	"github.com/amha-mersha/sanqa-suq/internal/handlers"
+	"github.com/amha-mersha/sanqa-suq/internal/middlewares"
+	"github.com/gin-gonic/gin"
+)
+
+// Register specification routes; specs are public to read and written by sellers and
+// admins, while category spec definitions are managed by admins
+func NewSpecRoutes(mainRouter *gin.RouterGroup, specHandler *handlers.SpecHandler, authMiddleware *middlewares.AuthMiddleware) {
+	productSpecs := mainRouter.Group("/product/:id/specs")
+	{
+		productSpecs.GET("", specHandler.GetProductSpecs) // GET    /product/:id/specs
+
+		write := productSpecs.Group("")
+		write.Use(authMiddleware.AuthMiddleware(), authMiddleware.RequireAnyRole("seller", "admin"))
+		write.PUT("", specHandler.ReplaceProductSpecs)        // PUT    /product/:id/specs
+		write.PATCH("", specHandler.UpdateProductSpecs)       // PATCH  /product/:id/specs
+		write.DELETE("/:name", specHandler.RemoveProductSpec) // DELETE /product/:id/specs/:name
+	}
+
+	definitions := mainRouter.Group("/categories/:id/spec-definitions")
+	{
+		definitions.GET("", specHandler.GetCategoryDefinitions)                                                                       // GET /categories/:id/spec-definitions
+		definitions.PUT("", authMiddleware.AuthMiddleware(), authMiddleware.RequireRole("admin"), specHandler.SetCategoryDefinitions) // PUT /categories/:id/spec-definitions
+	}
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+)
+
+const (
+	defaultCompletionCount = 3
+	// completionBeamWidth is how many partial builds the search keeps after each category
+	completionBeamWidth = 25
+)
+
+// completionState is a partial build plus the products added to it so far
+type completionState struct {
+	components []*models.BuildComponent
+	added      []buildCandidate
+	price      float64
+	blocking   map[string]bool
+	score      float64
+}
+
+// CompleteBuild fills the empty requested categories of a build within budget
+func (s *BuildService) CompleteBuild(ctx context.Context, req *dtos.CompleteBuildRequestDTO) ([]models.BuildCompletion, error) {
+	if req.Preference == "" {
+		req.Preference = models.BuildPreferenceBalanced
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultCompletionCount
+	}
+
+	quantities := make(map[int]int, len(req.Items))
+	for _, item := range req.Items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	components, err := fetchBuildComponents(ctx, s.compatibilityRepo, quantities)
+	if err != nil {
+		return nil, err
+	}
+	productIDs := make([]int, 0, len(quantities))
+	for productID := range quantities {
+		productIDs = append(productIDs, productID)
+	}
+	prices, err := s.buildRepo.GetProductPrices(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	partialPrice := 0.0
+	for productID, quantity := range quantities {
+		partialPrice += prices[productID] * float64(quantity)
+	}
+
+	categories := []int{}
+	for _, categoryID := range req.CategoryIDs {
+		covered := slices.ContainsFunc(components, func(component *models.BuildComponent) bool {
+			return slices.Contains(component.CategoryIDs, categoryID)
+		})
+		if !covered && !slices.Contains(categories, categoryID) {
+			categories = append(categories, categoryID)
+		}
+	}
+	if len(categories) == 0 {
+		return nil, errs.BadRequest("NOTHING_TO_COMPLETE", fmt.Errorf("the build already has an item in every requested category"))
+	}
+
+	candidates := make([][]buildCandidate, len(categories))
+	for i, categoryID := range categories {
+		if candidates[i], err = s.loadCandidates(ctx, categoryID); err != nil {
+			return nil, err
+		}
+		if len(candidates[i]) == 0 {
+			return nil, errs.UnprocessableEntity("NO_PRODUCTS_IN_CATEGORY", fmt.Errorf("category %d has no products", categoryID))
+		}
+	}
+	// cheapestRest[i] is the least the categories from i on can cost; candidates come
+	// cheapest first
+	cheapestRest := make([]float64, len(categories)+1)
+	for i := len(categories) - 1; i >= 0; i-- {
+		cheapestRest[i] = cheapestRest[i+1] + candidates[i][0].product.Pricing.FinalPrice
+	}
+	if partialPrice+cheapestRest[0] > req.Budget {
+		appErr := errs.UnprocessableEntity("BUDGET_TOO_LOW", nil)
+		appErr.Meta = map[string]any{"minimum_total": math.Round((partialPrice+cheapestRest[0])*100) / 100}
+		return nil, appErr
+	}
+
+	rules, err := s.compatibilityRepo.FetchRules(ctx, &dtos.CompatibilityRuleFilterDTO{})
+	if err != nil {
+		return nil, err
+	}
+
+	// Beam search: categories are filled in turn, keeping the best partial builds, and a
+	// product is only added if it breaks no error rule the build did not already break.
+	// Price is the only measure of performance the catalogue has.
+	share := (req.Budget - partialPrice) / float64(len(categories))
+	beam := []*completionState{{
+		components: components,
+		price:      partialPrice,
+		blocking:   blockingViolations(rules, evaluateRules(rules, components)),
+	}}
+	for i, categoryID := range categories {
+		next := []*completionState{}
+		for _, state := range beam {
+			for _, candidate := range candidates[i] {
+				price := state.price + candidate.product.Pricing.FinalPrice
+				if price+cheapestRest[i+1] > req.Budget {
+					break
+				}
+				component := *candidate.component
+				component.Quantity = 1
+				grown := append(slices.Clone(state.components), &component)
+				blocking := blockingViolations(rules, evaluateRules(rules, grown))
+				if introducesViolation(state.blocking, blocking) {
+					continue
+				}
+				next = append(next, &completionState{
+					components: grown,
+					added:      append(slices.Clone(state.added), candidate),
+					price:      price,
+					blocking:   blocking,
+					score:      state.score + completionScore(req.Preference, candidate.product.Pricing.FinalPrice, share),
+				})
+			}
+		}
+		if len(next) == 0 {
+			return nil, errs.UnprocessableEntity("NO_COMPATIBLE_COMPLETION", fmt.Errorf("no product of category %d fits the build within budget", categoryID))
+		}
+
+		// builds breaking fewer error rules go first, so a product that satisfies a rule
+		// waiting for it (e.g. a motherboard for the CPU socket) wins over one that does not
+		sort.SliceStable(next, func(a, b int) bool {
+			if len(next[a].blocking) != len(next[b].blocking) {
+				return len(next[a].blocking) < len(next[b].blocking)
+			}
+			return next[a].score < next[b].score
+		})
+		beam = next[:min(len(next), completionBeamWidth)]
+	}
+
+	completions := make([]models.BuildCompletion, 0, req.Limit)
+	for _, state := range beam[:min(len(beam), req.Limit)] {
+		completion := models.BuildCompletion{
+			Rank:       len(completions) + 1,
+			Items:      make([]models.CompletionItem, 0, len(state.components)),
+			TotalPrice: math.Round(state.price*100) / 100,
+			Validation: summarizeRuleResults(evaluateRules(rules, state.components)),
+		}
+		for _, component := range components {
+			completion.Items = append(completion.Items, models.CompletionItem{
+				ProductID:   component.ProductID,
+				ProductName: component.Name,
+				CategoryID:  component.CategoryID,
+				Quantity:    component.Quantity,
+				Price:       prices[component.ProductID],
+			})
+		}
+		for _, candidate := range state.added {
+			completion.Items = append(completion.Items, models.CompletionItem{
+				ProductID:   candidate.product.ProductID,
+				ProductName: candidate.product.ProductName,
+				CategoryID:  candidate.component.CategoryID,
+				Quantity:    1,
+				Price:       candidate.product.Pricing.FinalPrice,
+				Added:       true,
+			})
+		}
+		completions = append(completions, completion)
+	}
+	return completions, nil
+}
+
+// completionScore rates one added product for the preference; lower is better
+func completionScore(preference string, price float64, share float64) float64 {
+	switch preference {
+	case models.BuildPreferenceCheapest:
+		return price
+	case models.BuildPreferencePerformance:
+		return -price
+	}
+	return math.Abs(price - share)
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+)
+
+// GetBuildPriceHistory returns how the total of a build of the user changed over time,
+// along with its price alert if one is set
+func (s *BuildService) GetBuildPriceHistory(ctx context.Context, buildID string, userID string) (*models.BuildPriceHistory, error) {
+	build, err := s.GetBuildByID(ctx, buildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if build.UserID != userID {
+		return nil, errs.Forbidden("you can only view the history of your own builds", nil)
+	}
+	history, err := s.buildRepo.GetBuildPriceHistory(ctx, buildID)
+	if err != nil {
+		return nil, err
+	}
+	alert, err := s.buildRepo.GetBuildPriceAlert(ctx, buildID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.BuildPriceHistory{
+		BuildID:      build.BuildID,
+		CurrentTotal: build.TotalPrice,
+		Alert:        alert,
+		History:      history,
+	}, nil
+}
+
+// SetBuildPriceAlert asks for a notification when the total of a build of the user drops
+// below the threshold
+func (s *BuildService) SetBuildPriceAlert(ctx context.Context, buildID string, userID string, req *dtos.SetBuildPriceAlertDTO) (*models.BuildPriceAlert, error) {
+	return s.buildRepo.SetBuildPriceAlert(ctx, buildID, userID, req.Threshold)
+}
+
+func (s *BuildService) DeleteBuildPriceAlert(ctx context.Context, buildID string, userID string) error {
+	return s.buildRepo.DeleteBuildPriceAlert(ctx, buildID, userID)
+}
+
+// RefreshBuildTotals brings every build total up to date with current prices, notifying
+// owners whose alert threshold is crossed, and returns how many totals changed
+func (s *BuildService) RefreshBuildTotals(ctx context.Context) (int, error) {
+	return s.buildRepo.RefreshBuildTotals(ctx)
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"math"
+	"slices"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+)
+
+// CheckBuildPurchase compares a build the user can see with current prices and stock
+func (s *BuildService) CheckBuildPurchase(ctx context.Context, buildID string, userID string, substitutes bool) (*dtos.BuildPurchaseCheckDTO, error) {
+	build, err := s.GetBuildByID(ctx, buildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.checkPurchase(ctx, build, substitutes)
+}
+
+// checkPurchase reports the items of a build that are short on stock or whose price
+// changed since the build was saved. With substitutes, each item short on stock gets the
+// cheapest in-stock products of its category that break no error rule in its place.
+func (s *BuildService) checkPurchase(ctx context.Context, build *models.BuildWithItems, substitutes bool) (*dtos.BuildPurchaseCheckDTO, error) {
+	check := &dtos.BuildPurchaseCheckDTO{
+		BuildID: build.BuildID,
+		Items:   make([]dtos.BuildPurchaseItemDTO, len(build.Items)),
+	}
+	for i, item := range build.Items {
+		line := dtos.BuildPurchaseItemDTO{
+			ProductID:     item.ProductID,
+			ProductName:   item.ProductName,
+			Quantity:      item.Quantity,
+			SavedPrice:    item.SavedPrice,
+			CurrentPrice:  item.CurrentPrice,
+			PriceChange:   math.Round((item.CurrentPrice-item.SavedPrice)*100) / 100,
+			StockQuantity: item.StockQuantity,
+			InStock:       item.StockQuantity >= item.Quantity,
+		}
+		if item.StockQuantity == 0 {
+			line.StockWarning = "OUT_OF_STOCK"
+		} else if item.StockQuantity < item.Quantity {
+			line.StockWarning = "INSUFFICIENT_STOCK"
+		}
+		if !line.InStock {
+			check.HasStockIssues = true
+		}
+		if line.PriceChange != 0 {
+			check.Repriced = true
+		}
+		check.SavedTotal += item.SavedPrice * float64(item.Quantity)
+		check.CurrentTotal += item.CurrentPrice * float64(item.Quantity)
+		check.Items[i] = line
+	}
+	check.SavedTotal = math.Round(check.SavedTotal*100) / 100
+	check.CurrentTotal = math.Round(check.CurrentTotal*100) / 100
+
+	if !substitutes || !check.HasStockIssues {
+		return check, nil
+	}
+
+	quantities := make(map[int]int, len(build.Items))
+	for _, item := range build.Items {
+		quantities[item.ProductID] = item.Quantity
+	}
+	components, err := fetchBuildComponents(ctx, s.compatibilityRepo, quantities)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.compatibilityRepo.FetchRules(ctx, &dtos.CompatibilityRuleFilterDTO{})
+	if err != nil {
+		return nil, err
+	}
+	baseline := blockingViolations(rules, evaluateRules(rules, components))
+	candidates := make(map[int][]buildCandidate)
+	for i := range check.Items {
+		line := &check.Items[i]
+		if line.InStock {
+			continue
+		}
+		index := slices.IndexFunc(components, func(component *models.BuildComponent) bool {
+			return component.ProductID == line.ProductID
+		})
+		replaced := components[index]
+		categoryCandidates, ok := candidates[replaced.CategoryID]
+		if !ok {
+			if categoryCandidates, err = s.loadCandidates(ctx, replaced.CategoryID); err != nil {
+				return nil, err
+			}
+			candidates[replaced.CategoryID] = categoryCandidates
+		}
+
+		line.Substitutes = []dtos.BuildSubstituteDTO{}
+		for _, candidate := range categoryCandidates {
+			if len(line.Substitutes) == maxBuildAlternatives {
+				break
+			}
+			if candidate.product.StockQuantity < line.Quantity || quantities[candidate.product.ProductID] > 0 {
+				continue
+			}
+			swapped := slices.Clone(components)
+			component := *candidate.component
+			component.Quantity = line.Quantity
+			swapped[index] = &component
+			if introducesViolation(baseline, blockingViolations(rules, evaluateRules(rules, swapped))) {
+				continue
+			}
+			line.Substitutes = append(line.Substitutes, dtos.BuildSubstituteDTO{
+				ProductID:     candidate.product.ProductID,
+				ProductName:   candidate.product.ProductName,
+				Price:         candidate.product.Pricing.FinalPrice,
+				StockQuantity: candidate.product.StockQuantity,
+			})
+		}
+	}
+	return check, nil
+}
+
+// AddBuildToCart adds the items of a build the user can see to their cart. Items short on
+// stock are left out; if none is left the request is a conflict carrying the check.
+func (s *BuildService) AddBuildToCart(ctx context.Context, buildID string, userID string, substitutes bool) (*dtos.BuildToCartResponseDTO, error) {
+	check, err := s.CheckBuildPurchase(ctx, buildID, userID, substitutes)
+	if err != nil {
+		return nil, err
+	}
+
+	merge := &dtos.MergeCartDTO{Items: []dtos.CartItemDTO{}}
+	for _, line := range check.Items {
+		if line.InStock {
+			merge.Items = append(merge.Items, dtos.CartItemDTO{ProductID: line.ProductID, Quantity: line.Quantity})
+		}
+	}
+	if len(merge.Items) == 0 {
+		appErr := errs.Conflict("BUILD_ITEMS_UNAVAILABLE", nil)
+		appErr.Meta = map[string]any{"check": check}
+		return nil, appErr
+	}
+
+	cart, err := s.cartService.MergeCart(ctx, userID, merge)
+	if err != nil {
+		return nil, err
+	}
+	return &dtos.BuildToCartResponseDTO{Check: check, Cart: cart}, nil
+}
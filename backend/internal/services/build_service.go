@@ -2,6 +2,12 @@ package services
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
@@ -10,13 +16,23 @@ import (
 	"github.com/amha-mersha/sanqa-suq/internal/repositories"
 )
 
+const (
+	defaultGalleryPageSize = 20
+	maxBuildNameLength     = 100
+	maxSlugBaseLength      = 60
+)
+
 type BuildService struct {
-	buildRepo *repositories.BuildRepository
+	buildRepo         *repositories.BuildRepository
+	compatibilityRepo *repositories.CompatibilityRepository
+	cartService       *CartService
 }
 
-func NewBuildService(buildRepo *repositories.BuildRepository) *BuildService {
+func NewBuildService(buildRepo *repositories.BuildRepository, compatibilityRepo *repositories.CompatibilityRepository, cartService *CartService) *BuildService {
 	return &BuildService{
-		buildRepo: buildRepo,
+		buildRepo:         buildRepo,
+		compatibilityRepo: compatibilityRepo,
+		cartService:       cartService,
 	}
 }
 
@@ -34,42 +50,205 @@ func (s *BuildService) validateBuildItems(items []dtos.BuildItemDTO) error {
 	return nil
 }
 
-func (s *BuildService) CreateBuild(ctx context.Context, userID string, req *dtos.CreateBuildRequestDTO) (*dtos.BuildResponseDTO, error) {
-	if err := s.validateBuildItems(req.Items); err != nil {
+// maxBuildAlternatives caps the alternatives suggested for each product of a violation
+const maxBuildAlternatives = 3
+
+// maxBuildCandidates caps the products of a category tried as alternatives, substitutes
+// or completions; the cheapest are tried
+const maxBuildCandidates = 50
+
+// buildCandidate is a product that may be suggested for a build, with its rule inputs
+type buildCandidate struct {
+	product   models.CompatibleProduct
+	component *models.BuildComponent
+}
+
+// ValidateBuild runs every compatibility rule against a set of items and reports the
+// failed ones. For the products involved in each violation, the cheapest products of the
+// same category that would clear it without breaking an error rule are suggested.
+func (s *BuildService) ValidateBuild(ctx context.Context, items []dtos.BuildItemDTO) (*models.BuildValidationReport, error) {
+	if err := s.validateBuildItems(items); err != nil {
 		return nil, err
 	}
 
-	// Convert DTO to model
-	build := &models.CustomBuild{
-		UserID: userID,
-		Name:   req.Name,
+	quantities := make(map[int]int, len(items))
+	for _, item := range items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	components, err := fetchBuildComponents(ctx, s.compatibilityRepo, quantities)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := s.compatibilityRepo.FetchRules(ctx, &dtos.CompatibilityRuleFilterDTO{})
+	if err != nil {
+		return nil, err
 	}
 
-	items := make([]models.BuildItem, len(req.Items))
-	for i, item := range req.Items {
-		items[i] = models.BuildItem{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
+	report := summarizeRuleResults(evaluateRules(rules, components))
+	candidates := make(map[int][]buildCandidate)
+	for _, result := range report.Rules {
+		for i := range result.Violations {
+			alternatives, err := s.findAlternatives(ctx, rules, components, result.RuleID, &result.Violations[i], candidates)
+			if err != nil {
+				return nil, err
+			}
+			result.Violations[i].Alternatives = alternatives
 		}
 	}
+	return report, nil
+}
 
-	// Create build in repository
-	result, err := s.buildRepo.CreateBuild(ctx, build, items)
+// summarizeRuleResults keeps the failed rules and counts them by severity
+func summarizeRuleResults(results []models.RuleResult) *models.BuildValidationReport {
+	report := &models.BuildValidationReport{Compatible: true, Rules: []models.RuleResult{}}
+	for _, result := range results {
+		if result.Status != models.RuleStatusFailed {
+			continue
+		}
+		if result.Severity == models.RuleSeverityError {
+			report.Compatible = false
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+		report.Rules = append(report.Rules, result)
+	}
+	return report
+}
+
+// findAlternatives tries each product of the same category in place of every product
+// involved in the violation, keeping those that the rule no longer fails for and that break
+// no error rule themselves. Candidates are loaded once per category.
+func (s *BuildService) findAlternatives(ctx context.Context, rules []*models.CompatibilityRule, components []*models.BuildComponent, ruleID int, violation *models.RuleViolation, candidates map[int][]buildCandidate) ([]models.ProductAlternative, error) {
+	inBuild := make(map[int]bool, len(components))
+	for _, component := range components {
+		inBuild[component.ProductID] = true
+	}
+
+	alternatives := []models.ProductAlternative{}
+	for _, productID := range violation.ProductIDs {
+		index := slices.IndexFunc(components, func(component *models.BuildComponent) bool {
+			return component.ProductID == productID
+		})
+		if index < 0 {
+			continue
+		}
+		replaced := components[index]
+
+		categoryCandidates, ok := candidates[replaced.CategoryID]
+		if !ok {
+			var err error
+			if categoryCandidates, err = s.loadCandidates(ctx, replaced.CategoryID); err != nil {
+				return nil, err
+			}
+			candidates[replaced.CategoryID] = categoryCandidates
+		}
+
+		found := 0
+		for _, candidate := range categoryCandidates {
+			if found == maxBuildAlternatives {
+				break
+			}
+			if inBuild[candidate.product.ProductID] {
+				continue
+			}
+			swapped := slices.Clone(components)
+			component := *candidate.component
+			component.Quantity = replaced.Quantity
+			swapped[index] = &component
+			if !clearsViolation(evaluateRules(rules, swapped), ruleID, component.ProductID) {
+				continue
+			}
+			alternatives = append(alternatives, models.ProductAlternative{
+				ReplacesProductID: replaced.ProductID,
+				ProductID:         candidate.product.ProductID,
+				ProductName:       candidate.product.ProductName,
+				Price:             candidate.product.Pricing.FinalPrice,
+			})
+			found++
+		}
+	}
+	return alternatives, nil
+}
+
+// loadCandidates returns the products of a category, cheapest first, with their rule inputs
+func (s *BuildService) loadCandidates(ctx context.Context, categoryID int) ([]buildCandidate, error) {
+	products, err := s.buildRepo.GetCompatibleProducts(ctx, categoryID, maxBuildCandidates)
 	if err != nil {
 		return nil, err
 	}
+	productIDs := make([]int, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ProductID
+	}
+	components, err := s.compatibilityRepo.FetchBuildComponents(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]*models.BuildComponent, len(components))
+	for _, component := range components {
+		byID[component.ProductID] = component
+	}
+
+	candidates := make([]buildCandidate, 0, len(products))
+	for _, product := range products {
+		if component, ok := byID[product.ProductID]; ok {
+			candidates = append(candidates, buildCandidate{product: product, component: component})
+		}
+	}
+	return candidates, nil
+}
 
-	// Convert model to response DTO
+// clearsViolation tells whether a product swapped into a build is free of violations of
+// the rule in question and of every error-severity rule
+func clearsViolation(results []models.RuleResult, ruleID int, productID int) bool {
+	for _, result := range results {
+		if result.RuleID != ruleID && result.Severity != models.RuleSeverityError {
+			continue
+		}
+		for _, violation := range result.Violations {
+			if slices.Contains(violation.ProductIDs, productID) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// checkCompatibility rejects a build breaking an error-severity rule; the details of the
+// error carry the validation report
+func (s *BuildService) checkCompatibility(ctx context.Context, items []dtos.BuildItemDTO) error {
+	report, err := s.ValidateBuild(ctx, items)
+	if err != nil {
+		return err
+	}
+	if report.Compatible {
+		return nil
+	}
+	appErr := errs.UnprocessableEntity("BUILD_INCOMPATIBLE", nil)
+	appErr.Meta = map[string]any{
+		"compatible": report.Compatible,
+		"errors":     report.Errors,
+		"warnings":   report.Warnings,
+		"rules":      report.Rules,
+	}
+	return appErr
+}
+
+// toBuildResponse converts a build with its items to the response DTO
+func toBuildResponse(build *models.BuildWithItems) *dtos.BuildResponseDTO {
 	response := &dtos.BuildResponseDTO{
-		BuildID:    result.BuildID,
-		UserID:     result.UserID,
-		Name:       result.Name,
-		CreatedAt:  result.CreatedAt.Format(time.RFC3339),
-		TotalPrice: result.TotalPrice,
-		Items:      make([]dtos.BuildItemResponseDTO, len(result.Items)),
+		BuildID:    build.BuildID,
+		UserID:     build.UserID,
+		Name:       build.Name,
+		CreatedAt:  build.CreatedAt.Format(time.RFC3339),
+		TotalPrice: build.TotalPrice,
+		Visibility: build.Visibility,
+		Slug:       build.Slug,
+		Items:      make([]dtos.BuildItemResponseDTO, len(build.Items)),
 	}
 
-	for i, item := range result.Items {
+	for i, item := range build.Items {
 		response.Items[i] = dtos.BuildItemResponseDTO{
 			ProductID:    item.ProductID,
 			Quantity:     item.Quantity,
@@ -81,18 +260,133 @@ func (s *BuildService) CreateBuild(ctx context.Context, userID string, req *dtos
 		}
 	}
 
-	return response, nil
+	return response
+}
+
+func (s *BuildService) CreateBuild(ctx context.Context, userID string, req *dtos.CreateBuildRequestDTO) (*dtos.BuildResponseDTO, error) {
+	if err := s.checkCompatibility(ctx, req.Items); err != nil {
+		return nil, err
+	}
+
+	// Convert DTO to model
+	build := &models.CustomBuild{
+		UserID: userID,
+		Name:   req.Name,
+	}
+
+	items := make([]models.BuildItem, len(req.Items))
+	for i, item := range req.Items {
+		items[i] = models.BuildItem{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+		}
+	}
+
+	// Create build in repository
+	result, err := s.buildRepo.CreateBuild(ctx, build, items)
+	if err != nil {
+		return nil, err
+	}
+
+	return toBuildResponse(result), nil
 }
 
 func (s *BuildService) GetUserBuilds(ctx context.Context, userID string) ([]models.BuildWithItems, error) {
 	return s.buildRepo.GetUserBuilds(ctx, userID)
 }
 
-func (s *BuildService) GetBuildByID(ctx context.Context, buildID string) (*models.BuildWithItems, error) {
+// GetBuildByID returns a build the viewer may see: their own, or one that is shared. A
+// private build of someone else is reported as not found, so its existence is not leaked.
+// viewerID is empty for anonymous requests.
+func (s *BuildService) GetBuildByID(ctx context.Context, buildID string, viewerID string) (*models.BuildWithItems, error) {
 	if buildID == "" {
 		return nil, errs.BadRequest("build ID is required", nil)
 	}
-	return s.buildRepo.GetBuildByID(ctx, buildID)
+	build, err := s.buildRepo.GetBuildByID(ctx, buildID)
+	if err != nil {
+		return nil, err
+	}
+	return visibleBuild(build, viewerID)
+}
+
+// GetSharedBuild returns the build behind a share link
+func (s *BuildService) GetSharedBuild(ctx context.Context, slug string, viewerID string) (*models.BuildWithItems, error) {
+	build, err := s.buildRepo.GetBuildBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	return visibleBuild(build, viewerID)
+}
+
+func visibleBuild(build *models.BuildWithItems, viewerID string) (*models.BuildWithItems, error) {
+	if build.Visibility == models.BuildVisibilityPrivate && build.UserID != viewerID {
+		return nil, errs.NotFound("build not found", nil)
+	}
+	return build, nil
+}
+
+// GetPublicBuilds lists the gallery of public builds, most recently shared first by default
+func (s *BuildService) GetPublicBuilds(ctx context.Context, filter *dtos.BuildGalleryDTO) (*models.BuildGallery, error) {
+	if filter.Sort == "" {
+		filter.Sort = "recent"
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultGalleryPageSize
+	}
+	return s.buildRepo.GetPublicBuilds(ctx, filter)
+}
+
+// SetBuildVisibility shares or unshares a build of the user. Sharing it for the first
+// time gives it a slug made of its name and a random suffix.
+func (s *BuildService) SetBuildVisibility(ctx context.Context, buildID string, userID string, visibility string) (*dtos.BuildResponseDTO, error) {
+	build, err := s.GetBuildByID(ctx, buildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	build, err = s.buildRepo.SetBuildVisibility(ctx, buildID, userID, visibility, newBuildSlug(build.Name))
+	if err != nil {
+		return nil, err
+	}
+	return toBuildResponse(build), nil
+}
+
+// CloneBuild copies a build the user can see into a new private build of theirs, named
+// "Copy of <name>" unless a name is given
+func (s *BuildService) CloneBuild(ctx context.Context, buildID string, userID string, name string) (*dtos.BuildResponseDTO, error) {
+	source, err := s.GetBuildByID(ctx, buildID, userID)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = "Copy of " + source.Name
+		if runes := []rune(name); len(runes) > maxBuildNameLength {
+			name = string(runes[:maxBuildNameLength])
+		}
+	}
+	build, err := s.buildRepo.CloneBuild(ctx, buildID, userID, name)
+	if err != nil {
+		return nil, err
+	}
+	return toBuildResponse(build), nil
+}
+
+// slugSeparators are the runs of characters a build name loses in its slug
+var slugSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// newBuildSlug turns "My Gaming PC!" into e.g. "my-gaming-pc-3f9a1c2e"; the suffix keeps
+// slugs unique and unlisted builds hard to guess
+func newBuildSlug(name string) string {
+	base := strings.Trim(slugSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if len(base) > maxSlugBaseLength {
+		base = strings.TrimRight(base[:maxSlugBaseLength], "-")
+	}
+	if base == "" {
+		base = "build"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return base + "-" + hex.EncodeToString(suffix)
 }
 
 func (s *BuildService) UpdateBuild(ctx context.Context, buildID string, userID string, req *dtos.UpdateBuildRequestDTO) (*dtos.BuildResponseDTO, error) {
@@ -100,8 +394,10 @@ func (s *BuildService) UpdateBuild(ctx context.Context, buildID string, userID s
 		return nil, errs.BadRequest("build ID is required", nil)
 	}
 
-	if err := s.validateBuildItems(req.Items); err != nil {
-		return nil, err
+	if len(req.Items) > 0 {
+		if err := s.checkCompatibility(ctx, req.Items); err != nil {
+			return nil, err
+		}
 	}
 
 	// Convert DTO items to model items
@@ -114,55 +410,241 @@ func (s *BuildService) UpdateBuild(ctx context.Context, buildID string, userID s
 	}
 
 	// Update the build
-	build, err := s.buildRepo.UpdateBuild(ctx, buildID, userID, &req.Name, items)
+	var name *string
+	if req.Name != "" {
+		name = &req.Name
+	}
+	build, err := s.buildRepo.UpdateBuild(ctx, buildID, userID, name, items, models.BuildChangeUpdated, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert to response DTO
-	response := &dtos.BuildResponseDTO{
-		BuildID:    build.BuildID,
-		UserID:     build.UserID,
-		Name:       build.Name,
-		CreatedAt:  build.CreatedAt.Format(time.RFC3339),
-		TotalPrice: build.TotalPrice,
-		Items:      make([]dtos.BuildItemResponseDTO, len(build.Items)),
+	return toBuildResponse(build), nil
+}
+
+func (s *BuildService) DeleteBuild(ctx context.Context, buildID string, userID string) error {
+	return s.buildRepo.DeleteBuild(ctx, buildID, userID)
+}
+
+// AddBuildItem adds a product to a build of the user, or more of it if the build has it
+func (s *BuildService) AddBuildItem(ctx context.Context, buildID string, userID string, req *dtos.AddBuildItemDTO) (*dtos.BuildResponseDTO, error) {
+	if req.Quantity == 0 {
+		req.Quantity = 1
 	}
+	return s.editBuildItems(ctx, buildID, userID, models.BuildChangeItemAdded, func(quantities map[int]int) error {
+		quantities[req.ProductID] += req.Quantity
+		return nil
+	})
+}
 
-	for i, item := range build.Items {
-		response.Items[i] = dtos.BuildItemResponseDTO{
-			ProductID:    item.ProductID,
-			Quantity:     item.Quantity,
-			ProductName:  item.ProductName,
-			Price:        item.Price,
-			Description:  item.Description,
-			BrandName:    item.BrandName,
-			CategoryName: item.CategoryName,
+func (s *BuildService) SetBuildItemQuantity(ctx context.Context, buildID string, userID string, productID int, quantity int) (*dtos.BuildResponseDTO, error) {
+	return s.editBuildItems(ctx, buildID, userID, models.BuildChangeQuantityChanged, func(quantities map[int]int) error {
+		if _, ok := quantities[productID]; !ok {
+			return errs.NotFound(fmt.Sprintf("build has no product %d", productID), nil)
 		}
+		quantities[productID] = quantity
+		return nil
+	})
+}
+
+// RemoveBuildItem takes a product out of a build; the last item cannot be removed, the
+// build is deleted instead
+func (s *BuildService) RemoveBuildItem(ctx context.Context, buildID string, userID string, productID int) (*dtos.BuildResponseDTO, error) {
+	return s.editBuildItems(ctx, buildID, userID, models.BuildChangeItemRemoved, func(quantities map[int]int) error {
+		if _, ok := quantities[productID]; !ok {
+			return errs.NotFound(fmt.Sprintf("build has no product %d", productID), nil)
+		}
+		if len(quantities) == 1 {
+			return errs.BadRequest("BUILD_MUST_KEEP_ONE_ITEM", nil)
+		}
+		delete(quantities, productID)
+		return nil
+	})
+}
+
+// editBuildItems applies an edit to the item quantities of a build of the user, validates
+// the result like a full update and saves it as a revision with the given change. The edit
+// runs while the repository holds the build locked, so it sees the latest items.
+func (s *BuildService) editBuildItems(ctx context.Context, buildID string, userID string, change string, edit func(quantities map[int]int) error) (*dtos.BuildResponseDTO, error) {
+	build, err := s.buildRepo.EditBuildItems(ctx, buildID, userID, change, func(current []models.BuildItem) ([]models.BuildItem, error) {
+		quantities := make(map[int]int, len(current)+1)
+		for _, item := range current {
+			quantities[item.ProductID] = item.Quantity
+		}
+		if err := edit(quantities); err != nil {
+			return nil, err
+		}
+
+		items := make([]dtos.BuildItemDTO, 0, len(quantities))
+		for productID, quantity := range quantities {
+			items = append(items, dtos.BuildItemDTO{ProductID: productID, Quantity: quantity})
+		}
+		slices.SortFunc(items, func(a, b dtos.BuildItemDTO) int { return a.ProductID - b.ProductID })
+		if err := s.checkCompatibility(ctx, items); err != nil {
+			return nil, err
+		}
+
+		buildItems := make([]models.BuildItem, len(items))
+		for i, item := range items {
+			buildItems[i] = models.BuildItem{ProductID: item.ProductID, Quantity: item.Quantity}
+		}
+		return buildItems, nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	return toBuildResponse(build), nil
+}
 
-	return response, nil
+// replaceBuildItems checks the items of a build for compatibility before saving them
+func (s *BuildService) replaceBuildItems(ctx context.Context, buildID string, userID string, name *string, items []dtos.BuildItemDTO, change string, restoredFrom *int) (*dtos.BuildResponseDTO, error) {
+	if err := s.checkCompatibility(ctx, items); err != nil {
+		return nil, err
+	}
+	buildItems := make([]models.BuildItem, len(items))
+	for i, item := range items {
+		buildItems[i] = models.BuildItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	build, err := s.buildRepo.UpdateBuild(ctx, buildID, userID, name, buildItems, change, restoredFrom)
+	if err != nil {
+		return nil, err
+	}
+	return toBuildResponse(build), nil
+}
+
+// ownBuild checks that a build exists and belongs to the user; someone else's build is
+// not found if private and forbidden otherwise
+func (s *BuildService) ownBuild(ctx context.Context, buildID string, userID string) error {
+	build, err := s.GetBuildByID(ctx, buildID, userID)
+	if err != nil {
+		return err
+	}
+	if build.UserID != userID {
+		return errs.Forbidden("you can only view the history of your own builds", nil)
+	}
+	return nil
 }
 
+// GetBuildRevisions lists the history of a build of the user, newest first
+func (s *BuildService) GetBuildRevisions(ctx context.Context, buildID string, userID string) ([]*models.BuildRevision, error) {
+	if err := s.ownBuild(ctx, buildID, userID); err != nil {
+		return nil, err
+	}
+	return s.buildRepo.GetBuildRevisions(ctx, buildID)
+}
+
+func (s *BuildService) GetBuildRevision(ctx context.Context, buildID string, userID string, revision int) (*models.BuildRevision, error) {
+	if err := s.ownBuild(ctx, buildID, userID); err != nil {
+		return nil, err
+	}
+	return s.buildRepo.GetBuildRevision(ctx, buildID, revision)
+}
+
+// RestoreBuildRevision brings back the name and items of an earlier revision as a new
+// revision. The items are validated again, since rules and products may have changed.
+func (s *BuildService) RestoreBuildRevision(ctx context.Context, buildID string, userID string, revision int) (*dtos.BuildResponseDTO, error) {
+	saved, err := s.GetBuildRevision(ctx, buildID, userID, revision)
+	if err != nil {
+		return nil, err
+	}
+	if len(saved.Items) == 0 {
+		return nil, errs.UnprocessableEntity("REVISION_HAS_NO_ITEMS", nil)
+	}
+	items := make([]dtos.BuildItemDTO, len(saved.Items))
+	for i, item := range saved.Items {
+		items[i] = dtos.BuildItemDTO{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+	return s.replaceBuildItems(ctx, buildID, userID, &saved.Name, items, models.BuildChangeRestored, &saved.Revision)
+}
+
+// GetCompatibleProducts lists the products of a category that can join the selected items
+// without breaking an error-severity rule that the selection did not already break
 func (s *BuildService) GetCompatibleProducts(ctx context.Context, categoryID int, selectedItems []int) ([]dtos.CompatibleProductDTO, error) {
-	products, err := s.buildRepo.GetCompatibleProducts(ctx, categoryID, selectedItems)
+	products, err := s.buildRepo.GetCompatibleProducts(ctx, categoryID, 0)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert model to DTO
-	response := make([]dtos.CompatibleProductDTO, len(products))
+	rules, err := s.compatibilityRepo.FetchRules(ctx, &dtos.CompatibilityRuleFilterDTO{})
+	if err != nil {
+		return nil, err
+	}
+	quantities := make(map[int]int, len(selectedItems))
+	for _, productID := range selectedItems {
+		quantities[productID]++
+	}
+	selected, err := fetchBuildComponents(ctx, s.compatibilityRepo, quantities)
+	if err != nil {
+		return nil, err
+	}
+	candidateIDs := make([]int, len(products))
 	for i, product := range products {
-		response[i] = dtos.CompatibleProductDTO{
-			ProductID:    product.ProductID,
-			ProductName:  product.ProductName,
-			Price:        product.Price,
-			Description:  product.Description,
-			BrandName:    product.BrandName,
-			CategoryName: product.CategoryName,
-			Specs:        product.Specs,
+		candidateIDs[i] = product.ProductID
+	}
+	candidates, err := s.compatibilityRepo.FetchBuildComponents(ctx, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	baseline := blockingViolations(rules, evaluateRules(rules, selected))
+	compatible := make(map[int]bool, len(candidates))
+	for _, candidate := range candidates {
+		components := append(slices.Clone(selected), candidate)
+		compatible[candidate.ProductID] = !introducesViolation(baseline, blockingViolations(rules, evaluateRules(rules, components)))
+	}
+
+	// Convert model to DTO
+	response := make([]dtos.CompatibleProductDTO, 0, len(products))
+	for _, product := range products {
+		if !compatible[product.ProductID] {
+			continue
 		}
+		response = append(response, dtos.CompatibleProductDTO{
+			ProductID:     product.ProductID,
+			ProductName:   product.ProductName,
+			Price:         product.Price,
+			Description:   product.Description,
+			BrandName:     product.BrandName,
+			CategoryName:  product.CategoryName,
+			StockQuantity: product.StockQuantity,
+			Specs:         product.Specs,
+		})
 	}
 
 	return response, nil
 }
+
+// introducesViolation tells whether a build breaks an error rule in a way the build it
+// grew from did not
+func introducesViolation(before map[string]bool, after map[string]bool) bool {
+	for key := range after {
+		if !before[key] {
+			return true
+		}
+	}
+	return false
+}
+
+// blockingViolations identifies the error-severity violations of a build. Checks of
+// "all" rules are per target, so a new target failing is told apart from an old one.
+func blockingViolations(rules []*models.CompatibilityRule, results []models.RuleResult) map[string]bool {
+	perTarget := make(map[int]bool, len(rules))
+	for _, rule := range rules {
+		perTarget[rule.RuleID] = rule.TargetAggregate == nil && rule.Quantifier == models.RuleQuantifierAll
+	}
+
+	keys := make(map[string]bool)
+	for _, result := range results {
+		if result.Severity != models.RuleSeverityError {
+			continue
+		}
+		for _, violation := range result.Violations {
+			key := fmt.Sprintf("%d/%d", result.RuleID, violation.SubjectProductID)
+			if perTarget[result.RuleID] {
+				key += fmt.Sprintf("/%d", violation.TargetProductIDs[0])
+			}
+			keys[key] = true
+		}
+	}
+	return keys
+}
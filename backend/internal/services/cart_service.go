@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+type CartService struct {
+	cartRepo   *repositories.CartRepository
+	couponRepo *repositories.CouponRepository
+}
+
+func NewCartService(cartRepo *repositories.CartRepository, couponRepo *repositories.CouponRepository) *CartService {
+	return &CartService{
+		cartRepo:   cartRepo,
+		couponRepo: couponRepo,
+	}
+}
+
+func (s *CartService) GetCart(ctx context.Context, userID string) (*dtos.CartResponseDTO, error) {
+	items, err := s.cartRepo.GetCartItems(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dtos.CartResponseDTO{
+		Items: make([]dtos.CartItemResponseDTO, len(items)),
+	}
+	for i, item := range items {
+		line := dtos.CartItemResponseDTO{
+			ProductID:          item.ProductID,
+			ProductName:        item.ProductName,
+			OriginalPrice:      item.OriginalPrice,
+			DiscountPercentage: item.DiscountPercentage,
+			Price:              item.Price,
+			Quantity:           item.Quantity,
+			LineTotal:          item.LineTotal,
+			StockQuantity:      item.StockQuantity,
+			InStock:            item.StockQuantity >= item.Quantity,
+		}
+		if item.StockQuantity == 0 {
+			line.StockWarning = "OUT_OF_STOCK"
+		} else if item.StockQuantity < item.Quantity {
+			line.StockWarning = "INSUFFICIENT_STOCK"
+		}
+		if !line.InStock {
+			response.HasStockIssues = true
+		}
+
+		response.Items[i] = line
+		response.ItemCount += item.Quantity
+		response.OriginalSubtotal += item.OriginalPrice * float64(item.Quantity)
+		response.Subtotal += item.LineTotal
+	}
+	response.DiscountTotal = math.Round((response.OriginalSubtotal-response.Subtotal)*100) / 100
+	response.Total = response.Subtotal
+
+	return response, nil
+}
+
+// GetCartWithCoupon prices the cart with a coupon code applied. A coupon that cannot be used
+// for this cart is reported in coupon_error instead of failing the request.
+func (s *CartService) GetCartWithCoupon(ctx context.Context, userID string, couponCode string) (*dtos.CartResponseDTO, error) {
+	response, err := s.GetCart(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Items) == 0 {
+		response.CouponError = "CART_IS_EMPTY"
+		return response, nil
+	}
+
+	items := make([]models.OrderItem, len(response.Items))
+	for i, item := range response.Items {
+		items[i] = models.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity}
+	}
+
+	quote, err := s.couponRepo.QuoteCoupon(ctx, userID, strings.TrimSpace(couponCode), items)
+	if err != nil {
+		var appErr *errs.AppError
+		if errors.As(err, &appErr) && appErr.StatusCode < http.StatusInternalServerError {
+			response.CouponError = appErr.Message
+			return response, nil
+		}
+		return nil, err
+	}
+
+	response.Coupon = &dtos.CartCouponDTO{
+		Code:             quote.Code,
+		EligibleSubtotal: quote.EligibleSubtotal,
+		DiscountAmount:   quote.DiscountAmount,
+	}
+	response.Total = math.Round((response.Subtotal-quote.DiscountAmount)*100) / 100
+	return response, nil
+}
+
+func (s *CartService) AddItem(ctx context.Context, userID string, req *dtos.CartItemDTO) (*dtos.CartResponseDTO, error) {
+	if req.Quantity <= 0 {
+		return nil, errs.BadRequest("QUANTITY_MUST_BE_POSITIVE", nil)
+	}
+	if err := s.cartRepo.AddCartItem(ctx, userID, req.ProductID, req.Quantity); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, userID)
+}
+
+func (s *CartService) UpdateItemQuantity(ctx context.Context, userID string, productID int, req *dtos.UpdateCartItemDTO) (*dtos.CartResponseDTO, error) {
+	if req.Quantity <= 0 {
+		return nil, errs.BadRequest("QUANTITY_MUST_BE_POSITIVE", nil)
+	}
+	if err := s.cartRepo.UpdateCartItemQuantity(ctx, userID, productID, req.Quantity); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, userID)
+}
+
+func (s *CartService) RemoveItem(ctx context.Context, userID string, productID int) (*dtos.CartResponseDTO, error) {
+	if err := s.cartRepo.RemoveCartItem(ctx, userID, productID); err != nil {
+		return nil, err
+	}
+	return s.GetCart(ctx, userID)
+}
+
+func (s *CartService) ClearCart(ctx context.Context, userID string) error {
+	return s.cartRepo.ClearCart(ctx, userID)
+}
+
+// MergeCart folds an anonymous (client-side) cart into the user's cart, typically right after login.
+func (s *CartService) MergeCart(ctx context.Context, userID string, req *dtos.MergeCartDTO) (*dtos.CartResponseDTO, error) {
+	// collapse duplicate lines so each product is upserted once
+	merged := make(map[int]int)
+	var order []int
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, errs.BadRequest("QUANTITY_MUST_BE_POSITIVE", nil)
+		}
+		if _, seen := merged[item.ProductID]; !seen {
+			order = append(order, item.ProductID)
+		}
+		merged[item.ProductID] += item.Quantity
+	}
+
+	items := make([]models.CartItem, len(order))
+	for i, productID := range order {
+		items[i] = models.CartItem{
+			ProductID: productID,
+			Quantity:  merged[productID],
+		}
+	}
+
+	if len(items) > 0 {
+		if err := s.cartRepo.MergeCartItems(ctx, userID, items); err != nil {
+			return nil, err
+		}
+	}
+	return s.GetCart(ctx, userID)
+}
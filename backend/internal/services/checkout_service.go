@@ -0,0 +1,95 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+type CheckoutService struct {
+	orderRepo   *repositories.OrderRepository
+	cartRepo    *repositories.CartRepository
+	builds      *BuildService
+	addressRepo *repositories.AddressRepository
+}
+
+func NewCheckoutService(
+	orderRepo *repositories.OrderRepository,
+	cartRepo *repositories.CartRepository,
+	builds *BuildService,
+	addressRepo *repositories.AddressRepository,
+) *CheckoutService {
+	return &CheckoutService{
+		orderRepo:   orderRepo,
+		cartRepo:    cartRepo,
+		builds:      builds,
+		addressRepo: addressRepo,
+	}
+}
+
+// Checkout turns the user's cart, or one of their saved builds, into an order.
+// Prices and totals are always computed by the database, never taken from the client.
+func (s *CheckoutService) Checkout(ctx context.Context, userID string, req *dtos.CheckoutRequestDTO) (*models.OrderWithItems, error) {
+	if _, err := s.addressRepo.GetAddressByID(ctx, req.AddressID, userID); err != nil {
+		return nil, err
+	}
+
+	var items []models.OrderItem
+	var agreedPrices map[int]float64
+	fromCart := req.BuildID == ""
+
+	if fromCart {
+		cartItems, err := s.cartRepo.GetCartItems(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range cartItems {
+			items = append(items, models.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+		if len(items) == 0 {
+			return nil, errs.BadRequest("CART_IS_EMPTY", nil)
+		}
+	} else {
+		build, err := s.builds.GetBuildByID(ctx, req.BuildID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if build.UserID != userID {
+			return nil, errs.Forbidden("you can only check out your own builds", nil)
+		}
+		for _, item := range build.Items {
+			items = append(items, models.OrderItem{ProductID: item.ProductID, Quantity: item.Quantity})
+		}
+		if len(items) == 0 {
+			return nil, errs.BadRequest("BUILD_HAS_NO_ITEMS", nil)
+		}
+
+		// a build is only ordered as saved: missing stock is reported with substitutes,
+		// changed prices need the user's consent
+		check, err := s.builds.checkPurchase(ctx, build, true)
+		if err != nil {
+			return nil, err
+		}
+		if check.HasStockIssues || (check.Repriced && !req.AcceptPriceChanges) {
+			code := "BUILD_ITEMS_UNAVAILABLE"
+			if !check.HasStockIssues {
+				code = "BUILD_PRICES_CHANGED"
+			}
+			appErr := errs.Conflict(code, nil)
+			appErr.Meta = map[string]any{"check": check}
+			return nil, appErr
+		}
+		// the order is placed at these prices or not at all, even if they change meanwhile
+		agreedPrices = make(map[int]float64, len(check.Items))
+		for _, line := range check.Items {
+			agreedPrices[line.ProductID] = line.CurrentPrice
+		}
+	}
+
+	couponCode := strings.ToUpper(strings.TrimSpace(req.CouponCode))
+	return s.orderRepo.PlaceOrder(ctx, userID, req.AddressID, req.PaymentMethod, items, agreedPrices, fromCart, couponCode)
+}
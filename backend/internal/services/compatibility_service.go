@@ -0,0 +1,176 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+type CompatibilityService struct {
+	compatibilityRepo *repositories.CompatibilityRepository
+}
+
+func NewCompatibilityService(compatibilityRepo *repositories.CompatibilityRepository) *CompatibilityService {
+	return &CompatibilityService{
+		compatibilityRepo: compatibilityRepo,
+	}
+}
+
+// optionalString trims s and returns nil when nothing is left
+func optionalString(s string) *string {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil
+	}
+	return &s
+}
+
+// optionalSpecName normalizes a spec name that may be left out
+func optionalSpecName(name string) (*string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, nil
+	}
+	normalized, err := normalizeSpecName(name)
+	if err != nil {
+		return nil, err
+	}
+	return &normalized, nil
+}
+
+func (s *CompatibilityService) GetRules(ctx context.Context, filter *dtos.CompatibilityRuleFilterDTO) ([]*models.CompatibilityRule, error) {
+	return s.compatibilityRepo.FetchRules(ctx, filter)
+}
+
+func (s *CompatibilityService) GetRule(ctx context.Context, ruleID int) (*models.CompatibilityRule, error) {
+	return s.compatibilityRepo.FindRuleByID(ctx, ruleID)
+}
+
+// CreateRule stores a new compatibility rule (admin only). Spec names are normalized the
+// same way as product specs so the rule matches them.
+func (s *CompatibilityService) CreateRule(ctx context.Context, dto *dtos.CreateCompatibilityRuleDTO) (*models.CompatibilityRule, error) {
+	if dto.SubjectCategoryID == nil && dto.SubjectProductID == nil {
+		return nil, errs.UnprocessableEntity("RULE_SUBJECT_REQUIRED", nil)
+	}
+
+	rule := &models.CompatibilityRule{
+		Name:               strings.TrimSpace(dto.Name),
+		Description:        optionalString(dto.Description),
+		Severity:           dto.Severity,
+		SubjectCategoryID:  dto.SubjectCategoryID,
+		SubjectProductID:   dto.SubjectProductID,
+		SubjectFilterValue: optionalString(dto.SubjectFilterValue),
+		TargetCategoryID:   dto.TargetCategoryID,
+		TargetFilterValue:  optionalString(dto.TargetFilterValue),
+		TargetAggregate:    optionalString(dto.TargetAggregate),
+		Operator:           dto.Operator,
+		Quantifier:         dto.Quantifier,
+		Margin:             dto.Margin,
+		Value:              optionalString(dto.Value),
+	}
+	if rule.Name == "" {
+		return nil, errs.BadRequest("RULE_NAME_REQUIRED", nil)
+	}
+	if rule.Severity == "" {
+		rule.Severity = models.RuleSeverityError
+	}
+	if rule.Operator == "" {
+		rule.Operator = models.RuleOperatorEquals
+	}
+	if rule.Quantifier == "" {
+		rule.Quantifier = models.RuleQuantifierAll
+	}
+
+	var err error
+	if rule.TargetSpec, err = optionalSpecName(dto.TargetSpec); err != nil {
+		return nil, err
+	}
+	if rule.SubjectSpec, err = optionalSpecName(dto.SubjectSpec); err != nil {
+		return nil, err
+	}
+	if rule.SubjectFilterSpec, err = optionalSpecName(dto.SubjectFilterSpec); err != nil {
+		return nil, err
+	}
+	if rule.TargetFilterSpec, err = optionalSpecName(dto.TargetFilterSpec); err != nil {
+		return nil, err
+	}
+
+	if (rule.SubjectFilterSpec == nil) != (rule.SubjectFilterValue == nil) ||
+		(rule.TargetFilterSpec == nil) != (rule.TargetFilterValue == nil) {
+		return nil, errs.UnprocessableEntity("RULE_FILTER_NEEDS_SPEC_AND_VALUE", nil)
+	}
+	if (rule.SubjectSpec == nil) == (rule.Value == nil) {
+		return nil, errs.UnprocessableEntity("RULE_NEEDS_VALUE_OR_SUBJECT_SPEC", nil)
+	}
+	if rule.TargetSpec == nil && (rule.TargetAggregate == nil || *rule.TargetAggregate != models.RuleAggregateCount) {
+		return nil, errs.UnprocessableEntity("RULE_TARGET_SPEC_REQUIRED", nil)
+	}
+	numeric := rule.Operator == models.RuleOperatorGTE || rule.Operator == models.RuleOperatorLTE
+	if (rule.TargetAggregate != nil || rule.Margin != 0) && !numeric {
+		return nil, errs.UnprocessableEntity("RULE_AGGREGATE_NEEDS_NUMERIC_OPERATOR", nil)
+	}
+
+	return s.compatibilityRepo.InsertRule(ctx, rule)
+}
+
+func (s *CompatibilityService) DeleteRule(ctx context.Context, ruleID int) error {
+	return s.compatibilityRepo.DeleteRule(ctx, ruleID)
+}
+
+// TestRule runs a rule against a set of products as if they formed a build, so admins
+// can check a rule before relying on it
+func (s *CompatibilityService) TestRule(ctx context.Context, ruleID int, dto *dtos.TestCompatibilityRuleDTO) (*models.RuleTestResult, error) {
+	rule, err := s.compatibilityRepo.FindRuleByID(ctx, ruleID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[int]int, len(dto.ProductIDs))
+	for _, productID := range dto.ProductIDs {
+		quantities[productID]++
+	}
+	components, err := fetchBuildComponents(ctx, s.compatibilityRepo, quantities)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RuleTestResult{
+		Rule:       rule,
+		ProductIDs: dto.ProductIDs,
+		Result:     evaluateRule(rule, components),
+	}, nil
+}
+
+// fetchBuildComponents loads the components for product id → quantity; any unknown product
+// is not found
+func fetchBuildComponents(ctx context.Context, repo *repositories.CompatibilityRepository, quantities map[int]int) ([]*models.BuildComponent, error) {
+	productIDs := make([]int, 0, len(quantities))
+	for productID := range quantities {
+		productIDs = append(productIDs, productID)
+	}
+	components, err := repo.FetchBuildComponents(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	found := make(map[int]bool, len(components))
+	for _, component := range components {
+		component.Quantity = quantities[component.ProductID]
+		found[component.ProductID] = true
+	}
+	missing := []int{}
+	for _, productID := range productIDs {
+		if !found[productID] {
+			missing = append(missing, productID)
+		}
+	}
+	if len(missing) > 0 {
+		sort.Ints(missing)
+		return nil, errs.NotFound(fmt.Sprintf("products %v not found", missing), nil)
+	}
+	return components, nil
+}
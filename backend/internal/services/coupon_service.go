@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]+$`)
+
+type CouponService struct {
+	couponRepo *repositories.CouponRepository
+}
+
+func NewCouponService(couponRepo *repositories.CouponRepository) *CouponService {
+	return &CouponService{
+		couponRepo: couponRepo,
+	}
+}
+
+func (s *CouponService) GetCoupons(ctx context.Context, filter *dtos.CouponFilterDTO) ([]*models.Coupon, error) {
+	return s.couponRepo.FetchCoupons(ctx, filter)
+}
+
+func (s *CouponService) GetCoupon(ctx context.Context, couponID int) (*models.Coupon, error) {
+	return s.couponRepo.FindCouponByID(ctx, couponID)
+}
+
+// CreateCoupon stores a new coupon. Codes are case-insensitive and kept upper case.
+func (s *CouponService) CreateCoupon(ctx context.Context, dto *dtos.CreateCouponDTO) (*models.Coupon, error) {
+	dto.Code = strings.ToUpper(strings.TrimSpace(dto.Code))
+	if !couponCodePattern.MatchString(dto.Code) {
+		return nil, errs.UnprocessableEntity("INVALID_COUPON_CODE", nil)
+	}
+	if err := checkCouponTerms(dto.CouponType, dto.Value, dto.MaxDiscountAmount, dto.StartsAt, dto.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return s.couponRepo.InsertCoupon(ctx, dto)
+}
+
+// UpdateCoupon changes the given fields of a coupon; the coupon they make together with
+// the unchanged fields must pass the same checks as a new one
+func (s *CouponService) UpdateCoupon(ctx context.Context, couponID int, dto *dtos.UpdateCouponDTO) (*models.Coupon, error) {
+	coupon, err := s.couponRepo.FindCouponByID(ctx, couponID)
+	if err != nil {
+		return nil, err
+	}
+	value, maxDiscount, startsAt, expiresAt := coupon.Value, coupon.MaxDiscountAmount, &coupon.StartsAt, coupon.ExpiresAt
+	if dto.Value != nil {
+		value = *dto.Value
+	}
+	if dto.MaxDiscountAmount != nil {
+		maxDiscount = dto.MaxDiscountAmount
+	}
+	if dto.StartsAt != nil {
+		startsAt = dto.StartsAt
+	}
+	if dto.ExpiresAt != nil {
+		expiresAt = dto.ExpiresAt
+	}
+	if err := checkCouponTerms(coupon.CouponType, value, maxDiscount, startsAt, expiresAt); err != nil {
+		return nil, err
+	}
+	return s.couponRepo.UpdateCoupon(ctx, couponID, dto)
+}
+
+// checkCouponTerms enforces the rules on a coupon's value, cap and dates
+func checkCouponTerms(couponType string, value float64, maxDiscount *float64, startsAt *time.Time, expiresAt *time.Time) error {
+	if couponType == models.CouponTypePercentage && value > 100 {
+		return errs.UnprocessableEntity("COUPON_PERCENTAGE_ABOVE_100", nil)
+	}
+	if couponType == models.CouponTypeFixed && maxDiscount != nil {
+		return errs.UnprocessableEntity("MAX_DISCOUNT_ONLY_FOR_PERCENTAGE_COUPONS", nil)
+	}
+	if startsAt != nil && expiresAt != nil && expiresAt.Before(*startsAt) {
+		return errs.UnprocessableEntity("COUPON_EXPIRES_BEFORE_IT_STARTS", nil)
+	}
+	return nil
+}
+
+func (s *CouponService) DeleteCoupon(ctx context.Context, couponID int) error {
+	return s.couponRepo.DeleteCoupon(ctx, couponID)
+}
+
+func (s *CouponService) GetRedemptions(ctx context.Context, couponID int) ([]*models.CouponRedemption, error) {
+	if _, err := s.couponRepo.FindCouponByID(ctx, couponID); err != nil {
+		return nil, err
+	}
+	return s.couponRepo.FetchRedemptions(ctx, couponID)
+}
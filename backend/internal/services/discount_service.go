@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+type DiscountService struct {
+	discountRepo *repositories.DiscountRepository
+}
+
+func NewDiscountService(discountRepo *repositories.DiscountRepository) *DiscountService {
+	return &DiscountService{
+		discountRepo: discountRepo,
+	}
+}
+
+// validateDiscount checks the rules the discounts table does not enforce on its own
+func validateDiscount(discount *models.Discount) error {
+	if (discount.ProductID == nil) == (discount.CategoryID == nil) {
+		return errs.UnprocessableEntity("DISCOUNT_MUST_TARGET_PRODUCT_OR_CATEGORY", nil)
+	}
+	if discount.EndDate.Before(discount.StartDate) {
+		return errs.UnprocessableEntity("DISCOUNT_ENDS_BEFORE_IT_STARTS", nil)
+	}
+	return nil
+}
+
+func (s *DiscountService) GetDiscounts(ctx context.Context, filter *dtos.DiscountFilterDTO) ([]*models.Discount, error) {
+	return s.discountRepo.FetchDiscounts(ctx, filter)
+}
+
+func (s *DiscountService) GetDiscount(ctx context.Context, discountID int) (*models.Discount, error) {
+	return s.discountRepo.FindDiscountByID(ctx, discountID)
+}
+
+func (s *DiscountService) CreateDiscount(ctx context.Context, dto *dtos.CreateDiscountDTO) (*models.Discount, error) {
+	discount := &models.Discount{
+		ProductID:          dto.ProductID,
+		CategoryID:         dto.CategoryID,
+		DiscountPercentage: dto.DiscountPercentage,
+		StartDate:          dto.StartDate,
+		EndDate:            dto.EndDate,
+	}
+	if err := validateDiscount(discount); err != nil {
+		return nil, err
+	}
+	return s.discountRepo.InsertDiscount(ctx, discount)
+}
+
+// UpdateDiscount applies a partial update. Giving a product_id or category_id moves the
+// discount to that target and clears the other one.
+func (s *DiscountService) UpdateDiscount(ctx context.Context, discountID int, dto *dtos.UpdateDiscountDTO) (*models.Discount, error) {
+	if dto.ProductID != nil && dto.CategoryID != nil {
+		return nil, errs.UnprocessableEntity("DISCOUNT_MUST_TARGET_PRODUCT_OR_CATEGORY", nil)
+	}
+
+	discount, err := s.discountRepo.FindDiscountByID(ctx, discountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if dto.ProductID != nil {
+		discount.ProductID = dto.ProductID
+		discount.CategoryID = nil
+	}
+	if dto.CategoryID != nil {
+		discount.CategoryID = dto.CategoryID
+		discount.ProductID = nil
+	}
+	if dto.DiscountPercentage != nil {
+		discount.DiscountPercentage = *dto.DiscountPercentage
+	}
+	if dto.StartDate != nil {
+		discount.StartDate = *dto.StartDate
+	}
+	if dto.EndDate != nil {
+		discount.EndDate = *dto.EndDate
+	}
+
+	if err := validateDiscount(discount); err != nil {
+		return nil, err
+	}
+	return s.discountRepo.UpdateDiscount(ctx, discount)
+}
+
+func (s *DiscountService) DeleteDiscount(ctx context.Context, discountID int) error {
+	return s.discountRepo.DeleteDiscount(ctx, discountID)
+}
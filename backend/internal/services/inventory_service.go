@@ -0,0 +1,76 @@
+package services
+
+import (
+	"context"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+const (
+	defaultLowStockThreshold = 5
+	defaultLedgerPageSize    = 50
+)
+
+// adjustmentDirections lists the adjustment reasons that only make sense in one direction:
+// -1 must take stock out, +1 must put stock back. Corrections may go either way.
+var adjustmentDirections = map[string]int{
+	models.StockReasonDamaged:        -1,
+	models.StockReasonLost:           -1,
+	models.StockReasonSupplierReturn: -1,
+	models.StockReasonFound:          1,
+	models.StockReasonCustomerReturn: 1,
+}
+
+type InventoryService struct {
+	inventoryRepo *repositories.InventoryRepository
+}
+
+func NewInventoryService(inventoryRepo *repositories.InventoryRepository) *InventoryService {
+	return &InventoryService{
+		inventoryRepo: inventoryRepo,
+	}
+}
+
+// ReceiveStock books a supplier delivery and returns the product's updated ledger
+func (s *InventoryService) ReceiveStock(ctx context.Context, productID int, userID string, dto *dtos.ReceiveStockDTO) (*models.StockLedger, error) {
+	if err := s.inventoryRepo.ReceiveStock(ctx, productID, dto, userID); err != nil {
+		return nil, err
+	}
+	return s.inventoryRepo.FetchStockLedger(ctx, productID, defaultLedgerPageSize, 0)
+}
+
+// AdjustStock applies a manual correction and returns the product's updated ledger
+func (s *InventoryService) AdjustStock(ctx context.Context, productID int, userID string, dto *dtos.AdjustStockDTO) (*models.StockLedger, error) {
+	if direction, ok := adjustmentDirections[dto.Reason]; ok {
+		if direction < 0 && dto.QuantityChange > 0 {
+			return nil, errs.UnprocessableEntity("REASON_REQUIRES_NEGATIVE_QUANTITY", nil)
+		}
+		if direction > 0 && dto.QuantityChange < 0 {
+			return nil, errs.UnprocessableEntity("REASON_REQUIRES_POSITIVE_QUANTITY", nil)
+		}
+	}
+
+	if err := s.inventoryRepo.AdjustStock(ctx, productID, dto, userID); err != nil {
+		return nil, err
+	}
+	return s.inventoryRepo.FetchStockLedger(ctx, productID, defaultLedgerPageSize, 0)
+}
+
+func (s *InventoryService) GetStockLedger(ctx context.Context, productID int, filter *dtos.StockLedgerFilterDTO) (*models.StockLedger, error) {
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultLedgerPageSize
+	}
+	return s.inventoryRepo.FetchStockLedger(ctx, productID, limit, filter.Offset)
+}
+
+func (s *InventoryService) GetLowStockProducts(ctx context.Context, filter *dtos.LowStockFilterDTO) ([]*models.LowStockProduct, error) {
+	threshold := defaultLowStockThreshold
+	if filter.Threshold != nil {
+		threshold = *filter.Threshold
+	}
+	return s.inventoryRepo.FetchLowStockProducts(ctx, threshold, filter)
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+const defaultNotificationPageSize = 20
+
+type NotificationService struct {
+	notificationRepo *repositories.NotificationRepository
+}
+
+func NewNotificationService(notificationRepo *repositories.NotificationRepository) *NotificationService {
+	return &NotificationService{notificationRepo: notificationRepo}
+}
+
+func (s *NotificationService) GetUserNotifications(ctx context.Context, userID string, filter *dtos.NotificationFilterDTO) ([]*models.Notification, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultNotificationPageSize
+	}
+	return s.notificationRepo.GetUserNotifications(ctx, userID, filter)
+}
+
+func (s *NotificationService) MarkNotificationRead(ctx context.Context, notificationID int, userID string) (*models.Notification, error) {
+	return s.notificationRepo.MarkNotificationRead(ctx, notificationID, userID)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
@@ -41,9 +42,6 @@ func (service *OrderService) AddNewOrder(ctx context.Context, dto *dtos.CreateOr
 func (service *OrderService) UpdateOrder(ctx context.Context, orderID string, dto *dtos.UpdateOrderDTO) (*models.Order, error) {
 	updateFields := make(map[string]any)
 
-	if dto.Status != nil {
-		updateFields["status"] = *dto.Status
-	}
 	if dto.TotalAmount != nil {
 		if *dto.TotalAmount < 0 {
 			return nil, errs.BadRequest("ORDER_TOTAL_AMOUNT_INVALID", nil)
@@ -69,28 +67,37 @@ func (service *OrderService) UpdateOrder(ctx context.Context, orderID string, dt
 	return service.repository.UpdateOrder(ctx, orderID, dto)
 }
 
-// Fetch one order by its ID
-func (service *OrderService) GetOrder(ctx context.Context, orderID string) (*models.Order, error) {
+// Fetch one order with its items; customers may only read their own orders
+func (service *OrderService) GetOrder(ctx context.Context, orderID string, requesterID string, requesterRole string) (*models.OrderWithItems, error) {
 	if orderID == "" {
 		return nil, errs.BadRequest("INVALID_ORDER_ID", nil)
 	}
-	return service.repository.FindOrderByID(ctx, orderID)
+	order, err := service.repository.FindOrderWithItems(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if requesterRole != "admin" && order.UserID != requesterID {
+		return nil, errs.Forbidden("you can only view your own orders", nil)
+	}
+	return order, nil
 }
 
-// Fetch all orders
-func (service *OrderService) GetAllOrders(ctx context.Context) ([]*models.Order, error) {
-	orders, err := service.repository.FetchAllOrders(ctx)
-	if err != nil {
-		return nil, errs.InternalError("failed to fetch all orders", err)
+// Fetch all orders matching the filter (admin only)
+func (service *OrderService) GetAllOrders(ctx context.Context, filter *dtos.OrderFilterDTO) ([]*models.Order, error) {
+	if filter.From != "" && filter.To != "" && filter.From > filter.To {
+		return nil, errs.BadRequest("INVALID_DATE_RANGE", nil)
 	}
-	return orders, nil
+	return service.repository.FetchAllOrders(ctx, filter)
 }
 
-// Fetch orders for a specific user
-func (service *OrderService) GetOrdersByUser(ctx context.Context, userID string) ([]*models.Order, error) {
+// Fetch orders for a specific user; customers may only list their own orders
+func (service *OrderService) GetOrdersByUser(ctx context.Context, userID string, requesterID string, requesterRole string) ([]*models.Order, error) {
 	if userID == "" {
 		return nil, errs.BadRequest("INVALID_USER_ID", nil)
 	}
+	if requesterRole != "admin" && userID != requesterID {
+		return nil, errs.Forbidden("you can only view your own orders", nil)
+	}
 	orders, err := service.repository.FetchOrdersByUserID(ctx, userID)
 	if err != nil {
 		return nil, errs.InternalError(fmt.Sprintf("failed to fetch orders for user %s", userID), err)
@@ -105,3 +112,68 @@ func (service *OrderService) RemoveOrder(ctx context.Context, orderID string) er
 	}
 	return service.repository.DeleteOrderByID(ctx, orderID)
 }
+
+// UpdateOrderStatus moves an order through its lifecycle
+// (pending -> paid -> shipped -> delivered, with cancellation from pending or paid).
+// Only sellers and admins ship or deliver, only admins mark orders paid manually and
+// customers may cancel their own orders while they are still pending. A paid order is only
+// cancelled once its payment has been refunded.
+func (service *OrderService) UpdateOrderStatus(ctx context.Context, orderID string, requesterID string, requesterRole string, dto *dtos.UpdateOrderStatusDTO) (*models.OrderWithItems, error) {
+	if orderID == "" {
+		return nil, errs.BadRequest("INVALID_ORDER_ID", nil)
+	}
+	order, err := service.repository.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkOrderStatusChange(order, dto.Status, requesterID, requesterRole); err != nil {
+		return nil, err
+	}
+
+	restoreStock := dto.Status == models.OrderStatusCancelled
+	if err := service.repository.ChangeOrderStatus(ctx, orderID, order.Status, dto.Status, &requesterID, dto.Reason, restoreStock); err != nil {
+		return nil, err
+	}
+	return service.repository.FindOrderWithItems(ctx, orderID)
+}
+
+// GetOrderStatusHistory returns the recorded status changes of an order; like the order
+// itself it is only visible to its owner and admins
+func (service *OrderService) GetOrderStatusHistory(ctx context.Context, orderID string, requesterID string, requesterRole string) ([]*models.OrderStatusHistory, error) {
+	order, err := service.repository.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if requesterRole != "admin" && order.UserID != requesterID {
+		return nil, errs.Forbidden("you can only view your own orders", nil)
+	}
+	return service.repository.FetchOrderStatusHistory(ctx, orderID)
+}
+
+func checkOrderStatusChange(order *models.Order, toStatus string, requesterID string, requesterRole string) error {
+	if !slices.Contains(models.OrderStatusTransitions[order.Status], toStatus) {
+		return errs.UnprocessableEntity(fmt.Sprintf("cannot move order from %s to %s", order.Status, toStatus), nil)
+	}
+
+	switch toStatus {
+	case models.OrderStatusPaid:
+		if requesterRole != "admin" {
+			return errs.Forbidden("only admins can mark orders as paid", nil)
+		}
+	case models.OrderStatusShipped, models.OrderStatusDelivered:
+		if requesterRole != "seller" && requesterRole != "admin" {
+			return errs.Forbidden("only sellers and admins can ship or deliver orders", nil)
+		}
+	case models.OrderStatusCancelled:
+		if requesterRole == "customer" {
+			if order.UserID != requesterID {
+				return errs.Forbidden("you can only cancel your own orders", nil)
+			}
+			if order.Status != models.OrderStatusPending {
+				return errs.UnprocessableEntity("orders can only be cancelled while pending", nil)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/payments"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+type PaymentService struct {
+	paymentRepo     *repositories.PaymentRepository
+	orderRepo       *repositories.OrderRepository
+	providers       *payments.Registry
+	callbackBaseURL string
+	returnBaseURL   string
+}
+
+// NewPaymentService creates the payment service. Provider callbacks are sent to
+// callbackBaseURL/<provider> and customers are sent back to returnBaseURL/<order id>.
+func NewPaymentService(
+	paymentRepo *repositories.PaymentRepository,
+	orderRepo *repositories.OrderRepository,
+	providers *payments.Registry,
+	callbackBaseURL string,
+	returnBaseURL string,
+) *PaymentService {
+	return &PaymentService{
+		paymentRepo:     paymentRepo,
+		orderRepo:       orderRepo,
+		providers:       providers,
+		callbackBaseURL: callbackBaseURL,
+		returnBaseURL:   returnBaseURL,
+	}
+}
+
+func providerError(err error) *errs.AppError {
+	return errs.NewAppError(http.StatusBadGateway, "PAYMENT_PROVIDER_ERROR", err, "BAD_GATEWAY")
+}
+
+// InitiatePayment starts collecting payment for a pending order through the provider of
+// the order's payment method. Calling it again while the payment is pending returns the
+// existing intent so the customer can resume the provider checkout.
+func (s *PaymentService) InitiatePayment(ctx context.Context, orderID string, userID string) (*models.PaymentIntent, error) {
+	order, err := s.orderRepo.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order.UserID != userID {
+		return nil, errs.Forbidden("you can only pay for your own orders", nil)
+	}
+	if order.Status != models.OrderStatusPending {
+		return nil, errs.UnprocessableEntity("ORDER_NOT_AWAITING_PAYMENT", nil)
+	}
+
+	existing, err := s.paymentRepo.FindLiveIntentByOrderID(ctx, orderID)
+	if err == nil {
+		if existing.Status == string(payments.StatusSucceeded) {
+			return nil, errs.Conflict("ORDER_ALREADY_PAID", nil)
+		}
+		if existing.CheckoutURL != nil {
+			return existing, nil
+		}
+		// a previous attempt never reached the provider; give up on it and start over
+		if err := s.paymentRepo.MarkIntentFailed(ctx, existing.IntentID); err != nil {
+			return nil, err
+		}
+	} else {
+		var appErr *errs.AppError
+		if !errors.As(err, &appErr) || appErr.StatusCode != http.StatusNotFound {
+			return nil, err
+		}
+	}
+
+	provider, err := s.providers.Provider(order.PaymentMethod)
+	if err != nil {
+		return nil, errs.UnprocessableEntity("PAYMENT_METHOD_NOT_SUPPORTED", err)
+	}
+
+	intent, err := s.paymentRepo.CreateIntent(ctx, order.OrderID, provider.Name(), order.TotalAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := provider.Initiate(ctx, &payments.InitiateRequest{
+		IntentID:    intent.IntentID,
+		OrderID:     order.OrderID,
+		Amount:      order.TotalAmount,
+		Currency:    "ETB",
+		Description: fmt.Sprintf("Sanqa Suq order %s", order.OrderID),
+		CallbackURL: fmt.Sprintf("%s/%s", s.callbackBaseURL, provider.Name()),
+		ReturnURL:   fmt.Sprintf("%s/%s", s.returnBaseURL, order.OrderID),
+	})
+	if err != nil {
+		if markErr := s.paymentRepo.MarkIntentFailed(ctx, intent.IntentID); markErr != nil {
+			return nil, markErr
+		}
+		return nil, providerError(err)
+	}
+
+	return s.paymentRepo.SetProviderDetails(ctx, intent.IntentID, result.ProviderReference, result.CheckoutURL)
+}
+
+// HandleCallback processes an asynchronous notification from a provider. The callback is
+// only used to find the payment; its outcome is always confirmed with the provider.
+func (s *PaymentService) HandleCallback(ctx context.Context, providerName string, body []byte, header http.Header) (*models.PaymentIntent, error) {
+	provider, err := s.providers.Provider(providerName)
+	if err != nil {
+		return nil, errs.NotFound("PAYMENT_PROVIDER_NOT_FOUND", err)
+	}
+
+	event, err := provider.ParseCallback(body, header)
+	if err != nil {
+		if errors.Is(err, payments.ErrInvalidSignature) {
+			return nil, errs.Unauthorized("INVALID_CALLBACK_SIGNATURE", err)
+		}
+		return nil, errs.BadRequest("INVALID_CALLBACK", err)
+	}
+
+	intent, err := s.paymentRepo.FindIntentByReference(ctx, provider.Name(), event.ProviderReference)
+	if err != nil {
+		return nil, err
+	}
+	return s.settle(ctx, provider, intent)
+}
+
+// VerifyPayment asks the provider for the current state of an order's payment
+func (s *PaymentService) VerifyPayment(ctx context.Context, orderID string, userID string, role string) (*models.PaymentIntent, error) {
+	intent, err := s.GetPayment(ctx, orderID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != string(payments.StatusPending) || intent.ProviderReference == nil {
+		return intent, nil
+	}
+
+	provider, err := s.providers.Provider(intent.Provider)
+	if err != nil {
+		return nil, errs.UnprocessableEntity("PAYMENT_METHOD_NOT_SUPPORTED", err)
+	}
+	return s.settle(ctx, provider, intent)
+}
+
+func (s *PaymentService) GetPayment(ctx context.Context, orderID string, userID string, role string) (*models.PaymentIntent, error) {
+	order, err := s.orderRepo.FindOrderByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if role != "admin" && order.UserID != userID {
+		return nil, errs.Forbidden("you can only view payments of your own orders", nil)
+	}
+	return s.paymentRepo.FindLiveIntentByOrderID(ctx, orderID)
+}
+
+// RefundPayment returns a settled payment to the customer (admin only)
+func (s *PaymentService) RefundPayment(ctx context.Context, orderID string) (*models.PaymentIntent, error) {
+	intent, err := s.paymentRepo.FindLiveIntentByOrderID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if intent.Status != string(payments.StatusSucceeded) || intent.ProviderReference == nil {
+		return nil, errs.Conflict("PAYMENT_NOT_REFUNDABLE", nil)
+	}
+
+	provider, err := s.providers.Provider(intent.Provider)
+	if err != nil {
+		return nil, errs.UnprocessableEntity("PAYMENT_METHOD_NOT_SUPPORTED", err)
+	}
+	return s.refund(ctx, provider, intent, intent.Amount)
+}
+
+// refund returns amount of an intent's payment to the customer and records it
+func (s *PaymentService) refund(ctx context.Context, provider payments.PaymentProvider, intent *models.PaymentIntent, amount float64) (*models.PaymentIntent, error) {
+	result, err := provider.Refund(ctx, *intent.ProviderReference, amount)
+	if err != nil {
+		return nil, providerError(err)
+	}
+	return s.paymentRepo.MarkIntentRefunded(ctx, intent.IntentID, result.RefundReference)
+}
+
+// settle verifies a pending intent with its provider and records the outcome,
+// moving the order to paid once the provider confirms the full amount. Money taken that
+// cannot pay the order (the wrong amount, an abandoned attempt or a cancelled order) is
+// refunded; if the refund fails the intent stays succeeded for an admin to refund.
+func (s *PaymentService) settle(ctx context.Context, provider payments.PaymentProvider, intent *models.PaymentIntent) (*models.PaymentIntent, error) {
+	if intent.ProviderReference == nil ||
+		(intent.Status != string(payments.StatusPending) && intent.Status != string(payments.StatusFailed)) {
+		return intent, nil
+	}
+
+	result, err := provider.Verify(ctx, *intent.ProviderReference)
+	if err != nil {
+		return nil, providerError(err)
+	}
+
+	switch result.Status {
+	case payments.StatusSucceeded:
+		if intent.Status == string(payments.StatusFailed) {
+			// the customer completed an attempt we had already given up on
+			return s.refund(ctx, provider, intent, result.Amount)
+		}
+		if math.Abs(result.Amount-intent.Amount) > 0.005 {
+			if err := s.paymentRepo.MarkIntentFailed(ctx, intent.IntentID); err != nil {
+				return nil, err
+			}
+			if _, err := s.refund(ctx, provider, intent, result.Amount); err != nil {
+				return nil, err
+			}
+			return nil, errs.UnprocessableEntity("PAYMENT_AMOUNT_MISMATCH", nil)
+		}
+		paidAt := time.Now()
+		if result.PaidAt != nil {
+			paidAt = *result.PaidAt
+		}
+		settled, orderPaid, err := s.paymentRepo.MarkIntentSucceeded(ctx, intent, paidAt)
+		if err != nil {
+			return nil, err
+		}
+		if settled && !orderPaid {
+			// the order was cancelled while the customer was paying
+			return s.refund(ctx, provider, intent, result.Amount)
+		}
+		if !settled {
+			// settled by an earlier callback, or given up on by a cancellation in between
+			current, err := s.paymentRepo.FindIntentByReference(ctx, intent.Provider, *intent.ProviderReference)
+			if err != nil {
+				return nil, err
+			}
+			if current.Status == string(payments.StatusFailed) {
+				return s.refund(ctx, provider, current, result.Amount)
+			}
+			return current, nil
+		}
+	case payments.StatusFailed:
+		if err := s.paymentRepo.MarkIntentFailed(ctx, intent.IntentID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.paymentRepo.FindIntentByReference(ctx, intent.Provider, *intent.ProviderReference)
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"math"
+
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+)
+
+const (
+	// tdpSpec and psuWattageSpec are the specifications the power budget is read from
+	tdpSpec        = "tdp_watts"
+	psuWattageSpec = "psu_wattage"
+
+	// baseSystemWatts covers fans, drives and peripherals, which rarely list a TDP
+	baseSystemWatts = 50.0
+	// typicalLoadFactor is the share of the peak drawn under everyday use
+	typicalLoadFactor = 0.6
+	// psuHeadroomPercent keeps the PSU off its limit at peak, where it is least efficient
+	psuHeadroomPercent = 30.0
+	// psuWattageStep rounds the recommendation up to the sizes PSUs are sold in
+	psuWattageStep = 50.0
+)
+
+// AnalyzeBuild estimates the power draw of a build from the tdp_watts of its items and
+// checks its power supplies, the items with a psu_wattage, against the recommended size
+func (s *BuildService) AnalyzeBuild(ctx context.Context, buildID string, viewerID string) (*models.BuildPowerAnalysis, error) {
+	build, err := s.GetBuildByID(ctx, buildID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	quantities := make(map[int]int, len(build.Items))
+	for _, item := range build.Items {
+		quantities[item.ProductID] += item.Quantity
+	}
+	components, err := fetchBuildComponents(ctx, s.compatibilityRepo, quantities)
+	if err != nil {
+		return nil, err
+	}
+
+	analysis := &models.BuildPowerAnalysis{
+		BuildID:         build.BuildID,
+		Components:      []models.ComponentPower{},
+		BaseSystemWatts: baseSystemWatts,
+		PeakWatts:       baseSystemWatts,
+		HeadroomPercent: psuHeadroomPercent,
+	}
+	var psu *models.PSUCheck
+	for _, component := range components {
+		if wattage, ok := leadingNumber(component.Specs[psuWattageSpec]); ok {
+			if psu == nil {
+				psu = &models.PSUCheck{ProductIDs: []int{}}
+			}
+			psu.ProductIDs = append(psu.ProductIDs, component.ProductID)
+			psu.WattageWatts += wattage * float64(component.Quantity)
+			continue
+		}
+
+		power := models.ComponentPower{
+			ProductID:   component.ProductID,
+			ProductName: component.Name,
+			Quantity:    component.Quantity,
+		}
+		if tdp, ok := leadingNumber(component.Specs[tdpSpec]); ok {
+			power.TDPWatts = &tdp
+			power.PeakWatts = tdp * float64(component.Quantity)
+			power.TypicalWatts = power.PeakWatts * typicalLoadFactor
+		}
+		analysis.PeakWatts += power.PeakWatts
+		analysis.Components = append(analysis.Components, power)
+	}
+	analysis.TypicalWatts = analysis.PeakWatts * typicalLoadFactor
+	analysis.RecommendedPSUWatts = math.Ceil(analysis.PeakWatts*(1+psuHeadroomPercent/100)/psuWattageStep) * psuWattageStep
+
+	if psu != nil {
+		switch {
+		case psu.WattageWatts < analysis.PeakWatts:
+			psu.Status = models.PSUStatusUndersized
+		case psu.WattageWatts < analysis.RecommendedPSUWatts:
+			psu.Status = models.PSUStatusBelowRecommended
+		default:
+			psu.Status = models.PSUStatusOK
+		}
+		psu.ShortfallWatts = math.Max(0, analysis.RecommendedPSUWatts-psu.WattageWatts)
+		analysis.PSU = psu
+	}
+	return analysis, nil
+}
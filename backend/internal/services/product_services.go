@@ -3,6 +3,8 @@ package services
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
 	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
@@ -10,6 +12,8 @@ import (
 	"github.com/amha-mersha/sanqa-suq/internal/repositories"
 )
 
+const defaultSearchPageSize = 20
+
 type ProductService struct {
 	repository *repositories.ProductRepository
 }
@@ -62,13 +66,6 @@ func (service *ProductService) UpdateProduct(ctx context.Context, productId int,
 		}
 		updateFields["price"] = *dto.Price
 	}
-	if dto.StockQuantity != nil {
-		if *dto.StockQuantity < 0 {
-			return errs.BadRequest("INVALID_STOCK_QUANTITY", nil)
-		}
-		updateFields["stock_quantity"] = *dto.StockQuantity
-	}
-
 	if len(updateFields) == 0 {
 		return nil
 	}
@@ -76,11 +73,24 @@ func (service *ProductService) UpdateProduct(ctx context.Context, productId int,
 	return service.repository.UpdateProduct(ctx, productId, updateFields)
 }
 
+// GetProduct returns a product with its specifications inline
 func (service *ProductService) GetProduct(ctx context.Context, productId int) (*models.Products, error) {
 	if productId < 0 {
 		return nil, errs.BadRequest("INVALID_PRODUCT_ID", nil)
 	}
-	return service.repository.FindProductByID(ctx, productId)
+	product, err := service.repository.FindProductByID(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	specifications, err := service.repository.GetProductSpecifications(ctx, productId)
+	if err != nil {
+		return nil, err
+	}
+	product.Specifications = make(map[string]string, len(specifications))
+	for _, spec := range specifications {
+		product.Specifications[spec.SpecName] = spec.SpecValue
+	}
+	return product, nil
 }
 
 func (service *ProductService) GetAllProducts(ctx context.Context) ([]*models.Products, error) {
@@ -109,12 +119,89 @@ func (service *ProductService) GetProductSpecifications(ctx context.Context, pro
 	if productId < 0 {
 		return nil, errs.BadRequest("INVALID_PRODUCT_ID", nil)
 	}
-	product, err := service.repository.FindProductByID(ctx, productId)
-	if err != nil {
-		return nil, errs.InternalError("failed to fetch product specifications", err)
+	return service.GetProduct(ctx, productId)
+}
+
+// SearchProducts runs a filtered, faceted product search. Without a keyword, relevance
+// has nothing to rank by and results fall back to newest first.
+func (service *ProductService) SearchProducts(ctx context.Context, filter *dtos.ProductSearchDTO) (*models.ProductSearchResult, error) {
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, errs.BadRequest("INVALID_PRICE_RANGE", nil)
 	}
-	if product == nil {
-		return nil, errs.NotFound("PRODUCT_NOT_FOUND", nil)
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Sort == "" || (filter.Sort == "relevance" && filter.Query == "") {
+		if filter.Query != "" {
+			filter.Sort = "relevance"
+		} else {
+			filter.Sort = "newest"
+		}
 	}
-	return product, nil
+	if filter.Limit == 0 {
+		filter.Limit = defaultSearchPageSize
+	}
+
+	filter.SpecFilters = make(map[string][]string)
+	for _, spec := range filter.Specs {
+		name, value, _ := strings.Cut(spec, ":")
+		name = strings.ToLower(strings.TrimSpace(name))
+		value = strings.ToLower(strings.TrimSpace(value))
+		if name == "" || value == "" {
+			return nil, errs.BadRequest("INVALID_SPEC_FILTER", fmt.Errorf("spec filter %q must look like name:value", spec))
+		}
+		filter.SpecFilters[name] = append(filter.SpecFilters[name], value)
+	}
+
+	filter.SpecRangeFilters = make(map[string]dtos.SpecRange)
+	for _, spec := range filter.SpecRanges {
+		name, specRange, err := parseSpecRange(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := filter.SpecRangeFilters[name]; ok {
+			return nil, errs.BadRequest("INVALID_SPEC_RANGE", fmt.Errorf("spec %s has more than one range", name))
+		}
+		filter.SpecRangeFilters[name] = specRange
+	}
+
+	return service.repository.SearchProducts(ctx, filter)
+}
+
+// parseSpecRange reads a name:min..max range filter; one of the ends may be empty
+func parseSpecRange(spec string) (string, dtos.SpecRange, error) {
+	invalid := errs.BadRequest("INVALID_SPEC_RANGE", fmt.Errorf("spec range %q must look like name:min..max", spec))
+
+	name, bounds, _ := strings.Cut(spec, ":")
+	name = strings.ToLower(strings.TrimSpace(name))
+	low, high, ok := strings.Cut(bounds, "..")
+	if name == "" || !ok {
+		return "", dtos.SpecRange{}, invalid
+	}
+
+	minValue, minErr := parseRangeBound(low)
+	maxValue, maxErr := parseRangeBound(high)
+	if minErr != nil || maxErr != nil {
+		return "", dtos.SpecRange{}, invalid
+	}
+	specRange := dtos.SpecRange{Min: minValue, Max: maxValue}
+	if specRange.Min == nil && specRange.Max == nil {
+		return "", dtos.SpecRange{}, invalid
+	}
+	if specRange.Min != nil && specRange.Max != nil && *specRange.Min > *specRange.Max {
+		return "", dtos.SpecRange{}, invalid
+	}
+	return name, specRange, nil
+}
+
+// parseRangeBound reads one end of a range; an empty end is open and returns nil
+func parseRangeBound(text string) (*float64, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &value, nil
 }
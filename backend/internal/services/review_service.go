@@ -3,6 +3,9 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/amha-mersha/sanqa-suq/internal/dtos"
 	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
@@ -10,13 +13,63 @@ import (
 	"github.com/amha-mersha/sanqa-suq/internal/repositories"
 )
 
+const (
+	defaultReviewPageSize     = 10
+	defaultModerationPageSize = 50
+)
+
+// moderationActions maps the actions a moderator can take onto their log entries
+var moderationActions = map[string]string{
+	"approve": models.ModerationActionApproved,
+	"hide":    models.ModerationActionHidden,
+	"delete":  models.ModerationActionDeleted,
+}
+
 type ReviewService struct {
 	repository *repositories.ReviewRepository
+	blocklist  *regexp.Regexp
 }
 
-func (service *ReviewService) AddNewReview(ctx context.Context, reviewDTO *dtos.CreateReviewDTO) (*models.Review, error) {
+// NewReviewService creates the review service. Reviews whose text contains one of the
+// blocklisted terms (whole words, case-insensitive) are held for moderation.
+func NewReviewService(repository *repositories.ReviewRepository, blocklist []string) *ReviewService {
+	return &ReviewService{
+		repository: repository,
+		blocklist:  compileBlocklist(blocklist),
+	}
+}
 
-	if reviewDTO.UserID == "" {
+func compileBlocklist(terms []string) *regexp.Regexp {
+	quoted := []string{}
+	for _, term := range terms {
+		if term = strings.TrimSpace(term); term != "" {
+			quoted = append(quoted, regexp.QuoteMeta(term))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+	// \b only knows ASCII word characters, so spell out the boundaries to cover Amharic too
+	return regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])(` + strings.Join(quoted, "|") + `)(?:$|[^\p{L}\p{N}])`)
+}
+
+// holdReason returns why a review text has to wait for a moderator, or nil when it can be published
+func (service *ReviewService) holdReason(text string) *string {
+	if service.blocklist == nil {
+		return nil
+	}
+	match := service.blocklist.FindStringSubmatch(text)
+	if match == nil {
+		return nil
+	}
+	reason := fmt.Sprintf("contains blocked term %q", strings.ToLower(match[1]))
+	return &reason
+}
+
+// AddNewReview records a review written by the authenticated user. Each user reviews a
+// product once; a second review of the same product is rejected in favour of editing.
+func (service *ReviewService) AddNewReview(ctx context.Context, userID string, reviewDTO *dtos.CreateReviewDTO) (*models.Review, error) {
+	if userID == "" {
 		return nil, errs.BadRequest("REVIEW_USER_ID_REQUIRED", nil)
 	}
 	if reviewDTO.ProductID == 0 {
@@ -26,10 +79,11 @@ func (service *ReviewService) AddNewReview(ctx context.Context, reviewDTO *dtos.
 		return nil, errs.BadRequest("REVIEW_RATING_INVALID", nil)
 	}
 
-	return service.repository.CreateNewReview(ctx, reviewDTO)
+	return service.repository.CreateNewReview(ctx, userID, reviewDTO, service.holdReason(reviewDTO.Comment))
 }
 
-func (service *ReviewService) UpdateReview(ctx context.Context, reviewID string, dto *dtos.UpdateReviewDTO) (*models.Review, error) {
+// UpdateReview edits a review; only its author may change it
+func (service *ReviewService) UpdateReview(ctx context.Context, reviewID string, requesterID string, dto *dtos.UpdateReviewDTO) (*models.Review, error) {
 	updateFields := make(map[string]any)
 
 	if dto.Rating != nil {
@@ -47,19 +101,154 @@ func (service *ReviewService) UpdateReview(ctx context.Context, reviewID string,
 		return nil, errs.BadRequest("NO_FIELDS_TO_UPDATE", errors.New("no valid fields provided for update"))
 	}
 
-	return service.repository.UpdateReview(ctx, reviewID, dto)
+	review, err := service.repository.FindReviewByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserId != requesterID {
+		return nil, errs.Forbidden("you can only edit your own reviews", nil)
+	}
+
+	// an edited comment goes through the blocklist again; a hidden review stays hidden
+	var hold *string
+	if dto.Comment != nil && review.Status != models.ReviewStatusHidden {
+		hold = service.holdReason(*dto.Comment)
+	}
+
+	return service.repository.UpdateReview(ctx, reviewID, dto, hold)
 }
 
+// GetReview returns a published review
 func (service *ReviewService) GetReview(ctx context.Context, reviewID string) (*models.Review, error) {
 	if reviewID == "" {
 		return nil, errs.BadRequest("INVALID_REVIEW_ID", nil)
 	}
-	return service.repository.FindReviewByID(ctx, reviewID)
+	return service.findPublishedReview(ctx, reviewID)
+}
+
+func (service *ReviewService) findPublishedReview(ctx context.Context, reviewID string) (*models.Review, error) {
+	review, err := service.repository.FindReviewByID(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.Status != models.ReviewStatusPublished {
+		return nil, errs.NotFound(fmt.Sprintf("review with id %s not found", reviewID), nil)
+	}
+	return review, nil
 }
 
-func (service *ReviewService) RemoveReview(ctx context.Context, reviewID string) error {
+// RemoveReview deletes a review; authors may delete their own reviews and admins any review
+func (service *ReviewService) RemoveReview(ctx context.Context, reviewID string, requesterID string, requesterRole string) error {
 	if reviewID == "" {
 		return errs.BadRequest("INVALID_REVIEW_ID", nil)
 	}
+
+	review, err := service.repository.FindReviewByID(ctx, reviewID)
+	if err != nil {
+		return err
+	}
+	if requesterRole != "admin" && review.UserId != requesterID {
+		return errs.Forbidden("you can only delete your own reviews", nil)
+	}
+
 	return service.repository.DeleteReviewByID(ctx, reviewID)
 }
+
+// GetProductReviews returns a page of a product's reviews along with its rating summary
+func (service *ReviewService) GetProductReviews(ctx context.Context, productID int, filter *dtos.ReviewFilterDTO) (*models.ProductReviewList, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultReviewPageSize
+	}
+	if filter.Sort == "" {
+		filter.Sort = "newest"
+	}
+
+	summary, err := service.repository.FetchRatingSummary(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	matching := summary.ReviewCount
+	if filter.VerifiedOnly {
+		matching = summary.VerifiedCount
+	}
+
+	reviews := []*models.ProductReview{}
+	if matching > filter.Offset {
+		reviews, err = service.repository.FetchProductReviews(ctx, productID, filter)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &models.ProductReviewList{
+		Summary: *summary,
+		Reviews: reviews,
+		Limit:   filter.Limit,
+		Offset:  filter.Offset,
+	}, nil
+}
+
+// ReportReview flags a published review for the moderators
+func (service *ReviewService) ReportReview(ctx context.Context, reviewID string, userID string, dto *dtos.ReportReviewDTO) (*models.ReviewReport, error) {
+	review, err := service.findPublishedReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserId == userID {
+		return nil, errs.UnprocessableEntity("CANNOT_REPORT_OWN_REVIEW", nil)
+	}
+	return service.repository.InsertReviewReport(ctx, reviewID, userID, dto)
+}
+
+// VoteHelpful marks a published review as helpful and returns it with the new vote count
+func (service *ReviewService) VoteHelpful(ctx context.Context, reviewID string, userID string) (*models.Review, error) {
+	review, err := service.findPublishedReview(ctx, reviewID)
+	if err != nil {
+		return nil, err
+	}
+	if review.UserId == userID {
+		return nil, errs.UnprocessableEntity("CANNOT_VOTE_OWN_REVIEW", nil)
+	}
+	if err := service.repository.AddHelpfulVote(ctx, reviewID, userID); err != nil {
+		return nil, err
+	}
+	return service.repository.FindReviewByID(ctx, reviewID)
+}
+
+// RemoveHelpfulVote withdraws the user's helpful vote and returns the review
+func (service *ReviewService) RemoveHelpfulVote(ctx context.Context, reviewID string, userID string) (*models.Review, error) {
+	if _, err := service.findPublishedReview(ctx, reviewID); err != nil {
+		return nil, err
+	}
+	if err := service.repository.RemoveHelpfulVote(ctx, reviewID, userID); err != nil {
+		return nil, err
+	}
+	return service.repository.FindReviewByID(ctx, reviewID)
+}
+
+// GetModerationQueue lists reviews waiting for a moderator (admin only)
+func (service *ReviewService) GetModerationQueue(ctx context.Context, filter *dtos.ModerationQueueFilterDTO) ([]*models.ModerationQueueItem, error) {
+	if filter.Limit == 0 {
+		filter.Limit = defaultModerationPageSize
+	}
+	return service.repository.FetchModerationQueue(ctx, filter)
+}
+
+// ModerateReview approves, hides or deletes a review (admin only). Hiding and deleting
+// must be justified with a reason. Deleting returns a nil review.
+func (service *ReviewService) ModerateReview(ctx context.Context, reviewID string, moderatorID string, dto *dtos.ModerateReviewDTO) (*models.Review, error) {
+	action, ok := moderationActions[dto.Action]
+	if !ok {
+		return nil, errs.BadRequest("INVALID_MODERATION_ACTION", nil)
+	}
+
+	var reason *string
+	if trimmed := strings.TrimSpace(dto.Reason); trimmed != "" {
+		reason = &trimmed
+	} else if action != models.ModerationActionApproved {
+		return nil, errs.BadRequest("MODERATION_REASON_REQUIRED", nil)
+	}
+
+	return service.repository.ModerateReview(ctx, reviewID, action, reason, moderatorID)
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+)
+
+// ruleOperatorSymbols are used to spell out what a rule requires
+var ruleOperatorSymbols = map[string]string{
+	models.RuleOperatorEquals: "=",
+	models.RuleOperatorInSet:  "one of",
+	models.RuleOperatorGTE:    "≥",
+	models.RuleOperatorLTE:    "≤",
+}
+
+// evaluateRules runs every rule against the components of a build
+func evaluateRules(rules []*models.CompatibilityRule, components []*models.BuildComponent) []models.RuleResult {
+	results := make([]models.RuleResult, 0, len(rules))
+	for _, rule := range rules {
+		results = append(results, evaluateRule(rule, components))
+	}
+	return results
+}
+
+// evaluateRule checks a rule for every component matching its subject
+func evaluateRule(rule *models.CompatibilityRule, components []*models.BuildComponent) models.RuleResult {
+	result := models.RuleResult{
+		RuleID:     rule.RuleID,
+		Name:       rule.Name,
+		Severity:   rule.Severity,
+		Status:     models.RuleStatusNotApplicable,
+		Violations: []models.RuleViolation{},
+	}
+
+	for _, subject := range components {
+		if !matchesRuleSelector(subject, rule.SubjectProductID, rule.SubjectCategoryID, rule.SubjectFilterSpec, rule.SubjectFilterValue) {
+			continue
+		}
+		expected, ok := ruleExpectedValue(rule, subject)
+		if !ok {
+			continue
+		}
+
+		targets := []*models.BuildComponent{}
+		for _, component := range components {
+			if component != subject &&
+				matchesRuleSelector(component, nil, rule.TargetCategoryID, rule.TargetFilterSpec, rule.TargetFilterValue) {
+				targets = append(targets, component)
+			}
+		}
+
+		var checked bool
+		var violations []models.RuleViolation
+		switch {
+		case rule.TargetAggregate != nil:
+			checked, violations = checkAggregate(rule, subject, expected, targets)
+		case rule.Quantifier == models.RuleQuantifierAny:
+			checked, violations = checkAnyTarget(rule, subject, expected, targets)
+		default:
+			checked, violations = checkEveryTarget(rule, subject, expected, targets)
+		}
+
+		for i := range violations {
+			violations[i].ProductIDs = violatingProducts(violations[i])
+		}
+		if len(violations) > 0 {
+			result.Status = models.RuleStatusFailed
+			result.Violations = append(result.Violations, violations...)
+		} else if checked && result.Status == models.RuleStatusNotApplicable {
+			result.Status = models.RuleStatusPassed
+		}
+	}
+	return result
+}
+
+// violatingProducts lists the subject and the targets of a violation once each
+func violatingProducts(violation models.RuleViolation) []int {
+	productIDs := []int{violation.SubjectProductID}
+	for _, productID := range violation.TargetProductIDs {
+		if !slices.Contains(productIDs, productID) {
+			productIDs = append(productIDs, productID)
+		}
+	}
+	return productIDs
+}
+
+// matchesRuleSelector tells whether a component matches a rule's subject or target selector
+func matchesRuleSelector(component *models.BuildComponent, productID *int, categoryID *int, filterSpec *string, filterValue *string) bool {
+	if productID != nil && component.ProductID != *productID {
+		return false
+	}
+	if categoryID != nil && !slices.Contains(component.CategoryIDs, *categoryID) {
+		return false
+	}
+	if filterSpec != nil && filterValue != nil {
+		value, ok := component.Specs[*filterSpec]
+		if !ok || !strings.EqualFold(strings.TrimSpace(value), *filterValue) {
+			return false
+		}
+	}
+	return true
+}
+
+// ruleExpectedValue returns the value the targets are compared against
+func ruleExpectedValue(rule *models.CompatibilityRule, subject *models.BuildComponent) (string, bool) {
+	if rule.Value != nil {
+		return *rule.Value, true
+	}
+	if rule.SubjectSpec == nil {
+		return "", false
+	}
+	value, ok := subject.Specs[*rule.SubjectSpec]
+	return value, ok
+}
+
+// leadingNumber reads the number at the start of a value such as "650" or "650 W"
+func leadingNumber(value string) (float64, bool) {
+	match := numberWithUnitPattern.FindStringSubmatch(strings.TrimSpace(value))
+	if match == nil {
+		return 0, false
+	}
+	number, err := strconv.ParseFloat(match[1], 64)
+	return number, err == nil
+}
+
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
+// compareRuleValues applies the rule operator to a target value and the expected one
+func compareRuleValues(rule *models.CompatibilityRule, actual string, expected string) (passed bool, comparable bool) {
+	switch rule.Operator {
+	case models.RuleOperatorInSet:
+		for _, option := range strings.Split(expected, ",") {
+			if strings.EqualFold(strings.TrimSpace(option), strings.TrimSpace(actual)) {
+				return true, true
+			}
+		}
+		return false, true
+
+	case models.RuleOperatorGTE, models.RuleOperatorLTE:
+		left, okLeft := leadingNumber(actual)
+		right, okRight := leadingNumber(expected)
+		if !okLeft || !okRight {
+			return false, false
+		}
+		return compareNumbers(rule.Operator, left+rule.Margin, right), true
+	}
+
+	// equals compares numbers by value, so "65" matches "65.0"
+	if left, ok := leadingNumber(actual); ok {
+		if right, ok := leadingNumber(expected); ok {
+			return left == right, true
+		}
+	}
+	return strings.EqualFold(strings.TrimSpace(actual), strings.TrimSpace(expected)), true
+}
+
+func compareNumbers(operator string, left float64, right float64) bool {
+	if operator == models.RuleOperatorGTE {
+		return left >= right
+	}
+	return left <= right
+}
+
+// ruleRequirement spells out what the targets must satisfy, e.g. "≤ 330"
+func ruleRequirement(rule *models.CompatibilityRule, expected string) string {
+	return ruleOperatorSymbols[rule.Operator] + " " + expected
+}
+
+// ruleTargetValue returns the value of a target compared by the rule, margin included
+func ruleTargetValue(rule *models.CompatibilityRule, target *models.BuildComponent) (string, bool) {
+	if rule.TargetSpec == nil {
+		return "", false
+	}
+	value, ok := target.Specs[*rule.TargetSpec]
+	if !ok {
+		return "", false
+	}
+	if rule.Margin != 0 && (rule.Operator == models.RuleOperatorGTE || rule.Operator == models.RuleOperatorLTE) {
+		if number, isNumber := leadingNumber(value); isNumber {
+			return formatNumber(number + rule.Margin), true
+		}
+	}
+	return value, true
+}
+
+// checkEveryTarget requires each target to pass; one violation is reported per failing target
+func checkEveryTarget(rule *models.CompatibilityRule, subject *models.BuildComponent, expected string, targets []*models.BuildComponent) (bool, []models.RuleViolation) {
+	checked := false
+	violations := []models.RuleViolation{}
+	for _, target := range targets {
+		actual, ok := target.Specs[*rule.TargetSpec]
+		if !ok {
+			continue
+		}
+		passed, comparable := compareRuleValues(rule, actual, expected)
+		if !comparable {
+			continue
+		}
+		checked = true
+		if passed {
+			continue
+		}
+		shown, _ := ruleTargetValue(rule, target)
+		violations = append(violations, models.RuleViolation{
+			SubjectProductID: subject.ProductID,
+			TargetProductIDs: []int{target.ProductID},
+			Required:         ruleRequirement(rule, expected),
+			Actual:           &shown,
+			Message: fmt.Sprintf("%s: %s has %s %s, needs %s (%s)",
+				rule.Name, target.Name, *rule.TargetSpec, shown, ruleRequirement(rule, expected), subject.Name),
+		})
+	}
+	return checked, violations
+}
+
+// checkAnyTarget requires at least one target to pass
+func checkAnyTarget(rule *models.CompatibilityRule, subject *models.BuildComponent, expected string, targets []*models.BuildComponent) (bool, []models.RuleViolation) {
+	targetIDs := []int{}
+	values := []string{}
+	for _, target := range targets {
+		targetIDs = append(targetIDs, target.ProductID)
+		actual, ok := target.Specs[*rule.TargetSpec]
+		if !ok {
+			continue
+		}
+		if passed, _ := compareRuleValues(rule, actual, expected); passed {
+			return true, nil
+		}
+		shown, _ := ruleTargetValue(rule, target)
+		values = append(values, shown)
+	}
+
+	violation := models.RuleViolation{
+		SubjectProductID: subject.ProductID,
+		TargetProductIDs: targetIDs,
+		Required:         ruleRequirement(rule, expected),
+		Message: fmt.Sprintf("%s: no item has %s %s (%s)",
+			rule.Name, *rule.TargetSpec, ruleRequirement(rule, expected), subject.Name),
+	}
+	if len(values) > 0 {
+		actual := strings.Join(values, ", ")
+		violation.Actual = &actual
+	}
+	return true, []models.RuleViolation{violation}
+}
+
+// checkAggregate compares the sum or count of the targets with the expected value
+func checkAggregate(rule *models.CompatibilityRule, subject *models.BuildComponent, expected string, targets []*models.BuildComponent) (bool, []models.RuleViolation) {
+	limit, ok := leadingNumber(expected)
+	if !ok {
+		return false, nil
+	}
+
+	total := 0.0
+	counted := []int{}
+	for _, target := range targets {
+		if *rule.TargetAggregate == models.RuleAggregateCount {
+			total += float64(target.Quantity)
+			counted = append(counted, target.ProductID)
+			continue
+		}
+		if value, isNumber := leadingNumber(target.Specs[*rule.TargetSpec]); isNumber {
+			total += value * float64(target.Quantity)
+			counted = append(counted, target.ProductID)
+		}
+	}
+	total += rule.Margin
+
+	if compareNumbers(rule.Operator, total, limit) {
+		return true, nil
+	}
+
+	described := "number of items"
+	if *rule.TargetAggregate == models.RuleAggregateSum {
+		described = "total " + *rule.TargetSpec
+	}
+	if rule.Margin != 0 {
+		described += " with a margin of " + formatNumber(rule.Margin)
+	}
+	actual := formatNumber(total)
+	required := ruleRequirement(rule, formatNumber(limit))
+	return true, []models.RuleViolation{{
+		SubjectProductID: subject.ProductID,
+		TargetProductIDs: counted,
+		Required:         required,
+		Actual:           &actual,
+		Message:          fmt.Sprintf("%s: %s is %s, needs %s (%s)", rule.Name, described, actual, required, subject.Name),
+	}}
+}
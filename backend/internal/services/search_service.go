@@ -0,0 +1,41 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+const (
+	defaultSuggestionLimit = 8
+	// brands and categories are shown above the products, so only a few of each
+	maxGroupSuggestions = 3
+	// trigram matching needs a couple of characters before it says anything useful
+	minSuggestionQueryLength = 2
+)
+
+type SearchService struct {
+	repository *repositories.SearchRepository
+}
+
+func NewSearchService(repository *repositories.SearchRepository) *SearchService {
+	return &SearchService{repository: repository}
+}
+
+// Suggest returns type-ahead suggestions for the search box
+func (s *SearchService) Suggest(ctx context.Context, dto *dtos.SearchSuggestDTO) ([]*models.SearchSuggestion, error) {
+	text := strings.Join(strings.Fields(dto.Query), " ")
+	if utf8.RuneCountInString(text) < minSuggestionQueryLength {
+		return []*models.SearchSuggestion{}, nil
+	}
+
+	limit := dto.Limit
+	if limit == 0 {
+		limit = defaultSuggestionLimit
+	}
+	return s.repository.FetchSuggestions(ctx, text, limit, min(limit, maxGroupSuggestions))
+}
@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	errs "github.com/amha-mersha/sanqa-suq/internal/errors"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+// specNamePattern keeps spec names in snake_case so the compatibility rules can rely on them
+var specNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+const maxSpecValueLength = 255
+
+type SpecService struct {
+	specRepo    *repositories.SpecRepository
+	productRepo *repositories.ProductRepository
+}
+
+func NewSpecService(specRepo *repositories.SpecRepository, productRepo *repositories.ProductRepository) *SpecService {
+	return &SpecService{
+		specRepo:    specRepo,
+		productRepo: productRepo,
+	}
+}
+
+func normalizeSpecName(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if len(name) > 100 || !specNamePattern.MatchString(name) {
+		return "", errs.BadRequest("INVALID_SPEC_NAME", fmt.Errorf("spec name %q must be snake_case", name))
+	}
+	return name, nil
+}
+
+func normalizeSpecValue(name, value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || len(value) > maxSpecValueLength {
+		return "", errs.BadRequest("INVALID_SPEC_VALUE", fmt.Errorf("value of spec %s must be 1-%d characters", name, maxSpecValueLength))
+	}
+	return value, nil
+}
+
+// GetCategoryDefinitions returns the spec definitions of a category including inherited ones
+func (s *SpecService) GetCategoryDefinitions(ctx context.Context, categoryID int) ([]*models.SpecDefinition, error) {
+	return s.specRepo.FetchCategoryDefinitions(ctx, categoryID)
+}
+
+// SetCategoryDefinitions replaces the spec definitions a category declares (admin only)
+func (s *SpecService) SetCategoryDefinitions(ctx context.Context, categoryID int, dto *dtos.SetCategorySpecDefinitionsDTO) ([]*models.SpecDefinition, error) {
+	seen := make(map[string]bool)
+	for i := range dto.Definitions {
+		def := &dto.Definitions[i]
+		name, err := normalizeSpecName(def.SpecName)
+		if err != nil {
+			return nil, err
+		}
+		if seen[name] {
+			return nil, errs.BadRequest("DUPLICATE_SPEC_NAME", fmt.Errorf("spec %s is listed twice", name))
+		}
+		seen[name] = true
+		def.SpecName = name
+		def.Description = strings.TrimSpace(def.Description)
+		def.Unit = strings.TrimSpace(def.Unit)
+		if def.DataType == "" {
+			def.DataType = models.SpecTypeText
+		}
+
+		if def.Unit != "" && def.DataType != models.SpecTypeInteger && def.DataType != models.SpecTypeDecimal {
+			return nil, errs.BadRequest("UNIT_ONLY_FOR_NUMERIC_SPECS", fmt.Errorf("spec %s is %s and cannot have a unit", name, def.DataType))
+		}
+		if def.DataType != models.SpecTypeEnum {
+			if len(def.AllowedValues) > 0 {
+				return nil, errs.BadRequest("ALLOWED_VALUES_ONLY_FOR_ENUM_SPECS", fmt.Errorf("spec %s is %s and cannot list allowed values", name, def.DataType))
+			}
+			continue
+		}
+		if def.AllowedValues, err = normalizeAllowedValues(name, def.AllowedValues); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.specRepo.ReplaceCategoryDefinitions(ctx, categoryID, dto.Definitions); err != nil {
+		return nil, err
+	}
+	return s.specRepo.FetchCategoryDefinitions(ctx, categoryID)
+}
+
+// normalizeAllowedValues trims the values of an enum spec; values differing only in case
+// are duplicates, since product values are matched case-insensitively
+func normalizeAllowedValues(name string, values []string) ([]string, error) {
+	if len(values) == 0 {
+		return nil, errs.BadRequest("ENUM_VALUES_REQUIRED", fmt.Errorf("enum spec %s needs allowed values", name))
+	}
+	seen := make(map[string]bool, len(values))
+	normalized := make([]string, 0, len(values))
+	for _, value := range values {
+		value, err := normalizeSpecValue(name, value)
+		if err != nil {
+			return nil, err
+		}
+		if key := strings.ToLower(value); !seen[key] {
+			seen[key] = true
+			normalized = append(normalized, value)
+		}
+	}
+	return normalized, nil
+}
+
+func (s *SpecService) GetProductSpecs(ctx context.Context, productID int) (map[string]string, error) {
+	if _, err := s.productRepo.FindProductByID(ctx, productID); err != nil {
+		return nil, err
+	}
+	return s.specRepo.FetchProductSpecs(ctx, productID)
+}
+
+// ReplaceProductSpecs sets the complete specification list of a product
+func (s *SpecService) ReplaceProductSpecs(ctx context.Context, productID int, dto *dtos.ReplaceProductSpecsDTO) (map[string]string, error) {
+	changes := make(map[string]*string, len(dto.Specs))
+	for name, value := range dto.Specs {
+		changes[name] = &value
+	}
+	return s.applySpecChanges(ctx, productID, changes, true)
+}
+
+// UpdateProductSpecs adds, changes or (with a null value) removes individual specifications
+func (s *SpecService) UpdateProductSpecs(ctx context.Context, productID int, dto *dtos.UpdateProductSpecsDTO) (map[string]string, error) {
+	return s.applySpecChanges(ctx, productID, dto.Specs, false)
+}
+
+func (s *SpecService) RemoveProductSpec(ctx context.Context, productID int, specName string) (map[string]string, error) {
+	return s.applySpecChanges(ctx, productID, map[string]*string{specName: nil}, false)
+}
+
+// applySpecChanges merges the changes into the product's current specs (or replaces them),
+// checks the result against the category's spec definitions and stores the difference
+func (s *SpecService) applySpecChanges(ctx context.Context, productID int, changes map[string]*string, replace bool) (map[string]string, error) {
+	product, err := s.productRepo.FindProductByID(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.specRepo.FetchProductSpecs(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]string)
+	if !replace {
+		for name, value := range current {
+			result[name] = value
+		}
+	}
+	for rawName, value := range changes {
+		name, err := normalizeSpecName(rawName)
+		if err != nil {
+			return nil, err
+		}
+		if value == nil {
+			if _, ok := result[name]; !ok {
+				return nil, errs.NotFound(fmt.Sprintf("product %d has no spec %s", productID, name), nil)
+			}
+			delete(result, name)
+			continue
+		}
+		if result[name], err = normalizeSpecValue(name, *value); err != nil {
+			return nil, err
+		}
+	}
+
+	values, err := s.validateAgainstDefinitions(ctx, product.CategoryID, result)
+	if err != nil {
+		return nil, err
+	}
+
+	upserts := make(map[string]models.SpecValue)
+	for name, value := range values {
+		result[name] = value.Value
+		if old, ok := current[name]; !ok || old != value.Value {
+			upserts[name] = value
+		}
+	}
+	removals := []string{}
+	for name := range current {
+		if _, ok := result[name]; !ok {
+			removals = append(removals, name)
+		}
+	}
+	if err := s.specRepo.SaveProductSpecs(ctx, productID, upserts, removals); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// validateAgainstDefinitions checks specs against the definitions of the category, if it
+// has any, and returns them in canonical form. The offending spec names are reported in
+// the error details, with the reason for each invalid value.
+func (s *SpecService) validateAgainstDefinitions(ctx context.Context, categoryID int, specs map[string]string) (map[string]models.SpecValue, error) {
+	definitions, err := s.specRepo.FetchCategoryDefinitions(ctx, categoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]models.SpecValue, len(specs))
+	if len(definitions) == 0 {
+		for name, value := range specs {
+			values[name] = models.SpecValue{Value: value}
+		}
+		return values, nil
+	}
+
+	byName := make(map[string]*models.SpecDefinition, len(definitions))
+	missing := []string{}
+	for _, def := range definitions {
+		byName[def.SpecName] = def
+		if _, ok := specs[def.SpecName]; def.Required && !ok {
+			missing = append(missing, def.SpecName)
+		}
+	}
+	unknown := []string{}
+	invalid := map[string]string{}
+	for name, value := range specs {
+		def, ok := byName[name]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if values[name], err = coerceSpecValue(def, value); err != nil {
+			invalid[name] = err.Error()
+		}
+	}
+	if len(missing) == 0 && len(unknown) == 0 && len(invalid) == 0 {
+		return values, nil
+	}
+
+	sort.Strings(unknown)
+	appErr := errs.UnprocessableEntity("SPECIFICATIONS_DO_NOT_MATCH_DEFINITIONS", nil)
+	appErr.Meta = map[string]any{
+		"missing_required": missing,
+		"unknown":          unknown,
+		"invalid":          invalid,
+	}
+	return nil, appErr
+}
+
+// numberWithUnitPattern splits values such as "650W", "3.5 GHz" or "16" into number and unit
+var numberWithUnitPattern = regexp.MustCompile(`^(-?[0-9]+(?:\.[0-9]+)?)\s*(\S*)$`)
+
+var booleanSpecValues = map[string]bool{
+	"true": true, "yes": true, "1": true,
+	"false": false, "no": false, "0": false,
+}
+
+// coerceSpecValue checks a value against its definition and returns its canonical form:
+// enum values take the spelling of the definition, numbers drop their unit and booleans
+// become true or false
+func coerceSpecValue(def *models.SpecDefinition, value string) (models.SpecValue, error) {
+	switch def.DataType {
+	case models.SpecTypeEnum:
+		for _, allowed := range def.AllowedValues {
+			if strings.EqualFold(allowed, value) {
+				return models.SpecValue{Value: allowed}, nil
+			}
+		}
+		return models.SpecValue{}, fmt.Errorf("must be one of %s", strings.Join(def.AllowedValues, ", "))
+
+	case models.SpecTypeBoolean:
+		b, ok := booleanSpecValues[strings.ToLower(value)]
+		if !ok {
+			return models.SpecValue{}, errors.New("must be true or false")
+		}
+		return models.SpecValue{Value: strconv.FormatBool(b)}, nil
+
+	case models.SpecTypeInteger, models.SpecTypeDecimal:
+		match := numberWithUnitPattern.FindStringSubmatch(value)
+		if match == nil || (def.DataType == models.SpecTypeInteger && strings.Contains(match[1], ".")) {
+			return models.SpecValue{}, fmt.Errorf("must be %s", numericSpecFormat(def))
+		}
+		if unit := match[2]; unit != "" && (def.Unit == nil || !strings.EqualFold(unit, *def.Unit)) {
+			return models.SpecValue{}, fmt.Errorf("must be %s", numericSpecFormat(def))
+		}
+		number, err := strconv.ParseFloat(match[1], 64)
+		if err != nil {
+			return models.SpecValue{}, errors.New("is out of range")
+		}
+		return models.SpecValue{Value: strconv.FormatFloat(number, 'f', -1, 64), Numeric: &number}, nil
+	}
+	return models.SpecValue{Value: value}, nil
+}
+
+// numericSpecFormat describes the values a numeric spec accepts, e.g. "an integer in W"
+func numericSpecFormat(def *models.SpecDefinition) string {
+	format := "a number"
+	if def.DataType == models.SpecTypeInteger {
+		format = "an integer"
+	}
+	if def.Unit != nil {
+		format += " in " + *def.Unit
+	}
+	return format
+}
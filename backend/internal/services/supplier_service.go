@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+
+	"github.com/amha-mersha/sanqa-suq/internal/dtos"
+	"github.com/amha-mersha/sanqa-suq/internal/models"
+	"github.com/amha-mersha/sanqa-suq/internal/repositories"
+)
+
+type SupplierService struct {
+	supplierRepo *repositories.SupplierRepository
+}
+
+func NewSupplierService(supplierRepo *repositories.SupplierRepository) *SupplierService {
+	return &SupplierService{
+		supplierRepo: supplierRepo,
+	}
+}
+
+func (s *SupplierService) GetSuppliers(ctx context.Context) ([]*models.Supplier, error) {
+	return s.supplierRepo.FetchAllSuppliers(ctx)
+}
+
+func (s *SupplierService) GetSupplier(ctx context.Context, supplierID int) (*models.Supplier, error) {
+	return s.supplierRepo.FindSupplierByID(ctx, supplierID)
+}
+
+func (s *SupplierService) CreateSupplier(ctx context.Context, dto *dtos.CreateSupplierDTO) (*models.Supplier, error) {
+	return s.supplierRepo.InsertSupplier(ctx, dto)
+}
+
+func (s *SupplierService) UpdateSupplier(ctx context.Context, supplierID int, dto *dtos.UpdateSupplierDTO) (*models.Supplier, error) {
+	return s.supplierRepo.UpdateSupplier(ctx, supplierID, dto)
+}
+
+func (s *SupplierService) DeleteSupplier(ctx context.Context, supplierID int) error {
+	return s.supplierRepo.DeleteSupplier(ctx, supplierID)
+}
@@ -0,0 +1,56 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS place_order(UUID, INTEGER, payment_method, JSONB);
+
+-- Stored Procedure: Place Order
+CREATE OR REPLACE PROCEDURE place_order(
+    p_user_id UUID,
+    p_address_id INTEGER,
+    p_payment_method payment_method,
+    p_items JSONB
+)
+LANGUAGE plpgsql AS $$
+DECLARE
+    v_order_id UUID;
+    v_item JSONB;
+BEGIN
+    -- Insert order
+    INSERT INTO orders (user_id, address_id, payment_method, total_amount)
+    VALUES (
+        p_user_id,
+        p_address_id,
+        p_payment_method,
+        (SELECT SUM((item->>'quantity')::INTEGER * p.price)
+         FROM JSONB_ARRAY_ELEMENTS(p_items) item
+         JOIN products p ON (item->>'product_id')::INTEGER = p.product_id)
+    )
+    RETURNING order_id INTO v_order_id;
+
+    -- Insert order items
+    FOR v_item IN SELECT * FROM JSONB_ARRAY_ELEMENTS(p_items)
+    LOOP
+        INSERT INTO order_items (order_id, product_id, quantity, unit_price)
+        SELECT
+            v_order_id,
+            (v_item->>'product_id')::INTEGER,
+            (v_item->>'quantity')::INTEGER,
+            p.price
+        FROM products p
+        WHERE p.product_id = (v_item->>'product_id')::INTEGER;
+
+        -- Update inventory
+        UPDATE inventory
+        SET quantity = quantity - (v_item->>'quantity')::INTEGER
+        WHERE product_id = (v_item->>'product_id')::INTEGER
+        AND quantity >= (v_item->>'quantity')::INTEGER;
+    END LOOP;
+
+    COMMIT;
+EXCEPTION
+    WHEN OTHERS THEN
+        ROLLBACK;
+        RAISE EXCEPTION 'Order placement failed: %', SQLERRM;
+END;
+$$;
+
+COMMIT;
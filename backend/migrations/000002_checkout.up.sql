@@ -0,0 +1,114 @@
+-- Checkout: turn place_order into a function that can run inside the caller's transaction
+-- Database: PostgreSQL
+
+BEGIN;
+
+-- The original procedure issued COMMIT/ROLLBACK itself, which is not allowed inside an
+-- exception block and prevents callers from clearing the cart in the same transaction.
+DROP PROCEDURE IF EXISTS place_order(UUID, INTEGER, payment_method, JSONB);
+
+-- Function: Place Order
+-- Prices every item from products.price, reserves stock (consuming inventory lots oldest
+-- first when the product is tracked in inventory) and writes the order with its items.
+-- Raises SQ403 for a foreign address, SQ404 for an unknown product and SQ409 when stock
+-- is insufficient so the API can map them to HTTP errors.
+CREATE OR REPLACE FUNCTION place_order(
+    p_user_id UUID,
+    p_address_id INTEGER,
+    p_payment_method payment_method,
+    p_items JSONB
+)
+RETURNS UUID AS $$
+DECLARE
+    v_order_id UUID;
+    v_item RECORD;
+    v_product RECORD;
+    v_lot RECORD;
+    v_remaining INTEGER;
+    v_taken INTEGER;
+    v_total DECIMAL(10,2) := 0;
+BEGIN
+    IF p_items IS NULL OR JSONB_ARRAY_LENGTH(p_items) = 0 THEN
+        RAISE EXCEPTION 'Order must contain at least one item' USING ERRCODE = '22023';
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM addresses WHERE address_id = p_address_id AND user_id = p_user_id
+    ) THEN
+        RAISE EXCEPTION 'Address % does not belong to user %', p_address_id, p_user_id
+            USING ERRCODE = 'SQ403';
+    END IF;
+
+    INSERT INTO orders (user_id, address_id, payment_method, total_amount)
+    VALUES (p_user_id, p_address_id, p_payment_method, 0)
+    RETURNING order_id INTO v_order_id;
+
+    -- Collapse duplicate lines and lock products in a stable order to avoid deadlocks
+    FOR v_item IN
+        SELECT (item->>'product_id')::INTEGER AS product_id,
+               SUM((item->>'quantity')::INTEGER)::INTEGER AS quantity
+        FROM JSONB_ARRAY_ELEMENTS(p_items) item
+        GROUP BY 1
+        ORDER BY 1
+    LOOP
+        IF v_item.quantity <= 0 THEN
+            RAISE EXCEPTION 'Quantity for product % must be positive', v_item.product_id
+                USING ERRCODE = '22023';
+        END IF;
+
+        SELECT p.product_id, p.name, p.price, p.stock_quantity INTO v_product
+        FROM products p
+        WHERE p.product_id = v_item.product_id
+        FOR UPDATE;
+
+        IF NOT FOUND THEN
+            RAISE EXCEPTION 'Product % not found', v_item.product_id USING ERRCODE = 'SQ404';
+        END IF;
+
+        IF v_product.stock_quantity < v_item.quantity THEN
+            RAISE EXCEPTION 'Insufficient stock for product % (requested %, available %)',
+                v_item.product_id, v_item.quantity, v_product.stock_quantity
+                USING ERRCODE = 'SQ409';
+        END IF;
+
+        INSERT INTO order_items (order_id, product_id, quantity, unit_price)
+        VALUES (v_order_id, v_item.product_id, v_item.quantity, v_product.price);
+
+        v_total := v_total + v_product.price * v_item.quantity;
+
+        -- Reserve stock
+        IF EXISTS (SELECT 1 FROM inventory WHERE product_id = v_item.product_id) THEN
+            v_remaining := v_item.quantity;
+            FOR v_lot IN
+                SELECT inventory_id, quantity
+                FROM inventory
+                WHERE product_id = v_item.product_id AND quantity > 0
+                ORDER BY last_updated, inventory_id
+                FOR UPDATE
+            LOOP
+                EXIT WHEN v_remaining = 0;
+                v_taken := LEAST(v_lot.quantity, v_remaining);
+                UPDATE inventory
+                SET quantity = quantity - v_taken, last_updated = CURRENT_TIMESTAMP
+                WHERE inventory_id = v_lot.inventory_id;
+                v_remaining := v_remaining - v_taken;
+            END LOOP;
+
+            IF v_remaining > 0 THEN
+                RAISE EXCEPTION 'Insufficient inventory for product %', v_item.product_id
+                    USING ERRCODE = 'SQ409';
+            END IF;
+        ELSE
+            UPDATE products
+            SET stock_quantity = stock_quantity - v_item.quantity
+            WHERE product_id = v_item.product_id;
+        END IF;
+    END LOOP;
+
+    UPDATE orders SET total_amount = v_total WHERE order_id = v_order_id;
+
+    RETURN v_order_id;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,23 @@
+-- Order status history
+-- Database: PostgreSQL
+
+BEGIN;
+
+CREATE TABLE order_status_history (
+    history_id SERIAL PRIMARY KEY,
+    order_id UUID NOT NULL REFERENCES orders(order_id) ON DELETE CASCADE,
+    from_status order_status,
+    to_status order_status NOT NULL,
+    changed_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    reason TEXT,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_order_status_history_order_id ON order_status_history(order_id);
+
+-- Backfill the current status of existing orders so every order has a history
+INSERT INTO order_status_history (order_id, from_status, to_status, changed_by, changed_at)
+SELECT order_id, NULL, status, user_id, order_date
+FROM orders;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS payment_intents;
+DROP TYPE IF EXISTS payment_intent_status;
+
+COMMIT;
@@ -0,0 +1,30 @@
+-- Payment intents
+-- Database: PostgreSQL
+
+BEGIN;
+
+CREATE TYPE payment_intent_status AS ENUM ('pending', 'succeeded', 'failed', 'refunded');
+
+-- One row per attempt to collect payment for an order through a provider
+CREATE TABLE payment_intents (
+    intent_id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(order_id) ON DELETE CASCADE,
+    provider payment_method NOT NULL,
+    provider_reference VARCHAR(255),
+    amount DECIMAL(10,2) NOT NULL,
+    status payment_intent_status NOT NULL DEFAULT 'pending',
+    checkout_url TEXT,
+    refund_reference VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT positive_intent_amount CHECK (amount >= 0),
+    CONSTRAINT unique_provider_reference UNIQUE (provider, provider_reference)
+);
+
+-- An order can only have one live (pending or succeeded) intent at a time
+CREATE UNIQUE INDEX idx_payment_intents_live_order
+    ON payment_intents(order_id)
+    WHERE status IN ('pending', 'succeeded');
+CREATE INDEX idx_payment_intents_order_id ON payment_intents(order_id);
+
+COMMIT;
@@ -0,0 +1,31 @@
+BEGIN;
+
+DROP VIEW IF EXISTS product_stock_ledger;
+DROP TABLE IF EXISTS stock_movements;
+
+-- Lots without a supplier cannot survive the NOT NULL constraint; drop them without
+-- touching products.stock_quantity
+ALTER TABLE inventory DISABLE TRIGGER inventory_update_trigger;
+DELETE FROM inventory WHERE supplier_id IS NULL;
+ALTER TABLE inventory ENABLE TRIGGER inventory_update_trigger;
+ALTER TABLE inventory ALTER COLUMN supplier_id SET NOT NULL;
+
+ALTER TABLE suppliers DROP COLUMN IF EXISTS created_at;
+
+CREATE OR REPLACE FUNCTION update_product_stock()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products
+    SET stock_quantity = (
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM inventory
+        WHERE product_id = NEW.product_id
+    )
+    WHERE product_id = NEW.product_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TYPE IF EXISTS stock_movement_reason;
+
+COMMIT;
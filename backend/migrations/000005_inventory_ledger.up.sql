@@ -0,0 +1,106 @@
+-- Inventory ledger and supplier management
+-- Database: PostgreSQL
+
+BEGIN;
+
+CREATE TYPE stock_movement_reason AS ENUM (
+    'opening_balance',
+    'receipt',
+    'damaged',
+    'lost',
+    'found',
+    'correction',
+    'customer_return',
+    'supplier_return',
+    'sale',
+    'order_cancelled'
+);
+
+-- Stock that was entered before suppliers were tracked (or found during a count) has no supplier
+ALTER TABLE inventory ALTER COLUMN supplier_id DROP NOT NULL;
+
+ALTER TABLE suppliers ADD COLUMN created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+-- Every change to a product's stock, newest last. quantity_change is signed.
+CREATE TABLE stock_movements (
+    movement_id SERIAL PRIMARY KEY,
+    product_id INTEGER NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    inventory_id INTEGER REFERENCES inventory(inventory_id) ON DELETE SET NULL,
+    supplier_id INTEGER REFERENCES suppliers(supplier_id) ON DELETE SET NULL,
+    order_id UUID REFERENCES orders(order_id) ON DELETE SET NULL,
+    quantity_change INTEGER NOT NULL,
+    reason stock_movement_reason NOT NULL,
+    note TEXT,
+    performed_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT non_zero_quantity_change CHECK (quantity_change <> 0)
+);
+
+CREATE INDEX idx_stock_movements_product_id ON stock_movements(product_id, created_at);
+
+-- The original trigger read NEW on DELETE, where it is NULL, and never resynced the product
+CREATE OR REPLACE FUNCTION update_product_stock()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_product_id INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        v_product_id := OLD.product_id;
+    ELSE
+        v_product_id := NEW.product_id;
+    END IF;
+
+    UPDATE products
+    SET stock_quantity = (
+        SELECT COALESCE(SUM(quantity), 0)
+        FROM inventory
+        WHERE product_id = v_product_id
+    )
+    WHERE product_id = v_product_id;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Move stock that was only recorded on products.stock_quantity into an inventory lot, so the
+-- trigger does not wipe it the first time the product receives stock
+INSERT INTO inventory (product_id, supplier_id, quantity)
+SELECT p.product_id, NULL, p.stock_quantity - COALESCE(lots.quantity, 0)
+FROM products p
+LEFT JOIN (
+    SELECT product_id, SUM(quantity) AS quantity
+    FROM inventory
+    GROUP BY product_id
+) lots ON lots.product_id = p.product_id
+WHERE p.stock_quantity > COALESCE(lots.quantity, 0);
+
+INSERT INTO stock_movements (product_id, quantity_change, reason, note)
+SELECT product_id, stock_quantity, 'opening_balance', 'stock on hand when the ledger was introduced'
+FROM products
+WHERE stock_quantity > 0;
+
+-- Per-product ledger with the running balance after every movement
+CREATE VIEW product_stock_ledger AS
+SELECT
+    m.movement_id,
+    m.product_id,
+    m.inventory_id,
+    m.supplier_id,
+    s.name AS supplier_name,
+    m.order_id,
+    m.quantity_change,
+    SUM(m.quantity_change) OVER (
+        PARTITION BY m.product_id
+        ORDER BY m.created_at, m.movement_id
+    ) AS balance_after,
+    m.reason,
+    m.note,
+    m.performed_by,
+    m.created_at
+FROM stock_movements m
+LEFT JOIN suppliers s ON m.supplier_id = s.supplier_id;
+
+COMMIT;
@@ -0,0 +1,181 @@
+-- Discounts: effective prices from product and category discounts
+-- Database: PostgreSQL
+
+BEGIN;
+
+CREATE INDEX idx_discounts_category_id ON discounts(category_id);
+
+-- Every category paired with itself and each of its ancestors; depth 0 is the category itself
+CREATE VIEW category_ancestors AS
+WITH RECURSIVE ancestors AS (
+    SELECT category_id, category_id AS ancestor_id, 0 AS depth
+    FROM categories
+    UNION ALL
+    SELECT a.category_id, c.parent_category_id, a.depth + 1
+    FROM ancestors a
+    JOIN categories c ON c.category_id = a.ancestor_id
+    WHERE c.parent_category_id IS NOT NULL
+)
+SELECT category_id, ancestor_id, depth FROM ancestors;
+
+-- The price a product sells for right now. A product gets the largest active discount among
+-- the ones on the product itself and the ones on its category or any ancestor category;
+-- on a tie the most specific discount wins.
+CREATE VIEW product_effective_prices AS
+WITH applicable AS (
+    SELECT p.product_id, d.discount_id, d.discount_percentage, -1 AS depth
+    FROM products p
+    JOIN discounts d ON d.product_id = p.product_id
+    WHERE CURRENT_TIMESTAMP BETWEEN d.start_date AND d.end_date
+    UNION ALL
+    SELECT p.product_id, d.discount_id, d.discount_percentage, ca.depth
+    FROM products p
+    JOIN category_ancestors ca ON ca.category_id = p.category_id
+    JOIN discounts d ON d.category_id = ca.ancestor_id AND d.product_id IS NULL
+    WHERE CURRENT_TIMESTAMP BETWEEN d.start_date AND d.end_date
+),
+best AS (
+    SELECT DISTINCT ON (product_id) product_id, discount_id, discount_percentage
+    FROM applicable
+    ORDER BY product_id, discount_percentage DESC, depth, discount_id
+)
+SELECT
+    p.product_id,
+    p.price AS original_price,
+    b.discount_id,
+    COALESCE(b.discount_percentage, 0)::DECIMAL(5,2) AS discount_percentage,
+    ROUND(p.price * COALESCE(b.discount_percentage, 0) / 100, 2)::DECIMAL(10,2) AS discount_amount,
+    (p.price - ROUND(p.price * COALESCE(b.discount_percentage, 0) / 100, 2))::DECIMAL(10,2) AS final_price
+FROM products p
+LEFT JOIN best b ON b.product_id = p.product_id;
+
+-- Cart lines are priced at the effective price
+CREATE OR REPLACE VIEW user_cart_summary AS
+SELECT c.user_id, p.product_id, p.name, c.quantity, ep.final_price AS price, (c.quantity * ep.final_price) AS total
+FROM cart c
+JOIN products p ON c.product_id = p.product_id
+JOIN product_effective_prices ep ON ep.product_id = p.product_id;
+
+-- Build totals use the effective price as well
+CREATE OR REPLACE FUNCTION update_build_total_price()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE custom_builds
+    SET total_price = (
+        SELECT COALESCE(SUM(ep.final_price * bi.quantity), 0)
+        FROM build_items bi
+        JOIN product_effective_prices ep ON bi.product_id = ep.product_id
+        WHERE bi.build_id = NEW.build_id
+    )
+    WHERE build_id = NEW.build_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: Place Order
+-- Same as before, but every item is priced at its effective (discounted) price.
+CREATE OR REPLACE FUNCTION place_order(
+    p_user_id UUID,
+    p_address_id INTEGER,
+    p_payment_method payment_method,
+    p_items JSONB
+)
+RETURNS UUID AS $$
+DECLARE
+    v_order_id UUID;
+    v_item RECORD;
+    v_product RECORD;
+    v_lot RECORD;
+    v_remaining INTEGER;
+    v_taken INTEGER;
+    v_price DECIMAL(10,2);
+    v_total DECIMAL(10,2) := 0;
+BEGIN
+    IF p_items IS NULL OR JSONB_ARRAY_LENGTH(p_items) = 0 THEN
+        RAISE EXCEPTION 'Order must contain at least one item' USING ERRCODE = '22023';
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM addresses WHERE address_id = p_address_id AND user_id = p_user_id
+    ) THEN
+        RAISE EXCEPTION 'Address % does not belong to user %', p_address_id, p_user_id
+            USING ERRCODE = 'SQ403';
+    END IF;
+
+    INSERT INTO orders (user_id, address_id, payment_method, total_amount)
+    VALUES (p_user_id, p_address_id, p_payment_method, 0)
+    RETURNING order_id INTO v_order_id;
+
+    -- Collapse duplicate lines and lock products in a stable order to avoid deadlocks
+    FOR v_item IN
+        SELECT (item->>'product_id')::INTEGER AS product_id,
+               SUM((item->>'quantity')::INTEGER)::INTEGER AS quantity
+        FROM JSONB_ARRAY_ELEMENTS(p_items) item
+        GROUP BY 1
+        ORDER BY 1
+    LOOP
+        IF v_item.quantity <= 0 THEN
+            RAISE EXCEPTION 'Quantity for product % must be positive', v_item.product_id
+                USING ERRCODE = '22023';
+        END IF;
+
+        SELECT p.product_id, p.name, p.stock_quantity INTO v_product
+        FROM products p
+        WHERE p.product_id = v_item.product_id
+        FOR UPDATE;
+
+        IF NOT FOUND THEN
+            RAISE EXCEPTION 'Product % not found', v_item.product_id USING ERRCODE = 'SQ404';
+        END IF;
+
+        IF v_product.stock_quantity < v_item.quantity THEN
+            RAISE EXCEPTION 'Insufficient stock for product % (requested %, available %)',
+                v_item.product_id, v_item.quantity, v_product.stock_quantity
+                USING ERRCODE = 'SQ409';
+        END IF;
+
+        SELECT final_price INTO v_price
+        FROM product_effective_prices
+        WHERE product_id = v_item.product_id;
+
+        INSERT INTO order_items (order_id, product_id, quantity, unit_price)
+        VALUES (v_order_id, v_item.product_id, v_item.quantity, v_price);
+
+        v_total := v_total + v_price * v_item.quantity;
+
+        -- Reserve stock
+        IF EXISTS (SELECT 1 FROM inventory WHERE product_id = v_item.product_id) THEN
+            v_remaining := v_item.quantity;
+            FOR v_lot IN
+                SELECT inventory_id, quantity
+                FROM inventory
+                WHERE product_id = v_item.product_id AND quantity > 0
+                ORDER BY last_updated, inventory_id
+                FOR UPDATE
+            LOOP
+                EXIT WHEN v_remaining = 0;
+                v_taken := LEAST(v_lot.quantity, v_remaining);
+                UPDATE inventory
+                SET quantity = quantity - v_taken, last_updated = CURRENT_TIMESTAMP
+                WHERE inventory_id = v_lot.inventory_id;
+                v_remaining := v_remaining - v_taken;
+            END LOOP;
+
+            IF v_remaining > 0 THEN
+                RAISE EXCEPTION 'Insufficient inventory for product %', v_item.product_id
+                    USING ERRCODE = 'SQ409';
+            END IF;
+        ELSE
+            UPDATE products
+            SET stock_quantity = stock_quantity - v_item.quantity
+            WHERE product_id = v_item.product_id;
+        END IF;
+    END LOOP;
+
+    UPDATE orders SET total_amount = v_total WHERE order_id = v_order_id;
+
+    RETURN v_order_id;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
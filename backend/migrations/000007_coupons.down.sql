@@ -0,0 +1,11 @@
+BEGIN;
+
+DROP FUNCTION IF EXISTS apply_coupon(UUID, TEXT);
+DROP FUNCTION IF EXISTS quote_coupon(UUID, TEXT, JSONB, BOOLEAN);
+DROP TABLE IF EXISTS coupon_redemptions;
+DROP TABLE IF EXISTS coupon_brands;
+DROP TABLE IF EXISTS coupon_categories;
+DROP TABLE IF EXISTS coupons;
+DROP TYPE IF EXISTS coupon_type;
+
+COMMIT;
@@ -0,0 +1,184 @@
+-- Coupon codes
+-- Database: PostgreSQL
+
+BEGIN;
+
+CREATE TYPE coupon_type AS ENUM ('percentage', 'fixed');
+
+CREATE TABLE coupons (
+    coupon_id SERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL UNIQUE,
+    description TEXT,
+    coupon_type coupon_type NOT NULL,
+    value DECIMAL(10,2) NOT NULL,
+    min_order_amount DECIMAL(10,2) NOT NULL DEFAULT 0,
+    max_discount_amount DECIMAL(10,2),
+    usage_limit INTEGER,
+    per_user_limit INTEGER,
+    starts_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP,
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT coupon_code_upper CHECK (code = UPPER(code)),
+    CONSTRAINT positive_coupon_value CHECK (value > 0),
+    CONSTRAINT valid_coupon_percentage CHECK (coupon_type <> 'percentage' OR value <= 100),
+    CONSTRAINT positive_min_order_amount CHECK (min_order_amount >= 0),
+    CONSTRAINT positive_max_discount_amount CHECK (max_discount_amount IS NULL OR max_discount_amount > 0),
+    CONSTRAINT positive_usage_limit CHECK (usage_limit IS NULL OR usage_limit > 0),
+    CONSTRAINT positive_per_user_limit CHECK (per_user_limit IS NULL OR per_user_limit > 0),
+    CONSTRAINT valid_coupon_dates CHECK (expires_at IS NULL OR starts_at <= expires_at)
+);
+
+-- A coupon restricted to categories applies to products in those categories and their
+-- subcategories; one restricted to brands applies to products of those brands. When both
+-- are set a product has to match both.
+CREATE TABLE coupon_categories (
+    coupon_id INTEGER NOT NULL REFERENCES coupons(coupon_id) ON DELETE CASCADE,
+    category_id INTEGER NOT NULL REFERENCES categories(category_id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, category_id)
+);
+
+CREATE TABLE coupon_brands (
+    coupon_id INTEGER NOT NULL REFERENCES coupons(coupon_id) ON DELETE CASCADE,
+    brand_id INTEGER NOT NULL REFERENCES brands(brand_id) ON DELETE CASCADE,
+    PRIMARY KEY (coupon_id, brand_id)
+);
+
+CREATE TABLE coupon_redemptions (
+    redemption_id SERIAL PRIMARY KEY,
+    coupon_id INTEGER NOT NULL REFERENCES coupons(coupon_id) ON DELETE RESTRICT,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    order_id UUID NOT NULL UNIQUE REFERENCES orders(order_id) ON DELETE CASCADE,
+    discount_amount DECIMAL(10,2) NOT NULL,
+    redeemed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_coupon_redemptions_coupon_user ON coupon_redemptions(coupon_id, user_id);
+
+-- Function: Quote Coupon
+-- Works out what a coupon is worth for a set of items ([{product_id, quantity}]) priced at
+-- their effective price. Redemptions of cancelled orders do not count towards the limits.
+-- Raises SC404 for an unknown code, SC409 when a usage limit is reached and SC422 when the
+-- coupon cannot be used for these items; the message is the API error code.
+-- With p_lock the coupon row stays locked until the caller's transaction ends.
+CREATE OR REPLACE FUNCTION quote_coupon(
+    p_user_id UUID,
+    p_code TEXT,
+    p_items JSONB,
+    p_lock BOOLEAN DEFAULT FALSE
+)
+RETURNS TABLE (coupon_id INTEGER, eligible_subtotal DECIMAL(10,2), discount_amount DECIMAL(10,2)) AS $$
+DECLARE
+    v_coupon coupons%ROWTYPE;
+    v_used INTEGER;
+    v_used_by_user INTEGER;
+    v_eligible DECIMAL(10,2);
+    v_discount DECIMAL(10,2);
+BEGIN
+    IF p_lock THEN
+        SELECT * INTO v_coupon FROM coupons c WHERE c.code = UPPER(p_code) FOR UPDATE;
+    ELSE
+        SELECT * INTO v_coupon FROM coupons c WHERE c.code = UPPER(p_code);
+    END IF;
+
+    IF NOT FOUND THEN
+        RAISE EXCEPTION 'COUPON_NOT_FOUND' USING ERRCODE = 'SC404';
+    END IF;
+    IF NOT v_coupon.is_active OR v_coupon.starts_at > CURRENT_TIMESTAMP THEN
+        RAISE EXCEPTION 'COUPON_NOT_ACTIVE' USING ERRCODE = 'SC422';
+    END IF;
+    IF v_coupon.expires_at IS NOT NULL AND v_coupon.expires_at < CURRENT_TIMESTAMP THEN
+        RAISE EXCEPTION 'COUPON_EXPIRED' USING ERRCODE = 'SC422';
+    END IF;
+
+    SELECT COUNT(*), COUNT(*) FILTER (WHERE r.user_id = p_user_id)
+    INTO v_used, v_used_by_user
+    FROM coupon_redemptions r
+    JOIN orders o ON o.order_id = r.order_id
+    WHERE r.coupon_id = v_coupon.coupon_id AND o.status <> 'cancelled';
+
+    IF v_coupon.usage_limit IS NOT NULL AND v_used >= v_coupon.usage_limit THEN
+        RAISE EXCEPTION 'COUPON_USAGE_LIMIT_REACHED' USING ERRCODE = 'SC409';
+    END IF;
+    IF v_coupon.per_user_limit IS NOT NULL AND v_used_by_user >= v_coupon.per_user_limit THEN
+        RAISE EXCEPTION 'COUPON_ALREADY_USED' USING ERRCODE = 'SC409';
+    END IF;
+
+    SELECT COALESCE(SUM(ep.final_price * i.quantity), 0)
+    INTO v_eligible
+    FROM (
+        SELECT (item->>'product_id')::INTEGER AS product_id,
+               SUM((item->>'quantity')::INTEGER) AS quantity
+        FROM JSONB_ARRAY_ELEMENTS(p_items) item
+        GROUP BY 1
+    ) i
+    JOIN products p ON p.product_id = i.product_id
+    JOIN product_effective_prices ep ON ep.product_id = p.product_id
+    WHERE (
+        NOT EXISTS (SELECT 1 FROM coupon_categories cc WHERE cc.coupon_id = v_coupon.coupon_id)
+        OR EXISTS (
+            SELECT 1
+            FROM coupon_categories cc
+            JOIN category_ancestors ca ON ca.ancestor_id = cc.category_id
+            WHERE cc.coupon_id = v_coupon.coupon_id AND ca.category_id = p.category_id
+        )
+    )
+    AND (
+        NOT EXISTS (SELECT 1 FROM coupon_brands cb WHERE cb.coupon_id = v_coupon.coupon_id)
+        OR EXISTS (
+            SELECT 1 FROM coupon_brands cb
+            WHERE cb.coupon_id = v_coupon.coupon_id AND cb.brand_id = p.brand_id
+        )
+    );
+
+    IF v_eligible = 0 THEN
+        RAISE EXCEPTION 'COUPON_NOT_APPLICABLE' USING ERRCODE = 'SC422';
+    END IF;
+    IF v_eligible < v_coupon.min_order_amount THEN
+        RAISE EXCEPTION 'COUPON_MINIMUM_NOT_MET' USING ERRCODE = 'SC422';
+    END IF;
+
+    IF v_coupon.coupon_type = 'percentage' THEN
+        v_discount := ROUND(v_eligible * v_coupon.value / 100, 2);
+        IF v_coupon.max_discount_amount IS NOT NULL THEN
+            v_discount := LEAST(v_discount, v_coupon.max_discount_amount);
+        END IF;
+    ELSE
+        v_discount := LEAST(v_coupon.value, v_eligible);
+    END IF;
+
+    RETURN QUERY SELECT v_coupon.coupon_id, v_eligible, v_discount;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Function: Apply Coupon
+-- Redeems a coupon for an order placed in the same transaction and takes the discount off
+-- the order total. Returns the discount.
+CREATE OR REPLACE FUNCTION apply_coupon(p_order_id UUID, p_code TEXT)
+RETURNS DECIMAL(10,2) AS $$
+DECLARE
+    v_user_id UUID;
+    v_items JSONB;
+    v_quote RECORD;
+BEGIN
+    SELECT user_id INTO v_user_id FROM orders WHERE order_id = p_order_id;
+
+    SELECT JSONB_AGG(JSONB_BUILD_OBJECT('product_id', product_id, 'quantity', quantity))
+    INTO v_items
+    FROM order_items
+    WHERE order_id = p_order_id;
+
+    SELECT * INTO v_quote FROM quote_coupon(v_user_id, p_code, v_items, TRUE);
+
+    INSERT INTO coupon_redemptions (coupon_id, user_id, order_id, discount_amount)
+    VALUES (v_quote.coupon_id, v_user_id, p_order_id, v_quote.discount_amount);
+
+    UPDATE orders
+    SET total_amount = total_amount - v_quote.discount_amount
+    WHERE order_id = p_order_id;
+
+    RETURN v_quote.discount_amount;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
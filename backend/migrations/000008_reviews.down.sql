@@ -0,0 +1,14 @@
+BEGIN;
+
+DROP VIEW IF EXISTS top_rated_products;
+CREATE VIEW top_rated_products AS
+SELECT p.product_id, p.name, AVG(r.rating) AS avg_rating
+FROM products p
+JOIN reviews r ON p.product_id = r.product_id
+GROUP BY p.product_id, p.name
+HAVING COUNT(r.review_id) > 0;
+
+DROP INDEX IF EXISTS idx_reviews_product_date;
+ALTER TABLE reviews ALTER COLUMN review_id DROP DEFAULT;
+
+COMMIT;
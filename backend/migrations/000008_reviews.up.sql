@@ -0,0 +1,18 @@
+-- Product reviews
+-- Database: PostgreSQL
+
+BEGIN;
+
+ALTER TABLE reviews ALTER COLUMN review_id SET DEFAULT uuid_generate_v4();
+
+CREATE INDEX idx_reviews_product_date ON reviews(product_id, review_date DESC);
+
+-- keep the rating average next to the number of reviews it was computed from
+CREATE OR REPLACE VIEW top_rated_products AS
+SELECT p.product_id, p.name, AVG(r.rating) AS avg_rating, COUNT(r.review_id) AS review_count
+FROM products p
+JOIN reviews r ON p.product_id = r.product_id
+GROUP BY p.product_id, p.name
+HAVING COUNT(r.review_id) > 0;
+
+COMMIT;
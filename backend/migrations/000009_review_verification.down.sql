@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_order_items_product_id;
+ALTER TABLE reviews DROP CONSTRAINT IF EXISTS unique_user_product_review;
+
+COMMIT;
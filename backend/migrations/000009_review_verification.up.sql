@@ -0,0 +1,18 @@
+-- One review per user per product
+-- Database: PostgreSQL
+
+BEGIN;
+
+-- keep only the most recent review when a user reviewed the same product more than once
+DELETE FROM reviews r
+USING reviews newer
+WHERE newer.user_id = r.user_id
+  AND newer.product_id = r.product_id
+  AND (newer.review_date, newer.review_id) > (r.review_date, r.review_id);
+
+ALTER TABLE reviews ADD CONSTRAINT unique_user_product_review UNIQUE (user_id, product_id);
+
+-- verified-purchase lookups go from a product to the orders containing it
+CREATE INDEX idx_order_items_product_id ON order_items(product_id);
+
+COMMIT;
@@ -0,0 +1,25 @@
+BEGIN;
+
+CREATE OR REPLACE VIEW top_rated_products AS
+SELECT p.product_id, p.name, AVG(r.rating) AS avg_rating, COUNT(r.review_id) AS review_count
+FROM products p
+JOIN reviews r ON p.product_id = r.product_id
+GROUP BY p.product_id, p.name
+HAVING COUNT(r.review_id) > 0;
+
+DROP TABLE IF EXISTS review_moderation_log;
+DROP TABLE IF EXISTS review_votes;
+DROP TABLE IF EXISTS review_reports;
+
+DROP INDEX IF EXISTS idx_reviews_status;
+ALTER TABLE reviews
+    DROP COLUMN IF EXISTS moderated_at,
+    DROP COLUMN IF EXISTS moderated_by,
+    DROP COLUMN IF EXISTS moderation_reason,
+    DROP COLUMN IF EXISTS status;
+
+DROP TYPE IF EXISTS review_moderation_action;
+DROP TYPE IF EXISTS review_report_reason;
+DROP TYPE IF EXISTS review_status;
+
+COMMIT;
@@ -0,0 +1,61 @@
+-- Review moderation, reports and helpful votes
+-- Database: PostgreSQL
+
+BEGIN;
+
+CREATE TYPE review_status AS ENUM ('published', 'held', 'hidden');
+CREATE TYPE review_report_reason AS ENUM ('spam', 'offensive', 'off_topic', 'fake', 'other');
+CREATE TYPE review_moderation_action AS ENUM ('held', 'approved', 'hidden', 'deleted');
+
+-- held reviews wait for a moderator; hidden ones were rejected. Neither is listed publicly.
+ALTER TABLE reviews
+    ADD COLUMN status review_status NOT NULL DEFAULT 'published',
+    ADD COLUMN moderation_reason TEXT,
+    ADD COLUMN moderated_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    ADD COLUMN moderated_at TIMESTAMP;
+
+CREATE INDEX idx_reviews_status ON reviews(status) WHERE status <> 'published';
+
+CREATE TABLE review_reports (
+    report_id SERIAL PRIMARY KEY,
+    review_id UUID NOT NULL REFERENCES reviews(review_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    reason review_report_reason NOT NULL,
+    note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP,
+    CONSTRAINT unique_review_report UNIQUE (review_id, user_id)
+);
+
+CREATE INDEX idx_review_reports_open ON review_reports(review_id) WHERE resolved_at IS NULL;
+
+CREATE TABLE review_votes (
+    review_id UUID NOT NULL REFERENCES reviews(review_id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (review_id, user_id)
+);
+
+-- audit trail of moderation decisions; kept after a review is deleted
+CREATE TABLE review_moderation_log (
+    log_id SERIAL PRIMARY KEY,
+    review_id UUID NOT NULL,
+    product_id INTEGER REFERENCES products(product_id) ON DELETE SET NULL,
+    action review_moderation_action NOT NULL,
+    reason TEXT,
+    performed_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_review_moderation_log_review_id ON review_moderation_log(review_id);
+
+-- only published reviews count towards a product's rating
+CREATE OR REPLACE VIEW top_rated_products AS
+SELECT p.product_id, p.name, AVG(r.rating) AS avg_rating, COUNT(r.review_id) AS review_count
+FROM products p
+JOIN reviews r ON p.product_id = r.product_id
+WHERE r.status = 'published'
+GROUP BY p.product_id, p.name
+HAVING COUNT(r.review_id) > 0;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS product_specifications_search_vector_update ON product_specifications;
+DROP TRIGGER IF EXISTS brands_search_vector_update ON brands;
+DROP TRIGGER IF EXISTS products_search_vector_update ON products;
+DROP FUNCTION IF EXISTS product_specifications_search_vector_trigger();
+DROP FUNCTION IF EXISTS brands_search_vector_trigger();
+DROP FUNCTION IF EXISTS products_search_vector_trigger();
+DROP FUNCTION IF EXISTS refresh_product_search_vector(INTEGER);
+DROP FUNCTION IF EXISTS product_search_vector(INTEGER, TEXT, TEXT, INTEGER);
+
+DROP INDEX IF EXISTS idx_products_search_vector;
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
+
+COMMIT;
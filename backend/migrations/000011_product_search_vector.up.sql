@@ -0,0 +1,87 @@
+-- Full-text search over products
+-- Database: PostgreSQL
+
+BEGIN;
+
+ALTER TABLE products ADD COLUMN search_vector TSVECTOR;
+
+-- The searchable document of a product, weighted name > brand > spec values > description.
+-- The 'simple' configuration does no stemming, so model numbers and Amharic text are
+-- indexed as written and prefix queries behave predictably.
+CREATE OR REPLACE FUNCTION product_search_vector(
+    p_product_id INTEGER,
+    p_name TEXT,
+    p_description TEXT,
+    p_brand_id INTEGER
+)
+RETURNS TSVECTOR AS $$
+    SELECT setweight(to_tsvector('simple', COALESCE(p_name, '')), 'A')
+        || setweight(to_tsvector('simple', COALESCE((SELECT name FROM brands WHERE brand_id = p_brand_id), '')), 'B')
+        || setweight(to_tsvector('simple', COALESCE((
+               SELECT string_agg(spec_value, ' ')
+               FROM product_specifications
+               WHERE product_id = p_product_id
+           ), '')), 'C')
+        || setweight(to_tsvector('simple', COALESCE(p_description, '')), 'D');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION refresh_product_search_vector(p_product_id INTEGER)
+RETURNS VOID AS $$
+    UPDATE products
+    SET search_vector = product_search_vector(product_id, name, description, brand_id)
+    WHERE product_id = p_product_id;
+$$ LANGUAGE sql;
+
+CREATE OR REPLACE FUNCTION products_search_vector_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    NEW.search_vector := product_search_vector(NEW.product_id, NEW.name, NEW.description, NEW.brand_id);
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_search_vector_update
+BEFORE INSERT OR UPDATE OF name, description, brand_id ON products
+FOR EACH ROW
+EXECUTE FUNCTION products_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION brands_search_vector_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE products
+    SET search_vector = product_search_vector(product_id, name, description, brand_id)
+    WHERE brand_id = NEW.brand_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER brands_search_vector_update
+AFTER UPDATE OF name ON brands
+FOR EACH ROW
+WHEN (OLD.name IS DISTINCT FROM NEW.name)
+EXECUTE FUNCTION brands_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION product_specifications_search_vector_trigger()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP IN ('UPDATE', 'DELETE') THEN
+        PERFORM refresh_product_search_vector(OLD.product_id);
+    END IF;
+    IF TG_OP = 'INSERT' OR (TG_OP = 'UPDATE' AND NEW.product_id <> OLD.product_id) THEN
+        PERFORM refresh_product_search_vector(NEW.product_id);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_specifications_search_vector_update
+AFTER INSERT OR UPDATE OR DELETE ON product_specifications
+FOR EACH ROW
+EXECUTE FUNCTION product_specifications_search_vector_trigger();
+
+UPDATE products
+SET search_vector = product_search_vector(product_id, name, description, brand_id);
+
+CREATE INDEX idx_products_search_vector ON products USING GIN (search_vector);
+
+COMMIT;
@@ -0,0 +1,12 @@
+-- Trigram indexes for search suggestions
+-- Database: PostgreSQL
+
+BEGIN;
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+CREATE INDEX idx_products_name_trgm ON products USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_brands_name_trgm ON brands USING GIN (name gin_trgm_ops);
+CREATE INDEX idx_categories_name_trgm ON categories USING GIN (category_name gin_trgm_ops);
+
+COMMIT;
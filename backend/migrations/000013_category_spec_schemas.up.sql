@@ -0,0 +1,16 @@
+-- Specification schemas per category
+-- Database: PostgreSQL
+
+BEGIN;
+
+-- The specifications products of a category may carry. Once a category has a schema,
+-- spec writes for its products must stick to these names and include the required ones.
+CREATE TABLE category_spec_schemas (
+    category_id INTEGER NOT NULL REFERENCES categories(category_id) ON DELETE CASCADE,
+    spec_name VARCHAR(100) NOT NULL,
+    required BOOLEAN NOT NULL DEFAULT FALSE,
+    description TEXT,
+    PRIMARY KEY (category_id, spec_name)
+);
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+DROP VIEW IF EXISTS category_effective_spec_definitions;
+
+DROP INDEX IF EXISTS idx_product_specifications_numeric;
+ALTER TABLE product_specifications DROP COLUMN IF EXISTS numeric_value;
+
+ALTER TABLE category_spec_definitions
+    DROP CONSTRAINT IF EXISTS unit_only_for_numbers,
+    DROP CONSTRAINT IF EXISTS enum_has_allowed_values,
+    DROP COLUMN IF EXISTS filterable,
+    DROP COLUMN IF EXISTS allowed_values,
+    DROP COLUMN IF EXISTS unit,
+    DROP COLUMN IF EXISTS data_type;
+ALTER TABLE category_spec_definitions RENAME TO category_spec_schemas;
+
+DROP TYPE IF EXISTS spec_data_type;
+
+COMMIT;
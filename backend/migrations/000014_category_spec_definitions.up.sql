@@ -0,0 +1,39 @@
+-- Typed specification definitions inherited down the category tree
+-- Database: PostgreSQL
+
+BEGIN;
+
+CREATE TYPE spec_data_type AS ENUM ('text', 'enum', 'integer', 'decimal', 'boolean');
+
+ALTER TABLE category_spec_schemas RENAME TO category_spec_definitions;
+ALTER TABLE category_spec_definitions
+    ADD COLUMN data_type spec_data_type NOT NULL DEFAULT 'text',
+    ADD COLUMN unit VARCHAR(20),
+    ADD COLUMN allowed_values TEXT[],
+    ADD COLUMN filterable BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD CONSTRAINT enum_has_allowed_values
+        CHECK (data_type <> 'enum' OR COALESCE(array_length(allowed_values, 1), 0) > 0),
+    ADD CONSTRAINT unit_only_for_numbers
+        CHECK (unit IS NULL OR data_type IN ('integer', 'decimal'));
+
+-- Every schema was a search facet before definitions could opt out, so existing ones stay
+-- filterable; definitions created from now on opt in
+UPDATE category_spec_definitions SET filterable = TRUE;
+
+-- Numeric specs keep their canonical text in spec_value and the number in numeric_value
+-- so product search can filter on ranges
+ALTER TABLE product_specifications ADD COLUMN numeric_value NUMERIC;
+CREATE INDEX idx_product_specifications_numeric ON product_specifications(spec_name, numeric_value)
+    WHERE numeric_value IS NOT NULL;
+
+-- The definitions that apply to each category: its own and those of its ancestors.
+-- A definition on a closer category overrides one with the same name further up.
+CREATE VIEW category_effective_spec_definitions AS
+SELECT DISTINCT ON (ca.category_id, d.spec_name)
+       ca.category_id, d.spec_name, d.required, d.description, d.data_type, d.unit,
+       d.allowed_values, d.filterable, d.category_id AS defined_in
+FROM category_ancestors ca
+JOIN category_spec_definitions d ON d.category_id = ca.ancestor_id
+ORDER BY ca.category_id, d.spec_name, ca.depth;
+
+COMMIT;
@@ -0,0 +1,91 @@
+BEGIN;
+
+-- The redesigned rules do not fit the old table and are dropped
+DROP FUNCTION IF EXISTS validate_build(UUID);
+DROP FUNCTION IF EXISTS compatibility_violations(INTEGER[]);
+DROP TABLE IF EXISTS compatibility_rules;
+DROP TYPE IF EXISTS compatibility_quantifier;
+DROP TYPE IF EXISTS compatibility_operator;
+DROP TYPE IF EXISTS compatibility_severity;
+
+CREATE TABLE compatibility_rules (
+    product_id INTEGER NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    spec_id INTEGER NOT NULL,
+    spec_name VARCHAR(100) NOT NULL,
+    spec_value VARCHAR(255) NOT NULL,
+    PRIMARY KEY (product_id, spec_id, spec_value),
+    FOREIGN KEY (spec_id, spec_name) REFERENCES product_specifications(product_id, spec_name) ON DELETE CASCADE
+);
+CREATE INDEX idx_compatibility_rules_product_ids ON compatibility_rules(product_id);
+
+-- Stored Procedure: Validate Build Compatibility
+CREATE OR REPLACE FUNCTION validate_build(p_build_id UUID)
+RETURNS TABLE (is_compatible BOOLEAN, message TEXT) AS $$
+DECLARE
+    v_item RECORD;
+    v_rule RECORD;
+BEGIN
+    is_compatible := TRUE;
+    message := '';
+
+    FOR v_item IN
+        SELECT bi.product_id
+        FROM build_items bi
+        WHERE bi.build_id = p_build_id
+    LOOP
+        FOR v_rule IN
+            SELECT cr.spec_id, ps.spec_name, cr.spec_value
+            FROM compatibility_rules cr
+            JOIN product_specifications ps ON cr.product_id = ps.product_id AND cr.spec_id = ps.spec_name
+            WHERE cr.product_id = v_item.product_id
+            AND cr.spec_value != 'ANY' -- Skip universal compatibility rules 
+        LOOP
+            IF NOT EXISTS (
+                SELECT 1
+                FROM build_items bi2
+                JOIN product_specifications ps2 ON bi2.product_id = ps2.product_id
+                WHERE bi2.build_id = p_build_id
+                AND ps2.spec_name = v_rule.spec_name
+                AND ps2.spec_value = v_rule.spec_value
+            ) THEN
+                is_compatible := FALSE;
+                message := message || format('Incompatible: Product %s requires %s=%s; ', 
+                                            v_item.product_id, v_rule.spec_name, v_rule.spec_value);
+            END IF;
+        END LOOP;
+    END LOOP;
+
+    RETURN NEXT;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Stored Function: Get Compatible Products
+CREATE OR REPLACE FUNCTION get_compatible_products(p_build_id UUID, p_category_name TEXT)
+RETURNS TABLE (product_id INTEGER, product_name TEXT) AS $$
+BEGIN
+    RETURN QUERY
+    SELECT p.product_id, p.name AS product_name
+    FROM products p
+    JOIN categories c ON p.category_id = c.category_id
+    WHERE c.name = p_category_name
+    AND NOT EXISTS (
+        -- Check for any incompatible rules
+        SELECT 1
+        FROM compatibility_rules cr
+        JOIN product_specifications ps ON cr.product_id = ps.product_id AND cr.spec_id = ps.spec_name
+        WHERE cr.product_id = p.product_id
+        AND cr.spec_value != 'ANY' -- Skip universal compatibility rules (e.g., RAM)
+        AND NOT EXISTS (
+            -- Verify selected components meet the rule
+            SELECT 1
+            FROM build_items bi
+            JOIN product_specifications ps2 ON bi.product_id = ps2.product_id
+            WHERE bi.build_id = p_build_id
+            AND ps2.spec_name = ps.spec_name
+            AND ps2.spec_value = cr.spec_value
+        )
+    );
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
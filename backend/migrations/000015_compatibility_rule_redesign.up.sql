@@ -0,0 +1,121 @@
+-- Compatibility rules targeting categories and spec values
+-- Database: PostgreSQL
+
+BEGIN;
+
+CREATE TYPE compatibility_severity AS ENUM ('error', 'warning');
+CREATE TYPE compatibility_operator AS ENUM ('equals');
+CREATE TYPE compatibility_quantifier AS ENUM ('all', 'any');
+
+DROP FUNCTION IF EXISTS get_compatible_products(UUID, TEXT);
+DROP FUNCTION IF EXISTS validate_build(UUID);
+ALTER TABLE compatibility_rules RENAME TO compatibility_rules_legacy;
+ALTER INDEX compatibility_rules_pkey RENAME TO compatibility_rules_legacy_pkey;
+
+-- A rule applies to every build item matching its subject (a product or a category
+-- subtree, optionally narrowed to items with a spec value). The target spec of the other
+-- items (in the target category, or the whole build) is compared against a constant value
+-- or against one of the subject's own specs. With quantifier 'all' every target must pass
+-- and the rule does not apply when there are none; with 'any' one passing target is enough.
+CREATE TABLE compatibility_rules (
+    rule_id SERIAL PRIMARY KEY,
+    name VARCHAR(150) NOT NULL,
+    description TEXT,
+    severity compatibility_severity NOT NULL DEFAULT 'error',
+    subject_category_id INTEGER REFERENCES categories(category_id) ON DELETE CASCADE,
+    subject_product_id INTEGER REFERENCES products(product_id) ON DELETE CASCADE,
+    subject_filter_spec VARCHAR(100),
+    subject_filter_value VARCHAR(255),
+    target_category_id INTEGER REFERENCES categories(category_id) ON DELETE CASCADE,
+    target_spec VARCHAR(100) NOT NULL,
+    operator compatibility_operator NOT NULL DEFAULT 'equals',
+    quantifier compatibility_quantifier NOT NULL DEFAULT 'all',
+    subject_spec VARCHAR(100),
+    value VARCHAR(255),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT rule_has_subject CHECK (subject_category_id IS NOT NULL OR subject_product_id IS NOT NULL),
+    CONSTRAINT rule_filter_complete CHECK ((subject_filter_spec IS NULL) = (subject_filter_value IS NULL)),
+    CONSTRAINT rule_compares_one_thing CHECK ((subject_spec IS NULL) <> (value IS NULL))
+);
+
+-- Old rules meant "the build needs an item with spec_name = spec_value"; 'ANY' never applied
+INSERT INTO compatibility_rules (name, subject_product_id, target_spec, quantifier, value)
+SELECT format('Product %s requires %s=%s', product_id, spec_name, spec_value),
+       product_id, spec_name, 'any', spec_value
+FROM compatibility_rules_legacy
+WHERE spec_value <> 'ANY';
+
+DROP TABLE compatibility_rules_legacy;
+
+CREATE INDEX idx_compatibility_rules_subject_category ON compatibility_rules(subject_category_id);
+CREATE INDEX idx_compatibility_rules_subject_product ON compatibility_rules(subject_product_id);
+
+-- Lists every rule violated by a set of products, one row per rule and subject product,
+-- with the target products that failed the comparison and their values
+CREATE FUNCTION compatibility_violations(p_product_ids INTEGER[])
+RETURNS TABLE (
+    rule_id INTEGER,
+    severity compatibility_severity,
+    subject_product_id INTEGER,
+    expected TEXT,
+    target_product_ids INTEGER[],
+    actual_values TEXT[]
+) AS $$
+    WITH items AS (
+        SELECT DISTINCT p.product_id, p.category_id
+        FROM products p
+        WHERE p.product_id = ANY(p_product_ids)
+    ),
+    subjects AS (
+        SELECT r.rule_id, r.severity, r.target_category_id, r.target_spec, r.quantifier,
+               s.product_id, COALESCE(r.value, ss.spec_value) AS expected
+        FROM compatibility_rules r
+        JOIN items s
+          ON (r.subject_product_id IS NULL OR s.product_id = r.subject_product_id)
+         AND (r.subject_category_id IS NULL OR s.category_id IN (
+                SELECT category_id FROM category_ancestors WHERE ancestor_id = r.subject_category_id))
+        LEFT JOIN product_specifications ss
+          ON ss.product_id = s.product_id AND ss.spec_name = r.subject_spec
+        WHERE (r.subject_filter_spec IS NULL OR EXISTS (
+                SELECT 1 FROM product_specifications f
+                WHERE f.product_id = s.product_id AND f.spec_name = r.subject_filter_spec
+                  AND LOWER(f.spec_value) = LOWER(r.subject_filter_value)))
+          -- a subject without the spec it is compared by cannot be checked
+          AND (r.subject_spec IS NULL OR ss.spec_value IS NOT NULL)
+    ),
+    checks AS (
+        SELECT sj.rule_id, sj.severity, sj.product_id AS subject_product_id, sj.expected, sj.quantifier,
+               t.product_id AS target_product_id, ts.spec_value AS actual,
+               LOWER(TRIM(ts.spec_value)) = LOWER(TRIM(sj.expected)) AS passed
+        FROM subjects sj
+        LEFT JOIN items t
+          ON t.product_id <> sj.product_id
+         AND (sj.target_category_id IS NULL OR t.category_id IN (
+                SELECT category_id FROM category_ancestors WHERE ancestor_id = sj.target_category_id))
+        LEFT JOIN product_specifications ts
+          ON ts.product_id = t.product_id AND ts.spec_name = sj.target_spec
+    )
+    SELECT c.rule_id, c.severity, c.subject_product_id, c.expected,
+           COALESCE(array_agg(c.target_product_id ORDER BY c.target_product_id)
+                    FILTER (WHERE c.target_product_id IS NOT NULL AND (c.passed IS FALSE OR c.quantifier = 'any')), '{}'),
+           COALESCE(array_agg(c.actual ORDER BY c.target_product_id)
+                    FILTER (WHERE c.target_product_id IS NOT NULL AND (c.passed IS FALSE OR c.quantifier = 'any')), '{}')
+    FROM checks c
+    GROUP BY c.rule_id, c.severity, c.subject_product_id, c.expected, c.quantifier
+    -- targets missing the spec are not held against 'all' rules
+    HAVING (c.quantifier = 'all' AND bool_or(c.passed = FALSE))
+        OR (c.quantifier = 'any' AND NOT COALESCE(bool_or(c.passed), FALSE))
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION validate_build(p_build_id UUID)
+RETURNS TABLE (is_compatible BOOLEAN, message TEXT) AS $$
+    SELECT COUNT(*) = 0,
+           COALESCE(string_agg(
+               format('Incompatible: %s (product %s, expected %s); ', r.name, v.subject_product_id, v.expected),
+               '' ORDER BY v.rule_id, v.subject_product_id), '')
+    FROM compatibility_violations(ARRAY(SELECT product_id FROM build_items WHERE build_id = p_build_id)) v
+    JOIN compatibility_rules r ON r.rule_id = v.rule_id
+    WHERE v.severity = 'error'
+$$ LANGUAGE sql STABLE;
+
+COMMIT;
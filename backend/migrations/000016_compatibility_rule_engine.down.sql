@@ -0,0 +1,94 @@
+BEGIN;
+
+-- Rules using the new operators or aggregates cannot be expressed any more
+DELETE FROM compatibility_rules
+WHERE operator::text <> 'equals' OR target_aggregate IS NOT NULL OR target_filter_spec IS NOT NULL;
+
+ALTER TABLE compatibility_rules
+    DROP CONSTRAINT IF EXISTS rule_has_target_spec,
+    DROP CONSTRAINT IF EXISTS rule_target_filter_complete,
+    ALTER COLUMN target_spec SET NOT NULL,
+    DROP COLUMN IF EXISTS margin,
+    DROP COLUMN IF EXISTS target_filter_value,
+    DROP COLUMN IF EXISTS target_filter_spec,
+    DROP COLUMN IF EXISTS target_aggregate;
+DROP TYPE IF EXISTS compatibility_aggregate;
+
+-- Enum values cannot be dropped; swap the type for one without them
+ALTER TABLE compatibility_rules ALTER COLUMN operator DROP DEFAULT;
+ALTER TYPE compatibility_operator RENAME TO compatibility_operator_old;
+CREATE TYPE compatibility_operator AS ENUM ('equals');
+ALTER TABLE compatibility_rules
+    ALTER COLUMN operator TYPE compatibility_operator USING operator::text::compatibility_operator,
+    ALTER COLUMN operator SET DEFAULT 'equals';
+DROP TYPE compatibility_operator_old;
+
+-- Lists every rule violated by a set of products, one row per rule and subject product,
+-- with the target products that failed the comparison and their values
+CREATE FUNCTION compatibility_violations(p_product_ids INTEGER[])
+RETURNS TABLE (
+    rule_id INTEGER,
+    severity compatibility_severity,
+    subject_product_id INTEGER,
+    expected TEXT,
+    target_product_ids INTEGER[],
+    actual_values TEXT[]
+) AS $$
+    WITH items AS (
+        SELECT DISTINCT p.product_id, p.category_id
+        FROM products p
+        WHERE p.product_id = ANY(p_product_ids)
+    ),
+    subjects AS (
+        SELECT r.rule_id, r.severity, r.target_category_id, r.target_spec, r.quantifier,
+               s.product_id, COALESCE(r.value, ss.spec_value) AS expected
+        FROM compatibility_rules r
+        JOIN items s
+          ON (r.subject_product_id IS NULL OR s.product_id = r.subject_product_id)
+         AND (r.subject_category_id IS NULL OR s.category_id IN (
+                SELECT category_id FROM category_ancestors WHERE ancestor_id = r.subject_category_id))
+        LEFT JOIN product_specifications ss
+          ON ss.product_id = s.product_id AND ss.spec_name = r.subject_spec
+        WHERE (r.subject_filter_spec IS NULL OR EXISTS (
+                SELECT 1 FROM product_specifications f
+                WHERE f.product_id = s.product_id AND f.spec_name = r.subject_filter_spec
+                  AND LOWER(f.spec_value) = LOWER(r.subject_filter_value)))
+          -- a subject without the spec it is compared by cannot be checked
+          AND (r.subject_spec IS NULL OR ss.spec_value IS NOT NULL)
+    ),
+    checks AS (
+        SELECT sj.rule_id, sj.severity, sj.product_id AS subject_product_id, sj.expected, sj.quantifier,
+               t.product_id AS target_product_id, ts.spec_value AS actual,
+               LOWER(TRIM(ts.spec_value)) = LOWER(TRIM(sj.expected)) AS passed
+        FROM subjects sj
+        LEFT JOIN items t
+          ON t.product_id <> sj.product_id
+         AND (sj.target_category_id IS NULL OR t.category_id IN (
+                SELECT category_id FROM category_ancestors WHERE ancestor_id = sj.target_category_id))
+        LEFT JOIN product_specifications ts
+          ON ts.product_id = t.product_id AND ts.spec_name = sj.target_spec
+    )
+    SELECT c.rule_id, c.severity, c.subject_product_id, c.expected,
+           COALESCE(array_agg(c.target_product_id ORDER BY c.target_product_id)
+                    FILTER (WHERE c.target_product_id IS NOT NULL AND (c.passed IS FALSE OR c.quantifier = 'any')), '{}'),
+           COALESCE(array_agg(c.actual ORDER BY c.target_product_id)
+                    FILTER (WHERE c.target_product_id IS NOT NULL AND (c.passed IS FALSE OR c.quantifier = 'any')), '{}')
+    FROM checks c
+    GROUP BY c.rule_id, c.severity, c.subject_product_id, c.expected, c.quantifier
+    -- targets missing the spec are not held against 'all' rules
+    HAVING (c.quantifier = 'all' AND bool_or(c.passed = FALSE))
+        OR (c.quantifier = 'any' AND NOT COALESCE(bool_or(c.passed), FALSE))
+$$ LANGUAGE sql STABLE;
+
+CREATE FUNCTION validate_build(p_build_id UUID)
+RETURNS TABLE (is_compatible BOOLEAN, message TEXT) AS $$
+    SELECT COUNT(*) = 0,
+           COALESCE(string_agg(
+               format('Incompatible: %s (product %s, expected %s); ', r.name, v.subject_product_id, v.expected),
+               '' ORDER BY v.rule_id, v.subject_product_id), '')
+    FROM compatibility_violations(ARRAY(SELECT product_id FROM build_items WHERE build_id = p_build_id)) v
+    JOIN compatibility_rules r ON r.rule_id = v.rule_id
+    WHERE v.severity = 'error'
+$$ LANGUAGE sql STABLE;
+
+COMMIT;
@@ -0,0 +1,40 @@
+-- Numeric, set and aggregate compatibility rules evaluated by the build service
+-- Database: PostgreSQL
+
+BEGIN;
+
+-- A rule applies to the build items matching its subject: a product or every product in a
+-- category subtree, optionally only those whose subject_filter_spec has subject_filter_value.
+-- The other items in target_category_id (or the whole build), narrowed the same way by the
+-- target filter, are the targets. Their target_spec plus margin is compared with the
+-- operator against value or against the subject's own subject_spec. With quantifier 'all'
+-- each failing target is a violation; with 'any' one passing target is enough, and the rule
+-- fails when none passes, including when there is no target. A target_aggregate compares
+-- the sum or count of the targets instead; targets without a number add nothing to a sum.
+-- Subjects lacking the spec they are compared by, and targets whose values cannot be
+-- compared, are skipped. A rule with no subject or nothing to check is not applicable;
+-- the build is compatible when no error-severity rule fails.
+
+-- in_set: the target value is one of the comma-separated values it is compared against
+-- gte/lte: numeric comparisons of the target value (plus the margin) against the other side
+ALTER TYPE compatibility_operator ADD VALUE IF NOT EXISTS 'in_set';
+ALTER TYPE compatibility_operator ADD VALUE IF NOT EXISTS 'gte';
+ALTER TYPE compatibility_operator ADD VALUE IF NOT EXISTS 'lte';
+
+-- sum adds up target_spec times quantity over the targets, count adds up their quantities
+CREATE TYPE compatibility_aggregate AS ENUM ('sum', 'count');
+
+ALTER TABLE compatibility_rules
+    ADD COLUMN target_aggregate compatibility_aggregate,
+    ADD COLUMN target_filter_spec VARCHAR(100),
+    ADD COLUMN target_filter_value VARCHAR(255),
+    ADD COLUMN margin NUMERIC(10,2) NOT NULL DEFAULT 0,
+    ALTER COLUMN target_spec DROP NOT NULL,
+    ADD CONSTRAINT rule_target_filter_complete CHECK ((target_filter_spec IS NULL) = (target_filter_value IS NULL)),
+    ADD CONSTRAINT rule_has_target_spec CHECK (target_spec IS NOT NULL OR target_aggregate = 'count');
+
+-- Rules are evaluated in Go now
+DROP FUNCTION IF EXISTS validate_build(UUID);
+DROP FUNCTION IF EXISTS compatibility_violations(INTEGER[]);
+
+COMMIT;
@@ -0,0 +1,18 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_custom_builds_public_popular;
+DROP INDEX IF EXISTS idx_custom_builds_public_recent;
+
+ALTER TABLE custom_builds
+    DROP CONSTRAINT IF EXISTS non_negative_clone_count,
+    DROP CONSTRAINT IF EXISTS public_builds_have_shared_at,
+    DROP CONSTRAINT IF EXISTS shared_builds_have_slug,
+    DROP COLUMN IF EXISTS cloned_from,
+    DROP COLUMN IF EXISTS clone_count,
+    DROP COLUMN IF EXISTS shared_at,
+    DROP COLUMN IF EXISTS slug,
+    DROP COLUMN IF EXISTS visibility;
+
+DROP TYPE IF EXISTS build_visibility;
+
+COMMIT;
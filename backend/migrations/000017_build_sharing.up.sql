@@ -0,0 +1,25 @@
+-- Build visibility, share links and clones
+-- Database: PostgreSQL
+
+BEGIN;
+
+-- Private builds are seen by their owner only, unlisted ones by anyone with the link and
+-- public ones are listed in the gallery too. A build keeps its slug once shared, so the
+-- link works again if it is reshared. shared_at orders the gallery by when a build was made
+-- public and clone_count ranks it by popularity.
+CREATE TYPE build_visibility AS ENUM ('private', 'unlisted', 'public');
+
+ALTER TABLE custom_builds
+    ADD COLUMN visibility build_visibility NOT NULL DEFAULT 'private',
+    ADD COLUMN slug VARCHAR(80) UNIQUE,
+    ADD COLUMN shared_at TIMESTAMP,
+    ADD COLUMN clone_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN cloned_from UUID REFERENCES custom_builds(build_id) ON DELETE SET NULL,
+    ADD CONSTRAINT shared_builds_have_slug CHECK (visibility = 'private' OR slug IS NOT NULL),
+    ADD CONSTRAINT public_builds_have_shared_at CHECK ((visibility = 'public') = (shared_at IS NOT NULL)),
+    ADD CONSTRAINT non_negative_clone_count CHECK (clone_count >= 0);
+
+CREATE INDEX idx_custom_builds_public_recent ON custom_builds(shared_at DESC) WHERE visibility = 'public';
+CREATE INDEX idx_custom_builds_public_popular ON custom_builds(clone_count DESC, shared_at DESC) WHERE visibility = 'public';
+
+COMMIT;
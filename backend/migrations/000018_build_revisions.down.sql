@@ -0,0 +1,20 @@
+BEGIN;
+
+CREATE OR REPLACE FUNCTION update_build_total_price()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE custom_builds
+    SET total_price = (
+        SELECT COALESCE(SUM(ep.final_price * bi.quantity), 0)
+        FROM build_items bi
+        JOIN product_effective_prices ep ON bi.product_id = ep.product_id
+        WHERE bi.build_id = NEW.build_id
+    )
+    WHERE build_id = NEW.build_id;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS build_revisions;
+
+COMMIT;
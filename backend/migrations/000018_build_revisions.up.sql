@@ -0,0 +1,52 @@
+-- Build revision history
+-- Database: PostgreSQL
+
+BEGIN;
+
+-- Every change to the name or items of a build stores the state it leads to, so earlier
+-- versions can be listed and restored. Items are kept as [{product_id, quantity}].
+CREATE TABLE build_revisions (
+    build_id UUID NOT NULL REFERENCES custom_builds(build_id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    items JSONB NOT NULL,
+    total_price DECIMAL(10,2) NOT NULL,
+    change VARCHAR(30) NOT NULL,
+    restored_from INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (build_id, revision),
+    CONSTRAINT positive_revision CHECK (revision > 0),
+    CONSTRAINT items_is_array CHECK (jsonb_typeof(items) = 'array')
+);
+
+-- Existing builds start their history at their current state
+INSERT INTO build_revisions (build_id, revision, name, items, total_price, change, created_at)
+SELECT b.build_id, 1, b.name,
+       COALESCE((
+           SELECT jsonb_agg(jsonb_build_object('product_id', bi.product_id, 'quantity', bi.quantity) ORDER BY bi.product_id)
+           FROM build_items bi
+           WHERE bi.build_id = b.build_id
+       ), '[]'::jsonb),
+       b.total_price, 'created', b.created_at
+FROM custom_builds b;
+
+-- The total was only refreshed for inserted and updated items, since NEW is null when an
+-- item is deleted; removing a single item has to lower it too
+CREATE OR REPLACE FUNCTION update_build_total_price()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_build_id UUID := CASE WHEN TG_OP = 'DELETE' THEN OLD.build_id ELSE NEW.build_id END;
+BEGIN
+    UPDATE custom_builds
+    SET total_price = (
+        SELECT COALESCE(SUM(ep.final_price * bi.quantity), 0)
+        FROM build_items bi
+        JOIN product_effective_prices ep ON bi.product_id = ep.product_id
+        WHERE bi.build_id = v_build_id
+    )
+    WHERE build_id = v_build_id;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
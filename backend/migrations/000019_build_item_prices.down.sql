@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE build_items DROP COLUMN IF EXISTS saved_price;
+
+COMMIT;
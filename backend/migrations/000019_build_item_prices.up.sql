@@ -0,0 +1,19 @@
+-- Prices of build items when the build was saved
+-- Database: PostgreSQL
+
+BEGIN;
+
+-- Buying a build reports the items whose price changed since it was saved. Items saved
+-- before this migration take the current price as their saved price.
+ALTER TABLE build_items ADD COLUMN saved_price DECIMAL(10,2);
+
+UPDATE build_items bi
+SET saved_price = ep.final_price
+FROM product_effective_prices ep
+WHERE ep.product_id = bi.product_id;
+
+ALTER TABLE build_items
+    ALTER COLUMN saved_price SET NOT NULL,
+    ADD CONSTRAINT non_negative_saved_price CHECK (saved_price >= 0);
+
+COMMIT;
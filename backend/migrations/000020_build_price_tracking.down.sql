@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS discounts_refresh_builds ON discounts;
+DROP TRIGGER IF EXISTS products_price_refresh_builds ON products;
+DROP FUNCTION IF EXISTS refresh_builds_on_discount_change();
+DROP FUNCTION IF EXISTS refresh_builds_on_price_change();
+DROP FUNCTION IF EXISTS refresh_build_totals(UUID[]);
+
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS build_price_alerts;
+DROP TABLE IF EXISTS build_price_history;
+
+COMMIT;
@@ -0,0 +1,146 @@
+-- Build price history, total refresh on price changes and price-drop alerts
+-- Database: PostgreSQL
+
+BEGIN;
+
+-- Totals of a build over time. 'edited' points are recorded by the application when the
+-- owner changes the items, 'repriced' ones when product prices or discounts change.
+CREATE TABLE build_price_history (
+    history_id BIGSERIAL PRIMARY KEY,
+    build_id UUID NOT NULL REFERENCES custom_builds(build_id) ON DELETE CASCADE,
+    total_price DECIMAL(10,2) NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT valid_reason CHECK (reason IN ('edited', 'repriced'))
+);
+CREATE INDEX idx_build_price_history_build ON build_price_history(build_id, recorded_at);
+
+INSERT INTO build_price_history (build_id, total_price, reason)
+SELECT build_id, total_price, 'edited' FROM custom_builds;
+
+-- Opt-in alert for the owner of a build. is_below remembers whether the total was already
+-- under the threshold, so only a drop across it is notified.
+CREATE TABLE build_price_alerts (
+    build_id UUID PRIMARY KEY REFERENCES custom_builds(build_id) ON DELETE CASCADE,
+    threshold DECIMAL(10,2) NOT NULL,
+    is_below BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    notified_at TIMESTAMP,
+    CONSTRAINT positive_threshold CHECK (threshold > 0)
+);
+
+CREATE TABLE notifications (
+    notification_id SERIAL PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    type VARCHAR(50) NOT NULL,
+    message TEXT NOT NULL,
+    data JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    read_at TIMESTAMP
+);
+CREATE INDEX idx_notifications_user ON notifications(user_id, created_at DESC);
+
+-- Recomputes the totals of the given builds at the current effective prices. Changed
+-- totals are added to the price history, and owners whose alert threshold the new total
+-- drops below are notified. Returns the number of builds whose total changed.
+CREATE OR REPLACE FUNCTION refresh_build_totals(p_build_ids UUID[])
+RETURNS INTEGER AS $$
+DECLARE
+    v_build RECORD;
+    v_alert RECORD;
+    v_count INTEGER := 0;
+BEGIN
+    FOR v_build IN
+        SELECT b.build_id, b.user_id, b.name, b.total_price AS old_total, t.total AS new_total
+        FROM custom_builds b
+        CROSS JOIN LATERAL (
+            SELECT COALESCE(SUM(ep.final_price * bi.quantity), 0)::DECIMAL(10,2) AS total
+            FROM build_items bi
+            JOIN product_effective_prices ep ON ep.product_id = bi.product_id
+            WHERE bi.build_id = b.build_id
+        ) t
+        WHERE b.build_id = ANY(p_build_ids) AND b.total_price IS DISTINCT FROM t.total
+        FOR UPDATE OF b
+    LOOP
+        UPDATE custom_builds SET total_price = v_build.new_total WHERE build_id = v_build.build_id;
+        INSERT INTO build_price_history (build_id, total_price, reason)
+        VALUES (v_build.build_id, v_build.new_total, 'repriced');
+        v_count := v_count + 1;
+
+        SELECT * INTO v_alert FROM build_price_alerts WHERE build_id = v_build.build_id FOR UPDATE;
+        IF NOT FOUND THEN
+            CONTINUE;
+        END IF;
+        IF v_build.new_total < v_alert.threshold AND NOT v_alert.is_below THEN
+            INSERT INTO notifications (user_id, type, message, data)
+            VALUES (
+                v_build.user_id,
+                'build_price_drop',
+                format('Your build "%s" now costs %s, below your alert at %s', v_build.name, v_build.new_total, v_alert.threshold),
+                jsonb_build_object(
+                    'build_id', v_build.build_id,
+                    'old_total', v_build.old_total,
+                    'new_total', v_build.new_total,
+                    'threshold', v_alert.threshold
+                )
+            );
+            UPDATE build_price_alerts SET is_below = TRUE, notified_at = CURRENT_TIMESTAMP
+            WHERE build_id = v_build.build_id;
+        ELSIF v_build.new_total >= v_alert.threshold AND v_alert.is_below THEN
+            UPDATE build_price_alerts SET is_below = FALSE WHERE build_id = v_build.build_id;
+        END IF;
+    END LOOP;
+    RETURN v_count;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION refresh_builds_on_price_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM refresh_build_totals(ARRAY(
+        SELECT DISTINCT build_id FROM build_items WHERE product_id = NEW.product_id
+    ));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_price_refresh_builds
+AFTER UPDATE OF price ON products
+FOR EACH ROW
+WHEN (OLD.price IS DISTINCT FROM NEW.price)
+EXECUTE FUNCTION refresh_builds_on_price_change();
+
+-- A discount on a product or on a category subtree changes the effective price of the
+-- products it covers, before and after the change
+CREATE OR REPLACE FUNCTION refresh_builds_on_discount_change()
+RETURNS TRIGGER AS $$
+DECLARE
+    v_product_ids INTEGER[] := ARRAY[]::INTEGER[];
+    v_category_ids INTEGER[] := ARRAY[]::INTEGER[];
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        v_product_ids := v_product_ids || OLD.product_id;
+        v_category_ids := v_category_ids || OLD.category_id;
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        v_product_ids := v_product_ids || NEW.product_id;
+        v_category_ids := v_category_ids || NEW.category_id;
+    END IF;
+
+    PERFORM refresh_build_totals(ARRAY(
+        SELECT DISTINCT bi.build_id
+        FROM build_items bi
+        JOIN products p ON p.product_id = bi.product_id
+        WHERE p.product_id = ANY(v_product_ids)
+           OR p.category_id IN (SELECT category_id FROM category_ancestors WHERE ancestor_id = ANY(v_category_ids))
+    ));
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER discounts_refresh_builds
+AFTER INSERT OR UPDATE OR DELETE ON discounts
+FOR EACH ROW
+EXECUTE FUNCTION refresh_builds_on_discount_change();
+
+COMMIT;